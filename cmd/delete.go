@@ -14,7 +14,7 @@ func createDeleteCommand(rootCmd *cobra.Command, cfg *config.Config) {
 	cmd := &cobra.Command{
 		Use:     "delete-http",
 		Aliases: []string{"d"},
-		Short:   "Deletes all old HTTP requests to preserve DB space",
+		Short:   "Deletes old HTTP requests that were not starred or annotated to preserve DB space",
 		RunE: func(_ *cobra.Command, _ []string) error {
 			db, err := sdumpSql.New(cfg.HTTP.Database)
 			if err != nil {
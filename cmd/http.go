@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"os"
 	"os/signal"
 	"syscall"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/ayinke-llc/sdump/config"
 	sdumpSql "github.com/ayinke-llc/sdump/datastore/sql"
+	"github.com/ayinke-llc/sdump/internal/util"
 	"github.com/ayinke-llc/sdump/server/httpd"
 	"github.com/r3labs/sse/v2"
 	"github.com/sethvargo/go-limiter/memorystore"
@@ -28,6 +30,12 @@ func createHTTPCommand(cmd *cobra.Command, cfg *config.Config) {
 		Use:   "http",
 		Short: "Start/run the HTTP server",
 		RunE: func(_ *cobra.Command, _ []string) error {
+			// the admin secret is what lets the ssh server act on behalf of
+			// users. There is no safe default for it
+			if util.IsStringEmpty(cfg.HTTP.AdminSecret) {
+				return errors.New("http.admin_secret must be set. Generate one with openssl rand -hex 32")
+			}
+
 			sig := make(chan os.Signal, 1)
 
 			signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
@@ -73,6 +81,7 @@ func createHTTPCommand(cmd *cobra.Command, cfg *config.Config) {
 			urlStore := sdumpSql.NewURLRepositoryTable(db)
 			ingestStore := sdumpSql.NewIngestRepository(db)
 			userStore := sdumpSql.NewUserRepositoryTable(db)
+			replayStore := sdumpSql.NewReplayRepositoryTable(db)
 
 			hostName, err := os.Hostname()
 			if err != nil {
@@ -85,7 +94,7 @@ func createHTTPCommand(cmd *cobra.Command, cfg *config.Config) {
 			sseServer := sse.New()
 
 			httpServer := httpd.New(*cfg, urlStore, ingestStore,
-				userStore, logger, sseServer, ratelimitStore)
+				userStore, replayStore, logger, sseServer, ratelimitStore)
 
 			go func() {
 				logger.Debug("starting HTTP server")
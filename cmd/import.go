@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ayinke-llc/sdump"
+	"github.com/ayinke-llc/sdump/config"
+	sdumpSql "github.com/ayinke-llc/sdump/datastore/sql"
+	"github.com/ayinke-llc/sdump/internal/importer"
+	"github.com/ayinke-llc/sdump/internal/payload"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func createImportCommand(rootCmd *cobra.Command, cfg *config.Config) {
+	var reference string
+	var keepAnnotations bool
+
+	cmd := &cobra.Command{
+		Use:   "import [file]",
+		Short: "Imports a HAR file or an NDJSON dump of captured requests into an endpoint",
+		Long: `Imports a HAR file or an NDJSON dump of captured requests into an endpoint.
+The archive is read from stdin if no file or - is provided. Timestamps and ip
+addresses are kept if the archive has them. Stars, notes and tags are dropped
+unless --keep-annotations is set as annotated requests are never pruned`,
+		Example: `  sdump import --endpoint cmltfm6g330l5l1vq110 requests.har
+  cat requests.ndjson | sdump import --endpoint cmltfm6g330l5l1vq110`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			var r io.Reader = os.Stdin
+
+			if len(args) == 1 && args[0] != "-" {
+				f, err := os.Open(args[0])
+				if err != nil {
+					return err
+				}
+
+				defer f.Close()
+
+				r = f
+			}
+
+			ingests, err := importer.Decode(r)
+			if err != nil {
+				return err
+			}
+
+			db, err := sdumpSql.New(cfg.HTTP.Database)
+			if err != nil {
+				return err
+			}
+
+			defer db.Close()
+
+			ctx := context.Background()
+
+			endpoint, err := sdumpSql.NewURLRepositoryTable(db).Get(ctx, &sdump.FindURLOptions{
+				Reference: reference,
+			})
+			if err != nil {
+				return err
+			}
+
+			decoder := payload.NewDecoder(logrus.WithField("module", "import"), cfg.HTTP.MaxDecodedBodySize)
+
+			imported, err := importer.Import(ctx, sdumpSql.NewIngestRepository(db), decoder,
+				endpoint, ingests, &importer.Options{
+					KeepAnnotations: keepAnnotations,
+				})
+			if err != nil {
+				return fmt.Errorf("none of the %d requests were imported: %v", len(ingests), err)
+			}
+
+			fmt.Printf("Imported %d requests into %s\n", imported, reference)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&reference, "endpoint", "e", "", "Reference of the endpoint to import the requests into")
+
+	cmd.Flags().BoolVar(&keepAnnotations, "keep-annotations", false, "Keep the stars, notes and tags found in the archive")
+
+	_ = cmd.MarkFlagRequired("endpoint")
+
+	rootCmd.AddCommand(cmd)
+}
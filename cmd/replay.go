@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ayinke-llc/sdump"
+	"github.com/ayinke-llc/sdump/config"
+	sdumpSql "github.com/ayinke-llc/sdump/datastore/sql"
+	"github.com/ayinke-llc/sdump/internal/filter"
+	"github.com/ayinke-llc/sdump/internal/replay"
+	"github.com/spf13/cobra"
+)
+
+func createReplayCommand(rootCmd *cobra.Command, cfg *config.Config) {
+	var (
+		reference      string
+		target         string
+		from, to       string
+		method         string
+		query          string
+		headers        []string
+		limit          int
+		concurrency    int
+		rate           float64
+		preserveTiming bool
+		speed          float64
+		record         bool
+		verbose        bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "replay",
+		Short: "Re-sends captured requests of an endpoint to a target url and reports the results",
+		Example: `  sdump replay --endpoint cmltfm6g330l5l1vq110 --target http://localhost:3000/webhooks --from 24h
+  sdump replay --endpoint cmltfm6g330l5l1vq110 --target http://localhost:3000/webhooks --preserve-timing --speed 10
+  sdump replay --endpoint cmltfm6g330l5l1vq110 --target http://localhost:3000/webhooks --query 'method:post header:x-event=order.created'`,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if err := replay.ValidateTarget(target); err != nil {
+				return fmt.Errorf("invalid target url: %v", err)
+			}
+
+			overrides, err := parseHeaderFlags(headers)
+			if err != nil {
+				return err
+			}
+
+			fromTime, err := parseTimeFlag(from)
+			if err != nil {
+				return fmt.Errorf("invalid --from value: %v", err)
+			}
+
+			toTime, err := parseTimeFlag(to)
+			if err != nil {
+				return fmt.Errorf("invalid --to value: %v", err)
+			}
+
+			if method != "" {
+				query = "method:" + method + " " + query
+			}
+
+			filterQuery, err := filter.Parse(query)
+			if err != nil {
+				return fmt.Errorf("invalid --query value: %v", err)
+			}
+
+			db, err := sdumpSql.New(cfg.HTTP.Database)
+			if err != nil {
+				return err
+			}
+
+			defer db.Close()
+
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer cancel()
+
+			endpoint, err := sdumpSql.NewURLRepositoryTable(db).Get(ctx, &sdump.FindURLOptions{
+				Reference: reference,
+			})
+			if err != nil {
+				return err
+			}
+
+			ingests, err := replay.Select(ctx, sdumpSql.NewIngestRepository(db), sdump.ListIngestOptions{
+				UrlID: endpoint.ID,
+				From:  fromTime,
+				To:    toTime,
+				Limit: limit,
+			}, filterQuery)
+			if err != nil {
+				return err
+			}
+
+			if len(ingests) == 0 {
+				fmt.Println("No captured requests matched the provided filters")
+				return nil
+			}
+
+			fmt.Printf("Replaying %d requests to %s\n\n", len(ingests), target)
+
+			replayStore := sdumpSql.NewReplayRepositoryTable(db)
+
+			// the operator running this command has access to the database
+			// already so there is no need to guard against private networks
+			client := replay.New(cfg.HTTP.Replay.Timeout, true)
+
+			report := client.DoAll(ctx, ingests, &replay.BulkOptions{
+				Options: replay.Options{
+					Target:  target,
+					Headers: overrides,
+				},
+				Concurrency:    concurrency,
+				Rate:           rate,
+				PreserveTiming: preserveTiming,
+				Speed:          speed,
+			}, func(result replay.Result) {
+				if verbose {
+					printResult(result)
+				}
+
+				if !record || result.Replay == nil {
+					return
+				}
+
+				if err := replayStore.Create(ctx, result.Replay); err != nil {
+					fmt.Fprintf(os.Stderr, "could not record replay of %s: %v\n", result.Ingest.ID, err)
+				}
+			})
+
+			return report.Write(os.Stdout)
+		},
+	}
+
+	cmd.Flags().StringVarP(&reference, "endpoint", "e", "", "Reference of the endpoint to read captured requests from")
+	cmd.Flags().StringVarP(&target, "target", "t", "", "URL to send the captured requests to")
+	cmd.Flags().StringVar(&from, "from", "", "Only replay requests captured after this time. RFC3339 or a duration like 24h")
+	cmd.Flags().StringVar(&to, "to", "", "Only replay requests captured before this time. RFC3339 or a duration like 1h")
+	cmd.Flags().StringVarP(&method, "method", "X", "", "Only replay requests with this HTTP method")
+	cmd.Flags().StringVarP(&query, "query", "q", "", "Only replay requests matching this filter. Same syntax as the TUI search, e.g. 'method:post body.type=charge'")
+	cmd.Flags().StringArrayVarP(&headers, "header", "H", nil, "Override a header. Can be repeated. e.g -H 'Authorization: Bearer token'")
+	cmd.Flags().IntVar(&limit, "limit", 0, "Maximum number of requests to replay")
+	cmd.Flags().IntVar(&concurrency, "concurrency", 1, "Number of requests in flight at once")
+	cmd.Flags().Float64VarP(&rate, "rate", "r", 0, "Maximum number of requests started per second. 0 means no limit")
+	cmd.Flags().BoolVar(&preserveTiming, "preserve-timing", false, "Wait between requests as long as the gap between their original arrival")
+	cmd.Flags().Float64Var(&speed, "speed", 1, "Speed up preserved timing by this factor")
+	cmd.Flags().BoolVar(&record, "record", false, "Store every replay against the original capture")
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Print the result of every request")
+
+	_ = cmd.MarkFlagRequired("endpoint")
+	_ = cmd.MarkFlagRequired("target")
+
+	rootCmd.AddCommand(cmd)
+}
+
+// parseTimeFlag accepts either an RFC3339 timestamp or a duration which is
+// treated as relative to now
+func parseTimeFlag(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+
+	return time.Parse(time.RFC3339, s)
+}
+
+func parseHeaderFlags(headers []string) (map[string]string, error) {
+	overrides := make(map[string]string, len(headers))
+
+	for _, header := range headers {
+		key, value, found := strings.Cut(header, ":")
+		if !found || strings.TrimSpace(key) == "" {
+			return nil, errors.New("headers must be in the Key: Value format")
+		}
+
+		overrides[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+
+	return overrides, nil
+}
+
+func printResult(result replay.Result) {
+	switch {
+	case result.Err != nil:
+		fmt.Printf("%s  %-7s error: %v\n", result.Ingest.ID, result.Ingest.Request.Method, result.Err)
+	case result.Replay.Error != "":
+		fmt.Printf("%s  %-7s error: %s\n", result.Ingest.ID, result.Ingest.Request.Method, result.Replay.Error)
+	default:
+		var truncated string
+		if result.Replay.Response.Truncated {
+			truncated = "  response truncated"
+		}
+
+		fmt.Printf("%s  %-7s %d  %s%s\n", result.Ingest.ID, result.Ingest.Request.Method,
+			result.Replay.Response.StatusCode, result.Replay.Response.Duration.Round(time.Millisecond), truncated)
+	}
+}
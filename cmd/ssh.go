@@ -9,7 +9,9 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/ayinke-llc/sdump"
 	"github.com/ayinke-llc/sdump/config"
+	"github.com/ayinke-llc/sdump/internal/client"
 	"github.com/ayinke-llc/sdump/internal/tui"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/log"
@@ -17,6 +19,7 @@ import (
 	"github.com/charmbracelet/wish"
 	bm "github.com/charmbracelet/wish/bubbletea"
 	lm "github.com/charmbracelet/wish/logging"
+	"github.com/muesli/termenv"
 	"github.com/spf13/cobra"
 	gossh "golang.org/x/crypto/ssh"
 )
@@ -26,11 +29,24 @@ func createSSHCommand(rootCmd *cobra.Command, cfg *config.Config) {
 		Use:   "ssh",
 		Short: "Start/run the TUI app",
 		RunE: func(_ *cobra.Command, _ []string) error {
+			if err := tui.ValidateKeys(cfg.TUI.Keys); err != nil {
+				return err
+			}
+
+			if err := tui.ValidateTheme(cfg.TUI); err != nil {
+				return err
+			}
+
+			if err := tui.ValidateNotify(cfg.TUI.Notify); err != nil {
+				return err
+			}
+
 			s, err := wish.NewServer(
 				wish.WithAddress(fmt.Sprintf("%s:%d", cfg.SSH.Host, cfg.SSH.Port)),
 				validateSSHPublicKey(cfg),
 				wish.WithMiddleware(
-					bm.Middleware(teaHandler(cfg)),
+					bm.MiddlewareWithProgramHandler(teaHandler(cfg), termenv.ANSI256),
+					commandMiddleware(cfg),
 					lm.Middleware(),
 				),
 			)
@@ -112,28 +128,56 @@ func validateSSHPublicKey(cfg *config.Config) ssh.Option {
 	})
 }
 
-func teaHandler(cfg *config.Config) func(s ssh.Session) (tea.Model, []tea.ProgramOption) {
-	return func(s ssh.Session) (tea.Model, []tea.ProgramOption) {
+func teaHandler(cfg *config.Config) bm.ProgramHandler {
+	return func(s ssh.Session) *tea.Program {
 		pty, _, active := s.Pty()
 		if !active {
 			wish.Fatalln(s, "no active terminal, skipping")
-			return nil, nil
+			return nil
 		}
 
+		// the clipboard writes OSC 52 sequences to the session. Both it and
+		// the renderer share the same output so writes never interleave
+		output := tui.NewOutput(s)
+
 		sshFingerPrint := gossh.FingerprintSHA256(s.PublicKey())
 
+		environ := append(s.Environ(), "TERM="+pty.Term)
+
 		tuiModel, err := tui.New(cfg,
+			tui.WithPreferences(fetchPreferences(cfg, sshFingerPrint)),
 			tui.WithWidth(pty.Window.Width),
 			tui.WithHeight(pty.Window.Height),
 			tui.WithSSHFingerPrint(sshFingerPrint),
 			tui.WithColorscheme(cfg.TUI.ColorScheme),
+			tui.WithClipboard(tui.NewClipboard(output, environ)),
+			tui.WithNotifier(tui.NewNotifier(output, pty.Term)),
+			tui.WithEnvironment(environ),
 		)
 		if err != nil {
 			wish.Fatalln(s, fmt.Errorf("%v...Could not set up TUI session", err))
-			return nil, nil
+			return nil
 		}
 
-		return tuiModel,
-			[]tea.ProgramOption{tea.WithAltScreen()}
+		return tea.NewProgram(tuiModel,
+			tea.WithAltScreen(),
+			tea.WithInput(s),
+			tea.WithOutput(output))
 	}
 }
+
+// fetchPreferences returns the saved TUI preferences of the user. Users
+// connecting for the first time do not exist yet so errors fall back to the
+// config of the server
+func fetchPreferences(cfg *config.Config, sshFingerPrint string) *sdump.Preferences {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	preferences, err := client.New(cfg.HTTP.Domain, cfg.HTTP.AdminSecret, sshFingerPrint).
+		Preferences(ctx)
+	if err != nil || preferences == nil {
+		return &sdump.Preferences{}
+	}
+
+	return preferences
+}
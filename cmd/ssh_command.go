@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/ayinke-llc/sdump/config"
+	"github.com/ayinke-llc/sdump/internal/client"
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// sshCommand is a non interactive command that can be run over ssh instead
+// of starting the TUI. e.g ssh -p 2222 ssh.sdump.app har > requests.har
+type sshCommand struct {
+	usage       string
+	description string
+	run         func(ctx context.Context, s ssh.Session, apiClient *client.Client, args []string) error
+}
+
+var sshCommands = map[string]sshCommand{
+	"har": {
+		usage:       "har [reference] [ingest id...]",
+		description: "Export captured requests as a HAR file. Defaults to every request of your latest endpoint",
+		run:         runHARCommand,
+	},
+	"import": {
+		usage:       "import [--keep-annotations] [reference] < archive",
+		description: "Import a HAR file or an NDJSON dump read from stdin. Defaults to your latest endpoint",
+		run:         runImportCommand,
+	},
+	"protobuf": {
+		usage:       "protobuf [reference] [message] < descriptors.pb",
+		description: "Upload a protobuf FileDescriptorSet read from stdin to decode protobuf payloads. Defaults to your latest endpoint",
+		run:         runProtobufCommand,
+	},
+}
+
+// commandMiddleware runs ssh commands when one is provided. The TUI is only
+// started for sessions without a command
+func commandMiddleware(cfg *config.Config) wish.Middleware {
+	return func(next ssh.Handler) ssh.Handler {
+		return func(s ssh.Session) {
+			args := s.Command()
+			if len(args) == 0 {
+				next(s)
+				return
+			}
+
+			command, ok := sshCommands[args[0]]
+			if !ok {
+				writeSSHUsage(s.Stderr())
+				_ = s.Exit(1)
+				return
+			}
+
+			apiClient := client.New(cfg.HTTP.Domain, cfg.HTTP.AdminSecret,
+				gossh.FingerprintSHA256(s.PublicKey()))
+
+			if err := command.run(s.Context(), s, apiClient, args[1:]); err != nil {
+				wish.Fatalln(s, err)
+				return
+			}
+
+			_ = s.Exit(0)
+		}
+	}
+}
+
+func writeSSHUsage(w io.Writer) {
+	names := make([]string, 0, len(sshCommands))
+	for name := range sshCommands {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "Available commands:")
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-40s %s\n", sshCommands[name].usage, sshCommands[name].description)
+	}
+}
+
+// referenceFromArgs returns the endpoint reference provided as the first
+// argument or the latest endpoint of the user if none was provided
+func referenceFromArgs(ctx context.Context, apiClient *client.Client,
+	args []string,
+) (string, []string, error) {
+	if len(args) > 0 && strings.TrimSpace(args[0]) != "" {
+		return args[0], args[1:], nil
+	}
+
+	endpoint, err := apiClient.LatestEndpoint(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return endpoint.Reference, args, nil
+}
+
+func runHARCommand(ctx context.Context, s ssh.Session,
+	apiClient *client.Client, args []string,
+) error {
+	reference, args, err := referenceFromArgs(ctx, apiClient, args)
+	if err != nil {
+		return err
+	}
+
+	b, err := apiClient.EndpointHAR(ctx, reference, args)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Write(b)
+	return err
+}
+
+func runImportCommand(ctx context.Context, s ssh.Session,
+	apiClient *client.Client, args []string,
+) error {
+	var keepAnnotations bool
+
+	if len(args) > 0 && args[0] == "--keep-annotations" {
+		keepAnnotations = true
+		args = args[1:]
+	}
+
+	reference, _, err := referenceFromArgs(ctx, apiClient, args)
+	if err != nil {
+		return err
+	}
+
+	// the http client closes request bodies once sent. Hide the session's
+	// Close so the output below can still be written
+	imported, err := apiClient.Import(ctx, reference, io.NopCloser(s), keepAnnotations)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(s, "Imported %d requests into %s\n", imported, reference)
+	return err
+}
+
+func runProtobufCommand(ctx context.Context, s ssh.Session,
+	apiClient *client.Client, args []string,
+) error {
+	reference, args, err := referenceFromArgs(ctx, apiClient, args)
+	if err != nil {
+		return err
+	}
+
+	var defaultMessage string
+	if len(args) > 0 {
+		defaultMessage = args[0]
+	}
+
+	messages, err := apiClient.UploadDescriptorSet(ctx, reference, defaultMessage, io.NopCloser(s))
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(s, "Uploaded %d protobuf messages for %s:\n  %s\n",
+		len(messages), reference, strings.Join(messages, "\n  "))
+	return err
+}
@@ -36,7 +36,7 @@ type HTTPConfig struct {
 	// only ran by an admin
 	// Endpoints to create a new url as an example should only be ran by an admin
 	// or the ssh server ( after it has verified we have a verified connection)
-	// If empty, server would crash
+	// The http server refuses to start when it is empty
 	AdminSecret string `mapstructure:"admin_secret" json:"admin_secret,omitempty" yaml:"admin_secret"`
 
 	Database DatabaseConfig `mapstructure:"database" json:"database,omitempty" yaml:"database"`
@@ -44,6 +44,14 @@ type HTTPConfig struct {
 	Domain             string `json:"domain,omitempty" yaml:"domain" mapstructure:"domain"`
 	MaxRequestBodySize int64  `json:"max_request_body_size,omitempty" yaml:"max_request_body_size" mapstructure:"max_request_body_size"`
 
+	// MaxDecodedBodySize limits how large a compressed request body can grow
+	// when it is decoded. It protects the server from zip bombs
+	MaxDecodedBodySize int64 `json:"max_decoded_body_size,omitempty" yaml:"max_decoded_body_size" mapstructure:"max_decoded_body_size"`
+
+	// MaxImportSize limits the size of HAR and NDJSON archives that can be
+	// uploaded to an endpoint
+	MaxImportSize int64 `json:"max_import_size,omitempty" yaml:"max_import_size" mapstructure:"max_import_size"`
+
 	OTEL struct {
 		UseTLS      bool   `json:"use_tls,omitempty" mapstructure:"use_tls" yaml:"use_tls"`
 		ServiceName string `json:"service_name,omitempty" mapstructure:"service_name" yaml:"service_name"`
@@ -62,10 +70,55 @@ type HTTPConfig struct {
 	RateLimit struct {
 		RequestsPerMinute uint64 `json:"requests_per_minute,omitempty" mapstructure:"requests_per_minute"`
 	} `json:"rate_limit,omitempty" mapstructure:"rate_limit"`
+
+	Replay ReplayConfig `json:"replay,omitempty" mapstructure:"replay" yaml:"replay"`
+}
+
+type ReplayConfig struct {
+	// Timeout is how long to wait for the target to respond to a replayed
+	// request
+	Timeout time.Duration `json:"timeout,omitempty" mapstructure:"timeout" yaml:"timeout"`
+
+	// AllowPrivateNetworks allows replaying captured requests to loopback and
+	// private addresses. Enable this if you self host sdump and want to replay
+	// to a server on your machine. Keep it disabled on public instances
+	AllowPrivateNetworks bool `json:"allow_private_networks,omitempty" mapstructure:"allow_private_networks" yaml:"allow_private_networks"`
 }
 
 type TUIConfig struct {
 	ColorScheme string `mapstructure:"color_scheme" yaml:"color_scheme" json:"color_scheme,omitempty"`
+
+	// Theme colors everything but request bodies. It is one of auto, dark,
+	// light, dracula, nord or monochrome. auto picks dark or light from the
+	// terminal background
+	Theme string `mapstructure:"theme" yaml:"theme" json:"theme,omitempty"`
+
+	// Colors replaces single colors of the theme such as accent: "#FF5F87"
+	Colors map[string]string `mapstructure:"colors" yaml:"colors" json:"colors,omitempty"`
+
+	Keys KeysConfig `mapstructure:"keys" yaml:"keys" json:"keys,omitempty"`
+
+	Notify NotifyConfig `mapstructure:"notify" yaml:"notify" json:"notify,omitempty"`
+}
+
+type NotifyConfig struct {
+	// Mode is how new requests are announced. It is one of off, bell or
+	// desktop. It can be changed from the TUI
+	Mode string `mapstructure:"mode" yaml:"mode" json:"mode,omitempty"`
+
+	// Filter limits notifications to requests matching the query. It uses
+	// the syntax of the filter in the TUI
+	Filter string `mapstructure:"filter" yaml:"filter" json:"filter,omitempty"`
+}
+
+type KeysConfig struct {
+	// Preset is the keymap the bindings are applied on top of. It is one of
+	// default, vim or emacs
+	Preset string `mapstructure:"preset" yaml:"preset" json:"preset,omitempty"`
+
+	// Bindings replaces the keys of an action such as replay: ["ctrl+t"].
+	// An empty list unbinds the action
+	Bindings map[string][]string `mapstructure:"bindings" yaml:"bindings" json:"bindings,omitempty"`
 }
 
 type CronConfig struct {
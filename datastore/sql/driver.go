@@ -1,12 +1,15 @@
 package sql
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 
 	"github.com/ayinke-llc/sdump/config"
 	"github.com/oiime/logrusbun"
 	"github.com/sirupsen/logrus"
 	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
 	"github.com/uptrace/bun/dialect/pgdialect"
 	"github.com/uptrace/bun/dialect/sqlitedialect"
 	"github.com/uptrace/bun/driver/pgdriver"
@@ -42,7 +45,51 @@ func newSqlite(cfg config.DatabaseConfig) (*bun.DB, error) {
 		db.AddQueryHook(logrusbun.NewQueryHook(logrusbun.QueryHookOptions{Logger: log}))
 	}
 
-	return db, db.Ping()
+	if err := db.Ping(); err != nil {
+		return nil, err
+	}
+
+	return db, ensureSqliteSearchIndex(context.Background(), db)
+}
+
+// jsonText returns an expression that extracts a top level field of a json
+// column as text in the dialect of the database
+func jsonText(db *bun.DB, column, field string) string {
+	if db.Dialect().Name() == dialect.SQLite {
+		return fmt.Sprintf("json_extract(%s, '$.%s')", column, field)
+	}
+
+	return fmt.Sprintf("%s->>'%s'", column, field)
+}
+
+// jsonInt returns an expression that extracts a top level field of a json
+// column as an integer in the dialect of the database
+func jsonInt(db *bun.DB, column, field string) string {
+	if db.Dialect().Name() == dialect.SQLite {
+		return fmt.Sprintf("CAST(json_extract(%s, '$.%s') AS INTEGER)", column, field)
+	}
+
+	return fmt.Sprintf("CAST(%s->>'%s' AS BIGINT)", column, field)
+}
+
+// unixMinute returns an expression that truncates a timestamp column to the
+// number of minutes since the unix epoch
+func unixMinute(db *bun.DB, column string) string {
+	if db.Dialect().Name() == dialect.SQLite {
+		return fmt.Sprintf("CAST(strftime('%%s', %s) AS INTEGER) / 60", column)
+	}
+
+	return fmt.Sprintf("CAST(FLOOR(EXTRACT(EPOCH FROM %s) / 60) AS BIGINT)", column)
+}
+
+// emptyJSONArray returns a condition that is true when a json column holds
+// an empty array
+func emptyJSONArray(db *bun.DB, column string) string {
+	if db.Dialect().Name() == dialect.SQLite {
+		return fmt.Sprintf("json_array_length(%s) = 0", column)
+	}
+
+	return fmt.Sprintf("jsonb_array_length(%s) = 0", column)
 }
 
 func New(cfg config.DatabaseConfig) (*bun.DB, error) {
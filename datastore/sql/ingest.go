@@ -2,6 +2,11 @@ package sql
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/ayinke-llc/sdump"
 	"github.com/uptrace/bun"
@@ -25,6 +30,92 @@ func (u *ingestRepository) Create(ctx context.Context,
 	return err
 }
 
+// createManyBatchSize caps how many requests are sent in a single insert
+const createManyBatchSize = 100
+
+func (u *ingestRepository) CreateMany(ctx context.Context,
+	models []sdump.IngestHTTPRequest,
+) error {
+	return u.inner.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		for start := 0; start < len(models); start += createManyBatchSize {
+			batch := models[start:min(start+createManyBatchSize, len(models))]
+
+			if _, err := tx.NewInsert().Model(&batch).Exec(ctx); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (u *ingestRepository) Get(ctx context.Context,
+	opts *sdump.FindIngestOptions,
+) (*sdump.IngestHTTPRequest, error) {
+	res := new(sdump.IngestHTTPRequest)
+
+	err := bun.NewSelectQuery(u.inner).Model(res).
+		Where("id = ?", opts.ID).
+		Scan(ctx)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, sdump.ErrIngestNotFound
+	}
+
+	return res, err
+}
+
+func (u *ingestRepository) List(ctx context.Context,
+	opts *sdump.ListIngestOptions,
+) ([]sdump.IngestHTTPRequest, error) {
+	var ingests []sdump.IngestHTTPRequest
+
+	query := bun.NewSelectQuery(u.inner).Model(&ingests).
+		Where("url_id = ?", opts.UrlID)
+
+	if opts.NewestFirst {
+		query = query.Order("created_at DESC", "id DESC")
+	} else {
+		query = query.Order("created_at ASC", "id ASC")
+	}
+
+	if len(opts.IDs) > 0 {
+		query = query.Where("id IN (?)", bun.In(opts.IDs))
+	}
+
+	if !opts.From.IsZero() {
+		query = query.Where("created_at >= ?", opts.From)
+	}
+
+	if !opts.To.IsZero() {
+		query = query.Where("created_at <= ?", opts.To)
+	}
+
+	if opts.After != nil {
+		op := ">"
+		if opts.NewestFirst {
+			op = "<"
+		}
+
+		query = query.Where(fmt.Sprintf("(created_at %s ? OR (created_at = ? AND id %s ?))", op, op),
+			opts.After.CreatedAt, opts.After.CreatedAt, opts.After.ID)
+	}
+
+	if opts.Method != "" {
+		query = query.Where(jsonText(u.inner, "request", "method")+" = ?",
+			strings.ToUpper(opts.Method))
+	}
+
+	query = applyIngestSearch(u.inner, query, opts)
+
+	if opts.Limit > 0 {
+		query = query.Limit(opts.Limit)
+	}
+
+	err := query.Scan(ctx)
+	return ingests, err
+}
+
 func (u *ingestRepository) Delete(ctx context.Context,
 	opts *sdump.DeleteIngestedRequestOptions,
 ) error {
@@ -36,7 +127,11 @@ func (u *ingestRepository) Delete(ctx context.Context,
 
 	deleteQuery := bun.NewDeleteQuery(u.inner).
 		Model((*sdump.IngestHTTPRequest)(nil)).
-		Where("created_at < ?", opts.Before)
+		Where("created_at < ?", opts.Before).
+		// requests that were starred, noted or tagged are kept around
+		Where("starred = ?", false).
+		Where("note = ''").
+		Where("(tags IS NULL OR " + emptyJSONArray(u.inner, "tags") + ")")
 
 	if !opts.UseSoftDeletes {
 		deleteQuery = deleteQuery.ForceDelete()
@@ -45,3 +140,121 @@ func (u *ingestRepository) Delete(ctx context.Context,
 	_, err := deleteQuery.Exec(ctx)
 	return err
 }
+
+func (u *ingestRepository) Annotate(ctx context.Context,
+	model *sdump.IngestHTTPRequest,
+) error {
+	model.UpdatedAt = time.Now()
+
+	// the column cannot be null
+	if model.Tags == nil {
+		model.Tags = []string{}
+	}
+
+	_, err := bun.NewUpdateQuery(u.inner).Model(model).
+		Column("starred", "note", "tags", "updated_at").
+		WherePK().
+		Exec(ctx)
+	return err
+}
+
+func (u *ingestRepository) Stats(ctx context.Context,
+	opts *sdump.IngestStatsOptions,
+) (*sdump.IngestStats, error) {
+	stats := &sdump.IngestStats{
+		Methods:   map[string]int64{},
+		TopIPs:    []sdump.IPCount{},
+		TopPaths:  []sdump.PathCount{},
+		PerMinute: []sdump.MinuteCount{},
+	}
+
+	size := jsonInt(u.inner, "request", "size")
+
+	err := bun.NewSelectQuery(u.inner).Model((*sdump.IngestHTTPRequest)(nil)).
+		ColumnExpr("COUNT(*)").
+		ColumnExpr("COALESCE(SUM("+size+"), 0)").
+		ColumnExpr("COALESCE(MAX("+size+"), 0)").
+		Where("url_id = ?", opts.UrlID).
+		Scan(ctx, &stats.Total, &stats.TotalSize, &stats.MaxSize)
+	if err != nil {
+		return nil, err
+	}
+
+	var methods []struct {
+		Method string `bun:"method"`
+		Count  int64  `bun:"count"`
+	}
+
+	err = bun.NewSelectQuery(u.inner).Model((*sdump.IngestHTTPRequest)(nil)).
+		ColumnExpr(jsonText(u.inner, "request", "method")+" AS method").
+		ColumnExpr("COUNT(*) AS count").
+		Where("url_id = ?", opts.UrlID).
+		GroupExpr("method").
+		Scan(ctx, &methods)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, method := range methods {
+		stats.Methods[method.Method] = method.Count
+	}
+
+	if opts.TopIPs > 0 {
+		ip := jsonText(u.inner, "request", "ip_address")
+
+		err = bun.NewSelectQuery(u.inner).Model((*sdump.IngestHTTPRequest)(nil)).
+			ColumnExpr(ip+" AS ip").
+			ColumnExpr("COUNT(*) AS count").
+			Where("url_id = ?", opts.UrlID).
+			Where(ip+" IS NOT NULL").
+			GroupExpr("ip").
+			OrderExpr("count DESC, ip ASC").
+			Limit(opts.TopIPs).
+			Scan(ctx, &stats.TopIPs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.TopPaths > 0 {
+		path := "COALESCE(NULLIF(" + jsonText(u.inner, "request", "path") + ", ''), '/')"
+
+		err = bun.NewSelectQuery(u.inner).Model((*sdump.IngestHTTPRequest)(nil)).
+			ColumnExpr(path+" AS path").
+			ColumnExpr("COUNT(*) AS count").
+			Where("url_id = ?", opts.UrlID).
+			GroupExpr("path").
+			OrderExpr("count DESC, path ASC").
+			Limit(opts.TopPaths).
+			Scan(ctx, &stats.TopPaths)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var minutes []struct {
+		Minute int64 `bun:"minute"`
+		Count  int64 `bun:"count"`
+	}
+
+	err = bun.NewSelectQuery(u.inner).Model((*sdump.IngestHTTPRequest)(nil)).
+		ColumnExpr(unixMinute(u.inner, "created_at")+" AS minute").
+		ColumnExpr("COUNT(*) AS count").
+		Where("url_id = ?", opts.UrlID).
+		Where("created_at >= ?", opts.Since).
+		GroupExpr("minute").
+		OrderExpr("minute ASC").
+		Scan(ctx, &minutes)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, minute := range minutes {
+		stats.PerMinute = append(stats.PerMinute, sdump.MinuteCount{
+			Minute: time.Unix(minute.Minute*60, 0).UTC(),
+			Count:  minute.Count,
+		})
+	}
+
+	return stats, nil
+}
@@ -5,12 +5,20 @@ package sql
 
 import (
 	"context"
+	"net"
+	"net/http"
 	"testing"
+	"time"
 
 	"github.com/ayinke-llc/sdump"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
 )
 
+// see ingests.yml
+var ingestID = uuid.MustParse("0c7b3b0a-6f4d-4f0e-9a6e-0c7f6fd2c6a1")
+
 func TestIngestRepository_Create(t *testing.T) {
 	client, teardownFunc := setupPostgresDatabase(t)
 	defer teardownFunc()
@@ -31,3 +39,399 @@ func TestIngestRepository_Create(t *testing.T) {
 		},
 	}))
 }
+
+func TestIngestRepository_CreateMany(t *testing.T) {
+	databases := map[string]func(t *testing.T) (*bun.DB, func()){
+		"postgres": setupPostgresDatabase,
+		"sqlite":   setupSqliteIngestsDatabase,
+	}
+
+	urlID := uuid.MustParse("df1f03c9-1831-442a-9035-0f77bc413ec1") // see fixtures/urls.yml
+	createdAt := time.Date(2025, time.April, 1, 9, 0, 0, 0, time.UTC)
+
+	newIngests := func(n int) []sdump.IngestHTTPRequest {
+		ingests := make([]sdump.IngestHTTPRequest, 0, n)
+		for i := 0; i < n; i++ {
+			ingests = append(ingests, sdump.IngestHTTPRequest{
+				ID:        uuid.New(),
+				UrlID:     urlID,
+				CreatedAt: createdAt,
+				UpdatedAt: createdAt,
+			})
+		}
+
+		return ingests
+	}
+
+	count := func(t *testing.T, ingestStore sdump.IngestRepository) int {
+		ingests, err := ingestStore.List(context.Background(), &sdump.ListIngestOptions{
+			UrlID: urlID,
+			From:  createdAt,
+		})
+		require.NoError(t, err)
+		return len(ingests)
+	}
+
+	for name, setup := range databases {
+		t.Run(name, func(t *testing.T) {
+			client, teardownFunc := setup(t)
+			defer teardownFunc()
+
+			ingestStore := NewIngestRepository(client)
+
+			// more than one batch
+			require.NoError(t, ingestStore.CreateMany(context.Background(), newIngests(createManyBatchSize+5)))
+			require.Equal(t, createManyBatchSize+5, count(t, ingestStore))
+
+			// the duplicate id in the second batch fails the insert. The
+			// first batch must be rolled back with it
+			ingests := newIngests(createManyBatchSize + 1)
+			ingests[createManyBatchSize].ID = ingests[0].ID
+
+			require.Error(t, ingestStore.CreateMany(context.Background(), ingests))
+			require.Equal(t, createManyBatchSize+5, count(t, ingestStore))
+		})
+	}
+}
+
+func TestIngestRepository_Get(t *testing.T) {
+	client, teardownFunc := setupPostgresDatabase(t)
+	defer teardownFunc()
+
+	ingestStore := NewIngestRepository(client)
+
+	_, err := ingestStore.Get(context.Background(), &sdump.FindIngestOptions{
+		ID: uuid.New(),
+	})
+	require.Error(t, err)
+	require.ErrorIs(t, err, sdump.ErrIngestNotFound)
+
+	ingest, err := ingestStore.Get(context.Background(), &sdump.FindIngestOptions{
+		ID: ingestID, // see fixtures/ingests.yml
+	})
+	require.NoError(t, err)
+	require.Equal(t, "POST", ingest.Request.Method)
+}
+
+func TestIngestRepository_List(t *testing.T) {
+	client, teardownFunc := setupPostgresDatabase(t)
+	defer teardownFunc()
+
+	ingestStore := NewIngestRepository(client)
+
+	urlID := uuid.MustParse("df1f03c9-1831-442a-9035-0f77bc413ec1") // see fixtures/urls.yml
+
+	ingests, err := ingestStore.List(context.Background(), &sdump.ListIngestOptions{
+		UrlID:  urlID,
+		Method: "post",
+	})
+	require.NoError(t, err)
+	require.Len(t, ingests, 1)
+
+	ingests, err = ingestStore.List(context.Background(), &sdump.ListIngestOptions{
+		UrlID:  urlID,
+		Method: "GET",
+	})
+	require.NoError(t, err)
+	require.Len(t, ingests, 0)
+
+	ingests, err = ingestStore.List(context.Background(), &sdump.ListIngestOptions{
+		UrlID: urlID,
+		From:  time.Now(),
+	})
+	require.NoError(t, err)
+	require.Len(t, ingests, 0)
+
+	ingests, err = ingestStore.List(context.Background(), &sdump.ListIngestOptions{
+		UrlID:       urlID,
+		After:       &sdump.IngestCursor{CreatedAt: time.Now()},
+		NewestFirst: true,
+		Limit:       10,
+	})
+	require.NoError(t, err)
+	require.Len(t, ingests, 1)
+}
+
+func TestIngestRepository_List_Search(t *testing.T) {
+	client, teardownFunc := setupPostgresDatabase(t)
+	defer teardownFunc()
+
+	ingestStore := NewIngestRepository(client)
+
+	urlID := uuid.MustParse("df1f03c9-1831-442a-9035-0f77bc413ec1") // see fixtures/urls.yml
+
+	tt := []struct {
+		name     string
+		text     []string
+		body     []sdump.BodyMatch
+		expected int
+	}{
+		{name: "text found", text: []string{"SDUMP"}, expected: 1},
+		{name: "text not found", text: []string{"stripe"}, expected: 0},
+		{name: "body value found", body: []sdump.BodyMatch{{Path: []string{"name"}, Value: "sdump"}}, expected: 1},
+		{name: "body value not found", body: []sdump.BodyMatch{{Path: []string{"name"}, Value: "stripe"}}, expected: 0},
+		{name: "body path not found", body: []sdump.BodyMatch{{Path: []string{"data", "0", "name"}, Value: "sdump"}}, expected: 0},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			ingests, err := ingestStore.List(context.Background(), &sdump.ListIngestOptions{
+				UrlID: urlID,
+				Text:  v.text,
+				Body:  v.body,
+			})
+			require.NoError(t, err)
+			require.Len(t, ingests, v.expected)
+		})
+	}
+}
+
+func TestIngestRepository_List_SearchText(t *testing.T) {
+	databases := map[string]func(t *testing.T) (*bun.DB, func()){
+		"postgres": setupPostgresDatabase,
+		"sqlite":   setupSqliteIngestsDatabase,
+	}
+
+	urlID := uuid.MustParse("df1f03c9-1831-442a-9035-0f77bc413ec1") // see fixtures/urls.yml
+	ingestID := uuid.MustParse("5a0e4f3e-2a52-4d67-8f0a-4b8f3c1e9d10")
+
+	tt := []struct {
+		name     string
+		text     []string
+		expected int
+	}{
+		{name: "header value", text: []string{"push"}, expected: 1},
+		{name: "header name", text: []string{"X-GitHub-Event"}, expected: 1},
+		{name: "header value with quotes", text: []string{`W/"1234"`}, expected: 1},
+		{name: "body with backslashes", text: []string{`C:\sdump`}, expected: 1},
+		{name: "short term", text: []string{"ok"}, expected: 1},
+		{name: "body and header", text: []string{"sdump", "push"}, expected: 1},
+		{name: "not found", text: []string{"ping"}, expected: 0},
+		{name: "json encoding is not searched", text: []string{`\"`}, expected: 0},
+	}
+
+	for name, setup := range databases {
+		t.Run(name, func(t *testing.T) {
+			client, teardownFunc := setup(t)
+			defer teardownFunc()
+
+			ingestStore := NewIngestRepository(client)
+
+			require.NoError(t, ingestStore.Create(context.Background(), &sdump.IngestHTTPRequest{
+				ID:    ingestID,
+				UrlID: urlID,
+				Request: sdump.RequestDefinition{
+					Method: "POST",
+					Body:   `path=C:\sdump&status=ok`,
+					Headers: http.Header{
+						"X-Github-Event": []string{"push"},
+						"If-None-Match":  []string{`W/"1234"`},
+					},
+				},
+				CreatedAt: time.Now(),
+				UpdatedAt: time.Now(),
+			}))
+
+			for _, v := range tt {
+				t.Run(v.name, func(t *testing.T) {
+					ingests, err := ingestStore.List(context.Background(), &sdump.ListIngestOptions{
+						UrlID: urlID,
+						IDs:   []uuid.UUID{ingestID},
+						Text:  v.text,
+					})
+					require.NoError(t, err)
+					require.Len(t, ingests, v.expected)
+				})
+			}
+		})
+	}
+}
+
+func TestIngestRepository_List_Cursor(t *testing.T) {
+	databases := map[string]func(t *testing.T) (*bun.DB, func()){
+		"postgres": setupPostgresDatabase,
+		"sqlite":   setupSqliteIngestsDatabase,
+	}
+
+	urlID := uuid.MustParse("df1f03c9-1831-442a-9035-0f77bc413ec1") // see fixtures/urls.yml
+	createdAt := time.Date(2025, time.March, 1, 9, 0, 0, 0, time.UTC)
+
+	for name, setup := range databases {
+		t.Run(name, func(t *testing.T) {
+			client, teardownFunc := setup(t)
+			defer teardownFunc()
+
+			ingestStore := NewIngestRepository(client)
+
+			// every request is captured at the same time so every page
+			// boundary falls between requests sharing a timestamp
+			created := make(map[uuid.UUID]bool)
+			for i := 0; i < 5; i++ {
+				ingest := &sdump.IngestHTTPRequest{
+					ID:        uuid.New(),
+					UrlID:     urlID,
+					CreatedAt: createdAt,
+					UpdatedAt: createdAt,
+				}
+
+				require.NoError(t, ingestStore.Create(context.Background(), ingest))
+				created[ingest.ID] = true
+			}
+
+			seen := make(map[uuid.UUID]bool)
+
+			var after *sdump.IngestCursor
+			for {
+				ingests, err := ingestStore.List(context.Background(), &sdump.ListIngestOptions{
+					UrlID:       urlID,
+					From:        createdAt,
+					After:       after,
+					NewestFirst: true,
+					Limit:       2,
+				})
+				require.NoError(t, err)
+
+				for _, ingest := range ingests {
+					require.False(t, seen[ingest.ID], "%s was returned twice", ingest.ID)
+					seen[ingest.ID] = true
+				}
+
+				if len(ingests) < 2 {
+					break
+				}
+
+				last := ingests[len(ingests)-1]
+				after = &sdump.IngestCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+			}
+
+			require.Equal(t, created, seen)
+		})
+	}
+}
+
+func TestIngestRepository_Annotate(t *testing.T) {
+	client, teardownFunc := setupPostgresDatabase(t)
+	defer teardownFunc()
+
+	ingestStore := NewIngestRepository(client)
+
+	urlID := uuid.MustParse("df1f03c9-1831-442a-9035-0f77bc413ec1") // see fixtures/urls.yml
+
+	annotated := &sdump.IngestHTTPRequest{
+		UrlID: urlID,
+		Request: sdump.RequestDefinition{
+			Body: "{}",
+		},
+	}
+	require.NoError(t, ingestStore.Create(context.Background(), annotated))
+
+	annotated.Note = "reproduces bug #431"
+	annotated.Tags = []string{"stripe", "bug"}
+	require.NoError(t, ingestStore.Annotate(context.Background(), annotated))
+
+	ingest, err := ingestStore.Get(context.Background(), &sdump.FindIngestOptions{
+		ID: annotated.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "reproduces bug #431", ingest.Note)
+	require.Equal(t, []string{"stripe", "bug"}, ingest.Tags)
+	require.False(t, ingest.Starred)
+
+	require.NoError(t, ingestStore.Delete(context.Background(), &sdump.DeleteIngestedRequestOptions{
+		Before: time.Now().Add(time.Hour),
+	}))
+
+	// annotated requests are never pruned
+	_, err = ingestStore.Get(context.Background(), &sdump.FindIngestOptions{
+		ID: annotated.ID,
+	})
+	require.NoError(t, err)
+
+	_, err = ingestStore.Get(context.Background(), &sdump.FindIngestOptions{
+		ID: ingestID,
+	})
+	require.ErrorIs(t, err, sdump.ErrIngestNotFound)
+}
+
+func TestIngestRepository_Stats(t *testing.T) {
+	client, teardownFunc := setupPostgresDatabase(t)
+	defer teardownFunc()
+
+	ingestStore := NewIngestRepository(client)
+
+	urlID := uuid.MustParse("df1f03c9-1831-442a-9035-0f77bc413ec1") // see fixtures/urls.yml
+
+	require.NoError(t, ingestStore.Create(context.Background(), &sdump.IngestHTTPRequest{
+		UrlID: urlID,
+		Request: sdump.RequestDefinition{
+			Body:      "{}",
+			Method:    "GET",
+			Size:      2,
+			IPAddress: net.ParseIP("10.0.0.1"),
+		},
+	}))
+
+	stats, err := ingestStore.Stats(context.Background(), &sdump.IngestStatsOptions{
+		UrlID:  urlID,
+		Since:  time.Now().Add(-time.Hour),
+		TopIPs: 5,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), stats.Total)
+	require.Equal(t, int64(19), stats.TotalSize)
+	require.Equal(t, int64(17), stats.MaxSize)
+	require.Equal(t, map[string]int64{"GET": 1, "POST": 1}, stats.Methods)
+	require.Equal(t, []sdump.IPCount{{IP: "10.0.0.1", Count: 1}}, stats.TopIPs)
+
+	// the request from the fixtures is older than an hour
+	require.Len(t, stats.PerMinute, 1)
+	require.Equal(t, int64(1), stats.PerMinute[0].Count)
+}
+
+func TestIngestRepository_Stats_TopPaths(t *testing.T) {
+	databases := map[string]struct {
+		setup func(t *testing.T) (*bun.DB, func())
+		// fixtures is how many requests were already sent to the endpoint
+		// itself
+		fixtures int64
+	}{
+		"postgres": {setup: setupPostgresDatabase, fixtures: 1}, // see fixtures/ingests.yml
+		"sqlite":   {setup: setupSqliteIngestsDatabase},
+	}
+
+	urlID := uuid.MustParse("df1f03c9-1831-442a-9035-0f77bc413ec1") // see fixtures/urls.yml
+
+	for name, db := range databases {
+		t.Run(name, func(t *testing.T) {
+			client, teardownFunc := db.setup(t)
+			defer teardownFunc()
+
+			ingestStore := NewIngestRepository(client)
+
+			for _, path := range []string{"", "", "/stripe/events", "/github", "/github", "/github", "/github", "/slack"} {
+				require.NoError(t, ingestStore.Create(context.Background(), &sdump.IngestHTTPRequest{
+					ID:    uuid.New(),
+					UrlID: urlID,
+					Request: sdump.RequestDefinition{
+						Method: "POST",
+						Path:   path,
+					},
+				}))
+			}
+
+			stats, err := ingestStore.Stats(context.Background(), &sdump.IngestStatsOptions{
+				UrlID:    urlID,
+				Since:    time.Now().Add(-time.Hour),
+				TopPaths: 3,
+			})
+			require.NoError(t, err)
+
+			// requests sent to the endpoint itself are counted as /
+			require.Equal(t, []sdump.PathCount{
+				{Path: "/github", Count: 4},
+				{Path: "/", Count: 2 + db.fixtures},
+				{Path: "/slack", Count: 1},
+			}, stats.TopPaths)
+		})
+	}
+}
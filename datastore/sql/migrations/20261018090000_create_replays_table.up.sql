@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS replays(
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    ingest_id uuid NOT NULL REFERENCES ingests(id) ON DELETE CASCADE,
+    target_url TEXT NOT NULL,
+
+    request jsonb NOT NULL DEFAULT '{}'::jsonb,
+    response jsonb NOT NULL DEFAULT '{}'::jsonb,
+    error TEXT NOT NULL DEFAULT '',
+
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS replays_ingest_id_idx ON replays(ingest_id);
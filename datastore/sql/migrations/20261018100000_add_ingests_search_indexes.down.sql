@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS ingests_search_text_trgm_idx;
+DROP INDEX IF EXISTS ingests_request_body_jsonb_idx;
+DROP FUNCTION IF EXISTS sdump_search_text(jsonb);
+DROP FUNCTION IF EXISTS sdump_try_jsonb(TEXT);
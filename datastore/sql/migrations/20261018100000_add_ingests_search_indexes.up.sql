@@ -0,0 +1,30 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- bodies are stored as text since they are not always valid JSON. This
+-- returns NULL instead of failing so JSON searches can skip those bodies
+CREATE OR REPLACE FUNCTION sdump_try_jsonb(body TEXT) RETURNS jsonb AS $$
+BEGIN
+    RETURN body::jsonb;
+EXCEPTION WHEN others THEN
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+CREATE INDEX IF NOT EXISTS ingests_request_body_jsonb_idx
+    ON ingests USING GIN (sdump_try_jsonb(request->>'body') jsonb_path_ops);
+
+-- request::text is JSON encoded so quotes and backslashes in bodies and
+-- headers are escaped and never match what was searched for. This is the
+-- body and every header, as name: value, exactly as they were sent
+CREATE OR REPLACE FUNCTION sdump_search_text(request jsonb) RETURNS TEXT AS $$
+    SELECT concat_ws(E'\n', request->>'body', (
+        SELECT string_agg(h.key || ': ' || v.value, E'\n')
+        FROM jsonb_each(CASE WHEN jsonb_typeof(request->'headers') = 'object'
+                THEN request->'headers' ELSE '{}'::jsonb END) AS h,
+            jsonb_array_elements_text(CASE WHEN jsonb_typeof(h.value) = 'array'
+                THEN h.value ELSE '[]'::jsonb END) AS v
+    ))
+$$ LANGUAGE sql IMMUTABLE;
+
+CREATE INDEX IF NOT EXISTS ingests_search_text_trgm_idx
+    ON ingests USING GIN (sdump_search_text(request) gin_trgm_ops);
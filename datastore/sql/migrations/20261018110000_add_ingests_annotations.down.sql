@@ -0,0 +1,3 @@
+ALTER TABLE ingests DROP COLUMN IF EXISTS tags;
+ALTER TABLE ingests DROP COLUMN IF EXISTS note;
+ALTER TABLE ingests DROP COLUMN IF EXISTS starred;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS preferences(
+    user_id uuid PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+
+    color_scheme TEXT NOT NULL DEFAULT '',
+    timestamp_format TEXT NOT NULL DEFAULT '',
+    timezone TEXT NOT NULL DEFAULT '',
+    body_view TEXT NOT NULL DEFAULT '',
+    keymap_preset TEXT NOT NULL DEFAULT '',
+
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
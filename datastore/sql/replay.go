@@ -0,0 +1,45 @@
+package sql
+
+import (
+	"context"
+
+	"github.com/ayinke-llc/sdump"
+	"github.com/uptrace/bun"
+)
+
+type replayRepositoryTable struct {
+	inner *bun.DB
+}
+
+func NewReplayRepositoryTable(db *bun.DB) sdump.ReplayRepository {
+	return &replayRepositoryTable{
+		inner: db,
+	}
+}
+
+func (r *replayRepositoryTable) Create(ctx context.Context,
+	model *sdump.Replay,
+) error {
+	_, err := bun.NewInsertQuery(r.inner).Model(model).
+		Exec(ctx)
+	return err
+}
+
+func (r *replayRepositoryTable) List(ctx context.Context,
+	opts *sdump.FindReplayOptions,
+) ([]sdump.Replay, error) {
+	var replays []sdump.Replay
+
+	query := bun.NewSelectQuery(r.inner).Model(&replays).
+		Order("created_at DESC")
+
+	if len(opts.IngestIDs) > 0 {
+		query = query.Where("ingest_id IN (?)", bun.In(opts.IngestIDs))
+	} else {
+		query = query.Where("ingest_id = ?", opts.IngestID)
+	}
+
+	err := query.Scan(ctx)
+
+	return replays, err
+}
@@ -0,0 +1,48 @@
+//go:build integration
+// +build integration
+
+package sql
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/ayinke-llc/sdump"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplayRepositoryTable_Create(t *testing.T) {
+	client, teardownFunc := setupPostgresDatabase(t)
+	defer teardownFunc()
+
+	replayStore := NewReplayRepositoryTable(client)
+
+	require.NoError(t, replayStore.Create(context.Background(), &sdump.Replay{
+		IngestID:  ingestID,
+		TargetURL: "http://localhost:3000",
+		Response: sdump.ResponseDefinition{
+			StatusCode: http.StatusOK,
+		},
+	}))
+}
+
+func TestReplayRepositoryTable_List(t *testing.T) {
+	client, teardownFunc := setupPostgresDatabase(t)
+	defer teardownFunc()
+
+	replayStore := NewReplayRepositoryTable(client)
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, replayStore.Create(context.Background(), &sdump.Replay{
+			IngestID:  ingestID,
+			TargetURL: "http://localhost:3000",
+		}))
+	}
+
+	replays, err := replayStore.List(context.Background(), &sdump.FindReplayOptions{
+		IngestID: ingestID,
+	})
+	require.NoError(t, err)
+	require.Len(t, replays, 2)
+}
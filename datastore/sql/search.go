@@ -0,0 +1,230 @@
+package sql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ayinke-llc/sdump"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"github.com/uptrace/bun/dialect/pgdialect"
+)
+
+// minTrigramLength is the shortest term the trigram indexes can look up
+const minTrigramLength = 3
+
+// applyIngestSearch narrows a query on the ingests table down to the
+// requests matching the full text and JSON body conditions of opts.
+//
+// Text is looked up in the body and headers as they were sent, not in the
+// JSON encoded request where quotes and backslashes are escaped.
+//
+// On Postgres, the conditions are written so they can use the indexes
+// created in the 20261018100000_add_ingests_search_indexes migration. On
+// SQLite, text search goes through the ingests_fts FTS5 table
+func applyIngestSearch(db *bun.DB, query *bun.SelectQuery,
+	opts *sdump.ListIngestOptions,
+) *bun.SelectQuery {
+	sqlite := db.Dialect().Name() == dialect.SQLite
+
+	for _, term := range opts.Text {
+		if term == "" {
+			continue
+		}
+
+		if sqlite {
+			query = sqliteTextSearch(query, term)
+			continue
+		}
+
+		query = query.Where("sdump_search_text(request) ILIKE ? ESCAPE '\\'", "%"+escapeLike(term)+"%")
+	}
+
+	for _, match := range opts.Body {
+		if len(match.Path) == 0 {
+			continue
+		}
+
+		if sqlite {
+			query = sqliteBodySearch(query, match)
+			continue
+		}
+
+		query = postgresBodySearch(query, match)
+	}
+
+	return query
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// jsonCandidates returns every JSON literal a query value can stand for. 123
+// can be the number 123 or the string "123"
+func jsonCandidates(value string) []any {
+	candidates := []any{value}
+
+	switch value {
+	case "true":
+		return append(candidates, true)
+	case "false":
+		return append(candidates, false)
+	case "null":
+		return append(candidates, nil)
+	}
+
+	if n, err := strconv.ParseFloat(value, 64); err == nil {
+		candidates = append(candidates, json.Number(strconv.FormatFloat(n, 'f', -1, 64)))
+	}
+
+	return candidates
+}
+
+func hasArrayIndex(path []string) bool {
+	for _, key := range path {
+		if _, err := strconv.Atoi(key); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// containmentDocument nests value under path so it can be used with @>
+func containmentDocument(path []string, value any) (string, error) {
+	doc := value
+	for i := len(path) - 1; i >= 0; i-- {
+		doc = map[string]any{path[i]: doc}
+	}
+
+	b, err := json.Marshal(doc)
+	return string(b), err
+}
+
+const postgresBody = "sdump_try_jsonb(request->>'body')"
+
+func postgresBodySearch(query *bun.SelectQuery, match sdump.BodyMatch) *bun.SelectQuery {
+	// containment treats arrays as sets so an indexed path cannot be
+	// expressed with it. Those fall back to extracting the value
+	if hasArrayIndex(match.Path) {
+		return query.Where(postgresBody+" #>> ? = ?",
+			pgdialect.Array(match.Path), match.Value)
+	}
+
+	return query.WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+		for _, candidate := range jsonCandidates(match.Value) {
+			doc, err := containmentDocument(match.Path, candidate)
+			if err != nil {
+				continue
+			}
+
+			q = q.WhereOr(postgresBody+" @> ?::jsonb", doc)
+		}
+
+		return q
+	})
+}
+
+func sqliteTextSearch(query *bun.SelectQuery, term string) *bun.SelectQuery {
+	// the trigram tokenizer cannot find terms shorter than a trigram
+	if len([]rune(term)) < minTrigramLength {
+		text := fmt.Sprintf("LOWER(COALESCE(json_extract(request, '$.body'), '') || char(10) || COALESCE(%s, ''))",
+			sqliteHeadersText("request"))
+
+		return query.Where(text+" LIKE ? ESCAPE '\\'", "%"+escapeLike(strings.ToLower(term))+"%")
+	}
+
+	phrase := `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+
+	return query.Where("id IN (SELECT id FROM ingests_fts WHERE ingests_fts MATCH ?)", phrase)
+}
+
+// sqliteJSONPath converts a path to the JSON path syntax of SQLite
+func sqliteJSONPath(path []string) string {
+	var b strings.Builder
+	b.WriteString("$")
+
+	for _, key := range path {
+		if idx, err := strconv.Atoi(key); err == nil && idx >= 0 {
+			fmt.Fprintf(&b, "[%d]", idx)
+			continue
+		}
+
+		fmt.Fprintf(&b, ".%q", key)
+	}
+
+	return b.String()
+}
+
+func sqliteBodySearch(query *bun.SelectQuery, match sdump.BodyMatch) *bun.SelectQuery {
+	const body = "json_extract(request, '$.body')"
+
+	// json_extract of a boolean returns 1 or 0
+	candidates := []any{match.Value}
+	switch match.Value {
+	case "true":
+		candidates = append(candidates, 1)
+	case "false":
+		candidates = append(candidates, 0)
+	default:
+		if n, err := strconv.ParseFloat(match.Value, 64); err == nil {
+			candidates = append(candidates, n)
+		}
+	}
+
+	return query.Where(
+		fmt.Sprintf("CASE WHEN json_valid(%s) THEN json_extract(%s, ?) END IN (?)", body, body),
+		sqliteJSONPath(match.Path), bun.In(candidates))
+}
+
+// sqliteHeadersText returns an expression listing the headers of the
+// request in column as name: value lines
+func sqliteHeadersText(column string) string {
+	return fmt.Sprintf(`(SELECT group_concat(h.key || ': ' || v.value, char(10))
+		FROM json_each(%s, '$.headers') AS h, json_each(h.value) AS v)`, column)
+}
+
+// sqliteSearchSchema keeps a trigram FTS5 index of the body and headers of
+// every captured request in sync with the ingests table
+var sqliteSearchSchema = []string{
+	`CREATE VIRTUAL TABLE IF NOT EXISTS ingests_fts USING fts5(
+		id UNINDEXED, body, headers, tokenize = 'trigram'
+	)`,
+	`CREATE TRIGGER IF NOT EXISTS ingests_fts_insert AFTER INSERT ON ingests BEGIN
+		INSERT INTO ingests_fts (id, body, headers)
+		VALUES (new.id, json_extract(new.request, '$.body'), ` + sqliteHeadersText("new.request") + `);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS ingests_fts_delete AFTER DELETE ON ingests BEGIN
+		DELETE FROM ingests_fts WHERE id = old.id;
+	END`,
+	`INSERT INTO ingests_fts (id, body, headers)
+		SELECT id, json_extract(request, '$.body'), ` + sqliteHeadersText("request") + `
+		FROM ingests WHERE id NOT IN (SELECT id FROM ingests_fts)`,
+}
+
+// ensureSqliteSearchIndex creates the FTS5 index once the ingests table
+// exists. It is safe to call on every start
+func ensureSqliteSearchIndex(ctx context.Context, db *bun.DB) error {
+	var count int
+
+	err := db.NewSelect().
+		TableExpr("sqlite_master").
+		ColumnExpr("COUNT(*)").
+		Where("type = 'table' AND name = 'ingests'").
+		Scan(ctx, &count)
+	if err != nil || count == 0 {
+		return err
+	}
+
+	for _, stmt := range sqliteSearchSchema {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("could not set up search index: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -4,6 +4,7 @@
 package sql
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"testing"
@@ -65,3 +66,34 @@ func setupSqliteDatabase(t *testing.T) (*bun.DB, func()) {
 	return client, func() {
 	}
 }
+
+// setupSqliteIngestsDatabase creates a database with only the ingests table.
+// The migrations are written for postgres so the table is created by hand
+func setupSqliteIngestsDatabase(t *testing.T) (*bun.DB, func()) {
+	t.Helper()
+
+	client, err := New(config.DatabaseConfig{
+		DSN:    fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name()),
+		Driver: config.DatabaseTypeSqlite,
+	})
+	require.NoError(t, err)
+
+	_, err = client.Exec(`CREATE TABLE ingests (
+		id TEXT PRIMARY KEY,
+		url_id TEXT NOT NULL,
+		request TEXT NOT NULL DEFAULT '{}',
+		starred BOOLEAN NOT NULL DEFAULT FALSE,
+		note TEXT NOT NULL DEFAULT '',
+		tags TEXT,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		deleted_at TIMESTAMP
+	)`)
+	require.NoError(t, err)
+
+	require.NoError(t, ensureSqliteSearchIndex(context.Background(), client))
+
+	return client, func() {
+		_ = client.Close()
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
 	"github.com/ayinke-llc/sdump"
 	"github.com/google/uuid"
@@ -68,3 +69,14 @@ func (u *urlRepositoryTable) Latest(ctx context.Context, userID uuid.UUID) (
 
 	return ret, err
 }
+
+func (u *urlRepositoryTable) Update(ctx context.Context,
+	model *sdump.URLEndpoint,
+) error {
+	model.UpdatedAt = time.Now()
+
+	_, err := bun.NewUpdateQuery(u.inner).Model(model).
+		WherePK().
+		Exec(ctx)
+	return err
+}
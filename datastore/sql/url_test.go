@@ -54,3 +54,29 @@ func TestURLRepositoryTable_Latest(t *testing.T) {
 
 	require.Equal(t, endpoint.Reference, "cmltg1eg330l5l1vq11g")
 }
+
+func TestURLRepositoryTable_Update(t *testing.T) {
+	client, teardownFunc := setupPostgresDatabase(t)
+	defer teardownFunc()
+
+	urlStore := NewURLRepositoryTable(client)
+
+	endpoint, err := urlStore.Get(context.Background(), &sdump.FindURLOptions{
+		Reference: "cmltfm6g330l5l1vq110", // see fixtures/urls.yml
+	})
+	require.NoError(t, err)
+
+	endpoint.Metadata.Protobuf = &sdump.ProtobufSchema{
+		DescriptorSet:  []byte{1, 2, 3},
+		DefaultMessage: "sdump.Event",
+	}
+
+	require.NoError(t, urlStore.Update(context.Background(), endpoint))
+
+	endpoint, err = urlStore.Get(context.Background(), &sdump.FindURLOptions{
+		Reference: "cmltfm6g330l5l1vq110",
+	})
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 2, 3}, endpoint.Metadata.Protobuf.DescriptorSet)
+	require.Equal(t, "sdump.Event", endpoint.Metadata.Protobuf.DefaultMessage)
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
 	"github.com/ayinke-llc/sdump"
+	"github.com/google/uuid"
 	"github.com/uptrace/bun"
 )
 
@@ -42,3 +44,36 @@ func (u *userRepositoryTable) Find(ctx context.Context,
 
 	return res, err
 }
+
+func (u *userRepositoryTable) FindPreferences(ctx context.Context,
+	userID uuid.UUID,
+) (*sdump.Preferences, error) {
+	res := new(sdump.Preferences)
+
+	err := bun.NewSelectQuery(u.inner).Model(res).
+		Where("user_id = ?", userID).
+		Scan(ctx)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, sdump.ErrPreferencesNotFound
+	}
+
+	return res, err
+}
+
+func (u *userRepositoryTable) SavePreferences(ctx context.Context,
+	model *sdump.Preferences,
+) error {
+	model.UpdatedAt = time.Now()
+
+	_, err := bun.NewInsertQuery(u.inner).Model(model).
+		On("CONFLICT (user_id) DO UPDATE").
+		Set("color_scheme = EXCLUDED.color_scheme").
+		Set("timestamp_format = EXCLUDED.timestamp_format").
+		Set("timezone = EXCLUDED.timezone").
+		Set("body_view = EXCLUDED.body_view").
+		Set("keymap_preset = EXCLUDED.keymap_preset").
+		Set("updated_at = EXCLUDED.updated_at").
+		Exec(ctx)
+	return err
+}
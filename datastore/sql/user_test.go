@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/ayinke-llc/sdump"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 )
 
@@ -43,3 +44,34 @@ func TestUserRepository_Find(t *testing.T) {
 
 	require.NoError(t, err)
 }
+
+func TestUserRepository_Preferences(t *testing.T) {
+	client, teardownFunc := setupPostgresDatabase(t)
+	defer teardownFunc()
+
+	userStore := NewUserRepositoryTable(client)
+
+	userID := uuid.MustParse("8511ac86-5079-42ae-a030-cb46e6dbfbda")
+
+	_, err := userStore.FindPreferences(context.Background(), userID)
+	require.ErrorIs(t, err, sdump.ErrPreferencesNotFound)
+
+	require.NoError(t, userStore.SavePreferences(context.Background(), &sdump.Preferences{
+		UserID:       userID,
+		ColorScheme:  "dracula",
+		KeymapPreset: "vim",
+	}))
+
+	require.NoError(t, userStore.SavePreferences(context.Background(), &sdump.Preferences{
+		UserID:      userID,
+		ColorScheme: "monokai",
+		Timezone:    "Africa/Lagos",
+	}))
+
+	preferences, err := userStore.FindPreferences(context.Background(), userID)
+	require.NoError(t, err)
+
+	require.Equal(t, "monokai", preferences.ColorScheme)
+	require.Equal(t, "Africa/Lagos", preferences.Timezone)
+	require.Empty(t, preferences.KeymapPreset)
+}
@@ -3,3 +3,4 @@ package sdump
 //go:generate mockgen --source url.go -destination mocks/url.go -package mocks
 //go:generate mockgen --source ingest.go -destination mocks/ingest.go -package mocks
 //go:generate mockgen --source user.go -destination mocks/user.go -package mocks
+//go:generate mockgen --source replay.go -destination mocks/replay.go -package mocks
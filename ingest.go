@@ -1,15 +1,39 @@
 package sdump
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"io"
 	"net"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/uptrace/bun"
 )
 
+const (
+	ErrIngestNotFound = appError("ingested request not found")
+)
+
+// hopByHopHeaders are only meaningful for a single connection and must
+// not be forwarded when a captured request is sent elsewhere
+var hopByHopHeaders = []string{
+	"Connection",
+	"Content-Length",
+	"Host",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Proxy-Connection",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
 type RequestDefinition struct {
 	Body      string      `mapstructure:"body" json:"body,omitempty"`
 	Query     string      `json:"query,omitempty"`
@@ -17,6 +41,139 @@ type RequestDefinition struct {
 	IPAddress net.IP      `json:"ip_address,omitempty" bson:"ip_address"`
 	Size      int64       `json:"size,omitempty"`
 	Method    string      `json:"method,omitempty"`
+	// Path is what the request was sent to after the endpoint url, e.g
+	// /stripe/events. It is empty for requests sent to the endpoint itself
+	Path string `json:"path,omitempty"`
+
+	// Encoding is set when the body was sent with a Content-Encoding such as
+	// gzip. Body then holds the decoded body
+	Encoding *BodyEncoding `json:"encoding,omitempty"`
+
+	// Binary is set when the body is not text such as protobuf or msgpack
+	// payloads. Body then holds a JSON view of the payload if it could be
+	// decoded
+	Binary *BinaryBody `json:"binary,omitempty"`
+}
+
+// BodyEncoding keeps the body of a compressed request exactly as it was sent
+type BodyEncoding struct {
+	ContentEncoding string `json:"content_encoding,omitempty"`
+
+	// Original is the base64 encoded body as it was sent
+	Original string `json:"original,omitempty"`
+
+	// DecodedSize is the size of the body after decoding
+	DecodedSize int64 `json:"decoded_size,omitempty"`
+
+	// Error is set when the body could not be decoded. Body is then empty
+	// and only Original is available
+	Error string `json:"error,omitempty"`
+}
+
+// BinaryBody keeps a body that cannot be stored as text
+type BinaryBody struct {
+	// Format is what the body was decoded from such as protobuf, msgpack
+	// or cbor. It is empty if the format is not known
+	Format      string `json:"format,omitempty"`
+	MessageType string `json:"message_type,omitempty"`
+
+	// Original is the base64 encoded body after any Content-Encoding was
+	// reversed
+	Original string `json:"original,omitempty"`
+
+	// Error is set when the body could not be decoded
+	Error string `json:"error,omitempty"`
+}
+
+// IsDecoded reports if Body holds a decoded version of what was sent
+func (r RequestDefinition) IsDecoded() bool {
+	return r.Encoding != nil && r.Encoding.Error == ""
+}
+
+// OriginalBody is the body exactly as it was sent
+func (r RequestDefinition) OriginalBody() ([]byte, error) {
+	if r.Encoding != nil && r.Encoding.Original != "" {
+		return base64.StdEncoding.DecodeString(r.Encoding.Original)
+	}
+
+	if r.Binary != nil && r.Binary.Original != "" {
+		return base64.StdEncoding.DecodeString(r.Binary.Original)
+	}
+
+	return []byte(r.Body), nil
+}
+
+// WithoutEncoding returns the request as if the decoded body was sent
+// without compression. It is useful when the body is going to be edited or
+// shown as text
+func (r RequestDefinition) WithoutEncoding() RequestDefinition {
+	if !r.IsDecoded() {
+		return r
+	}
+
+	r.Headers = r.Headers.Clone()
+	r.Headers.Del("Content-Encoding")
+	r.Encoding = nil
+	r.Size = int64(len(r.Body))
+
+	return r
+}
+
+// HTTPRequest rebuilds the captured request so it can be sent to target.
+// The query string of the captured request is merged into any query
+// already present on target
+func (r RequestDefinition) HTTPRequest(ctx context.Context, target string) (*http.Request, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, err
+	}
+
+	capturedQuery, err := url.ParseQuery(r.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	query := u.Query()
+	for key, values := range capturedQuery {
+		for _, value := range values {
+			query.Add(key, value)
+		}
+	}
+
+	u.RawQuery = query.Encode()
+
+	method := r.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	// compressed requests are sent with the bytes that were captured so
+	// the Content-Encoding header still holds
+	original, err := r.OriginalBody()
+	if err != nil {
+		return nil, err
+	}
+
+	var body io.Reader
+	if len(original) > 0 {
+		body = bytes.NewReader(original)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header = r.Headers.Clone()
+	if req.Header == nil {
+		req.Header = make(http.Header)
+	}
+
+	for _, header := range hopByHopHeaders {
+		req.Header.Del(header)
+	}
+
+	return req, nil
 }
 
 type IngestHTTPRequest struct {
@@ -26,6 +183,12 @@ type IngestHTTPRequest struct {
 
 	// No need to store content type, it will always be application/json
 
+	// Starred, Note and Tags are set by the owner of the endpoint. Annotated
+	// requests are never pruned
+	Starred bool     `bun:",notnull" json:"starred,omitempty"`
+	Note    string   `bun:",notnull" json:"note,omitempty"`
+	Tags    []string `bun:",type:jsonb,nullzero" json:"tags,omitempty"`
+
 	CreatedAt time.Time  `bun:",nullzero,notnull,default:current_timestamp" json:"created_at,omitempty" bson:"created_at" mapstructure:"created_at"`
 	UpdatedAt time.Time  `bun:",nullzero,notnull,default:current_timestamp" json:"updated_at,omitempty" bson:"updated_at" mapstructure:"updated_at"`
 	DeletedAt *time.Time `bun:",soft_delete,nullzero" json:"-,omitempty" bson:"deleted_at" mapstructure:"deleted_at"`
@@ -33,12 +196,129 @@ type IngestHTTPRequest struct {
 	bun.BaseModel `bun:"table:ingests"`
 }
 
+// IsAnnotated reports if the request was starred, noted or tagged
+func (i IngestHTTPRequest) IsAnnotated() bool {
+	return i.Starred || i.Note != "" || len(i.Tags) > 0
+}
+
+// DeleteIngestedRequestOptions prunes captured requests. Annotated requests
+// are always kept
 type DeleteIngestedRequestOptions struct {
 	Before         time.Time
 	UseSoftDeletes bool
 }
 
+// BodyMatch matches captured requests with a JSON body that has Value at
+// Path. e.g Path data.object.id and Value ch_123. Numbers, booleans and
+// null are matched by their JSON text
+type BodyMatch struct {
+	// Path is the list of keys leading to the value. Numeric keys index
+	// into arrays
+	Path  []string
+	Value string
+}
+
+type FindIngestOptions struct {
+	ID uuid.UUID
+}
+
+// ListIngestOptions filters captured requests of an endpoint. Zero values
+// are ignored
+type ListIngestOptions struct {
+	UrlID uuid.UUID
+	// IDs limits the results to a selection of captured requests
+	IDs  []uuid.UUID
+	From time.Time
+	To   time.Time
+	// After only returns the requests that come after it in the order of
+	// the list. It is used to page through history
+	After  *IngestCursor
+	Method string
+	Limit  int
+	// NewestFirst reverses the default oldest first ordering
+	NewestFirst bool
+
+	// Text limits the results to requests whose body or headers contain
+	// every term. Matching is case insensitive
+	Text []string
+	// Body limits the results to requests whose JSON body satisfies every
+	// match
+	Body []BodyMatch
+}
+
+// IngestCursor is the position of a captured request in a list. Requests
+// captured at the same time are ordered by id so none is skipped when a
+// page ends between them
+type IngestCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// IngestStatsOptions selects the captured requests of an endpoint to
+// aggregate
+type IngestStatsOptions struct {
+	UrlID uuid.UUID
+	// Since is the first minute counted in IngestStats.PerMinute. Every other
+	// aggregate covers all the captured requests of the endpoint
+	Since time.Time
+	// TopIPs is how many source ips are returned
+	TopIPs int
+	// TopPaths is how many paths are returned
+	TopPaths int
+}
+
+// IngestStats summarizes the traffic of an endpoint
+type IngestStats struct {
+	Total     int64 `json:"total"`
+	TotalSize int64 `json:"total_size"`
+	MaxSize   int64 `json:"max_size"`
+
+	Methods map[string]int64 `json:"methods"`
+	// TopIPs are the source ips that sent the most requests, busiest first
+	TopIPs []IPCount `json:"top_ips"`
+	// TopPaths are the paths that received the most requests, busiest
+	// first. Requests sent to the endpoint itself are counted as /
+	TopPaths []PathCount `json:"top_paths"`
+	// PerMinute is oldest first. Minutes without requests are left out
+	PerMinute []MinuteCount `json:"per_minute"`
+}
+
+// AverageSize is the average size of the bodies that were sent
+func (s IngestStats) AverageSize() int64 {
+	if s.Total == 0 {
+		return 0
+	}
+
+	return s.TotalSize / s.Total
+}
+
+type IPCount struct {
+	IP    string `json:"ip"`
+	Count int64  `json:"count"`
+}
+
+type PathCount struct {
+	Path  string `json:"path"`
+	Count int64  `json:"count"`
+}
+
+type MinuteCount struct {
+	Minute time.Time `json:"minute"`
+	Count  int64     `json:"count"`
+}
+
 type IngestRepository interface {
 	Create(context.Context, *IngestHTTPRequest) error
+	// CreateMany stores every request or none of them
+	CreateMany(context.Context, []IngestHTTPRequest) error
+	Get(context.Context, *FindIngestOptions) (*IngestHTTPRequest, error)
+	// List returns the captured requests of an endpoint, oldest first unless
+	// NewestFirst is set
+	List(context.Context, *ListIngestOptions) ([]IngestHTTPRequest, error)
 	Delete(context.Context, *DeleteIngestedRequestOptions) error
+	// Annotate saves the star, note and tags of a captured request. The
+	// request itself is never changed
+	Annotate(context.Context, *IngestHTTPRequest) error
+	// Stats aggregates the captured requests of an endpoint
+	Stats(context.Context, *IngestStatsOptions) (*IngestStats, error)
 }
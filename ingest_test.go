@@ -0,0 +1,102 @@
+package sdump
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestDefinition_HTTPRequest(t *testing.T) {
+	def := RequestDefinition{
+		Body:   `{"name": "sdump"}`,
+		Query:  "page=1&sort=desc",
+		Method: http.MethodPut,
+		Headers: http.Header{
+			"Content-Type":   []string{"application/json"},
+			"Content-Length": []string{"17"},
+			"Connection":     []string{"keep-alive"},
+			"X-Github-Event": []string{"push"},
+		},
+	}
+
+	req, err := def.HTTPRequest(context.Background(), "http://localhost:3000/webhooks?source=sdump")
+	require.NoError(t, err)
+
+	require.Equal(t, http.MethodPut, req.Method)
+	require.Equal(t, "localhost:3000", req.URL.Host)
+	require.Equal(t, "/webhooks", req.URL.Path)
+
+	require.Equal(t, "1", req.URL.Query().Get("page"))
+	require.Equal(t, "desc", req.URL.Query().Get("sort"))
+	require.Equal(t, "sdump", req.URL.Query().Get("source"))
+
+	require.Equal(t, "push", req.Header.Get("X-Github-Event"))
+	require.Empty(t, req.Header.Get("Connection"))
+	require.Empty(t, req.Header.Get("Content-Length"))
+
+	body, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	require.Equal(t, def.Body, string(body))
+}
+
+func TestRequestDefinition_HTTPRequest_DefaultsToGet(t *testing.T) {
+	req, err := RequestDefinition{}.HTTPRequest(context.Background(), "http://localhost:3000")
+	require.NoError(t, err)
+
+	require.Equal(t, http.MethodGet, req.Method)
+	require.Nil(t, req.Body)
+}
+
+func TestRequestDefinition_Encoding(t *testing.T) {
+	def := RequestDefinition{
+		Body:   `{"name": "sdump"}`,
+		Method: http.MethodPost,
+		Size:   3,
+		Headers: http.Header{
+			"Content-Encoding": []string{"gzip"},
+			"Content-Type":     []string{"application/json"},
+		},
+		Encoding: &BodyEncoding{
+			ContentEncoding: "gzip",
+			Original:        "AQID",
+			DecodedSize:     17,
+		},
+	}
+
+	require.True(t, def.IsDecoded())
+
+	req, err := def.HTTPRequest(context.Background(), "http://localhost:3000/webhooks")
+	require.NoError(t, err)
+
+	body, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 2, 3}, body)
+	require.Equal(t, "gzip", req.Header.Get("Content-Encoding"))
+
+	decoded := def.WithoutEncoding()
+	require.Nil(t, decoded.Encoding)
+	require.Empty(t, decoded.Headers.Get("Content-Encoding"))
+	require.Equal(t, int64(17), decoded.Size)
+	require.Equal(t, "gzip", def.Headers.Get("Content-Encoding"))
+
+	def.Encoding.Error = "invalid gzip body"
+	require.False(t, def.IsDecoded())
+	require.Equal(t, def, def.WithoutEncoding())
+}
+
+func TestRequestDefinition_OriginalBody_Binary(t *testing.T) {
+	def := RequestDefinition{
+		Body: `{"name": "sdump"}`,
+		Binary: &BinaryBody{
+			Format:   "msgpack",
+			Original: "AQID",
+		},
+	}
+
+	b, err := def.OriginalBody()
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 2, 3}, b)
+}
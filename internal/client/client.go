@@ -0,0 +1,329 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ayinke-llc/sdump"
+)
+
+// fingerprintHeader must match what the http server expects
+const fingerprintHeader = "X-Sdump-Fingerprint"
+
+// Client talks to the authenticated /api routes of the http server on
+// behalf of a single ssh user
+type Client struct {
+	inner       *http.Client
+	domain      string
+	adminSecret string
+	fingerprint string
+}
+
+func New(domain, adminSecret, fingerprint string) *Client {
+	return &Client{
+		inner: &http.Client{
+			Timeout: time.Minute,
+		},
+		domain:      domain,
+		adminSecret: adminSecret,
+		fingerprint: fingerprint,
+	}
+}
+
+// rawBody is uploaded as is with its own content type
+type rawBody struct {
+	io.Reader
+	contentType string
+}
+
+type apiError struct {
+	Message string `json:"message"`
+}
+
+func (c *Client) do(ctx context.Context,
+	method, path string,
+	body any,
+	dst any,
+) error {
+	var r io.Reader
+
+	contentType := "application/json"
+
+	switch v := body.(type) {
+	case nil:
+	case rawBody:
+		r = v.Reader
+		contentType = v.contentType
+	case io.Reader:
+		// raw uploads such as archives are streamed as is
+		r = v
+	default:
+		b := new(bytes.Buffer)
+		if err := json.NewEncoder(b).Encode(body); err != nil {
+			return err
+		}
+
+		r = b
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.domain+path, r)
+	if err != nil {
+		return err
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.adminSecret)
+	req.Header.Set(fingerprintHeader, c.fingerprint)
+
+	resp, err := c.inner.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var e apiError
+		if err := json.NewDecoder(resp.Body).Decode(&e); err != nil || e.Message == "" {
+			return fmt.Errorf("unexpected status code %d from server", resp.StatusCode)
+		}
+
+		return errors.New(e.Message)
+	}
+
+	switch v := dst.(type) {
+	case nil:
+		_, err := io.Copy(io.Discard, resp.Body)
+		return err
+
+	case *[]byte:
+		*v, err = io.ReadAll(resp.Body)
+		return err
+	}
+
+	return json.NewDecoder(resp.Body).Decode(dst)
+}
+
+// Endpoint is a url requests can be sent to
+type Endpoint struct {
+	URL        string
+	Reference  string
+	SSEChannel string
+}
+
+// LatestEndpoint fetches the endpoint the user last created
+func (c *Client) LatestEndpoint(ctx context.Context) (*Endpoint, error) {
+	var response struct {
+		URL struct {
+			Identifier            string `json:"identifier,omitempty"`
+			HumanReadableEndpoint string `json:"human_readable_endpoint,omitempty"`
+		} `json:"url,omitempty"`
+		SSE struct {
+			Channel string `json:"channel,omitempty"`
+		} `json:"sse,omitempty"`
+	}
+
+	if err := c.do(ctx, http.MethodGet, "/api/urls/latest", nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &Endpoint{
+		URL:        response.URL.HumanReadableEndpoint,
+		Reference:  response.URL.Identifier,
+		SSEChannel: response.SSE.Channel,
+	}, nil
+}
+
+type ReplayOptions struct {
+	TargetURL string            `json:"target_url,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+
+	// Request replaces the captured request with an edited variant
+	Request *sdump.RequestDefinition `json:"request,omitempty"`
+}
+
+// Replay re-sends a captured request to the target url
+func (c *Client) Replay(ctx context.Context, ingestID string,
+	opts *ReplayOptions,
+) (*sdump.Replay, error) {
+	var response struct {
+		Replay *sdump.Replay `json:"replay"`
+	}
+
+	err := c.do(ctx, http.MethodPost,
+		fmt.Sprintf("/api/ingests/%s/replays", ingestID), opts, &response)
+	return response.Replay, err
+}
+
+// Replays lists all previous replays of a captured request
+func (c *Client) Replays(ctx context.Context, ingestID string) ([]sdump.Replay, error) {
+	var response struct {
+		Replays []sdump.Replay `json:"replays"`
+	}
+
+	err := c.do(ctx, http.MethodGet,
+		fmt.Sprintf("/api/ingests/%s/replays", ingestID), nil, &response)
+	return response.Replays, err
+}
+
+// IngestHAR exports a single captured request as a HAR document
+func (c *Client) IngestHAR(ctx context.Context, ingestID string) ([]byte, error) {
+	var b []byte
+
+	err := c.do(ctx, http.MethodGet,
+		fmt.Sprintf("/api/ingests/%s/har", ingestID), nil, &b)
+	return b, err
+}
+
+// EndpointHAR exports the captured requests of an endpoint as a HAR document.
+// If ids is empty, every captured request of the endpoint is exported
+func (c *Client) EndpointHAR(ctx context.Context, reference string, ids []string) ([]byte, error) {
+	path := fmt.Sprintf("/api/urls/%s/har", url.PathEscape(reference))
+	if len(ids) > 0 {
+		path += "?ids=" + url.QueryEscape(strings.Join(ids, ","))
+	}
+
+	var b []byte
+
+	err := c.do(ctx, http.MethodGet, path, nil, &b)
+	return b, err
+}
+
+// Import uploads a HAR file or an NDJSON dump of captured requests into an
+// endpoint and returns how many requests were imported. Stars, notes and
+// tags found in the archive are dropped unless keepAnnotations is set
+func (c *Client) Import(ctx context.Context, reference string,
+	archive io.Reader, keepAnnotations bool,
+) (int, error) {
+	var response struct {
+		Imported int `json:"imported"`
+	}
+
+	path := fmt.Sprintf("/api/urls/%s/imports", url.PathEscape(reference))
+	if keepAnnotations {
+		path += "?keep_annotations=true"
+	}
+
+	err := c.do(ctx, http.MethodPost, path, archive, &response)
+	return response.Imported, err
+}
+
+// SearchResult is a page of captured requests matching a filter query
+type SearchResult struct {
+	Ingests []sdump.IngestHTTPRequest `json:"ingests"`
+	// Cursor is set when older matches might exist. Pass it back to fetch
+	// the next page
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// SearchIngests returns the captured requests of an endpoint that match a
+// filter query, newest first. An empty cursor starts from the latest request
+func (c *Client) SearchIngests(ctx context.Context, reference, query string,
+	cursor string, limit int,
+) (*SearchResult, error) {
+	params := url.Values{}
+	params.Set("q", query)
+
+	if cursor != "" {
+		params.Set("cursor", cursor)
+	}
+
+	if limit > 0 {
+		params.Set("limit", fmt.Sprintf("%d", limit))
+	}
+
+	result := new(SearchResult)
+
+	err := c.do(ctx, http.MethodGet,
+		fmt.Sprintf("/api/urls/%s/ingests?%s", url.PathEscape(reference), params.Encode()), nil, result)
+	return result, err
+}
+
+// UploadDescriptorSet stores a protobuf FileDescriptorSet used to decode
+// protobuf payloads sent to an endpoint. defaultMessage is used for payloads
+// that do not specify their message type and can be empty. The messages of
+// the descriptor set are returned
+func (c *Client) UploadDescriptorSet(ctx context.Context, reference, defaultMessage string,
+	descriptorSet io.Reader,
+) ([]string, error) {
+	path := fmt.Sprintf("/api/urls/%s/protobuf", url.PathEscape(reference))
+	if defaultMessage != "" {
+		path += "?message=" + url.QueryEscape(defaultMessage)
+	}
+
+	var response struct {
+		Messages []string `json:"messages"`
+	}
+
+	err := c.do(ctx, http.MethodPut, path, rawBody{
+		Reader:      descriptorSet,
+		contentType: "application/octet-stream",
+	}, &response)
+	return response.Messages, err
+}
+
+type Annotation struct {
+	Starred bool     `json:"starred"`
+	Note    string   `json:"note"`
+	Tags    []string `json:"tags"`
+}
+
+// Annotate replaces the star, note and tags of a captured request. Annotated
+// requests are kept when old requests are pruned
+func (c *Client) Annotate(ctx context.Context, ingestID string,
+	annotation *Annotation,
+) (*sdump.IngestHTTPRequest, error) {
+	var response struct {
+		Ingest *sdump.IngestHTTPRequest `json:"ingest"`
+	}
+
+	err := c.do(ctx, http.MethodPut,
+		fmt.Sprintf("/api/ingests/%s/annotation", ingestID), annotation, &response)
+	return response.Ingest, err
+}
+
+// Preferences fetches the TUI preferences of the user. Fields that were never
+// saved are empty
+func (c *Client) Preferences(ctx context.Context) (*sdump.Preferences, error) {
+	var response struct {
+		Preferences *sdump.Preferences `json:"preferences"`
+	}
+
+	err := c.do(ctx, http.MethodGet, "/api/preferences", nil, &response)
+	return response.Preferences, err
+}
+
+// SavePreferences replaces the TUI preferences of the user
+func (c *Client) SavePreferences(ctx context.Context,
+	preferences *sdump.Preferences,
+) (*sdump.Preferences, error) {
+	var response struct {
+		Preferences *sdump.Preferences `json:"preferences"`
+	}
+
+	err := c.do(ctx, http.MethodPut, "/api/preferences", preferences, &response)
+	return response.Preferences, err
+}
+
+// Stats summarizes the traffic of an endpoint. The requests per minute cover
+// the last minutes
+func (c *Client) Stats(ctx context.Context, reference string, minutes int) (*sdump.IngestStats, error) {
+	var response struct {
+		Stats *sdump.IngestStats `json:"stats"`
+	}
+
+	err := c.do(ctx, http.MethodGet,
+		fmt.Sprintf("/api/urls/%s/stats?minutes=%d", url.PathEscape(reference), minutes), nil, &response)
+	return response.Stats, err
+}
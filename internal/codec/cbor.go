@@ -0,0 +1,303 @@
+package codec
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// cborMaxDepth limits how deeply nested arrays and maps can be so crafted
+// payloads cannot exhaust the stack
+const cborMaxDepth = 256
+
+const cborBreak = 0xff
+
+var errCBORUnexpectedEnd = errors.New("unexpected end of cbor body")
+
+func cborToJSON(body []byte) ([]byte, error) {
+	d := &cborDecoder{b: body}
+
+	v, err := d.value(0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cbor body: %w", err)
+	}
+
+	if d.pos != len(d.b) {
+		return nil, errors.New("invalid cbor body: unexpected data after the first item")
+	}
+
+	return json.Marshal(v)
+}
+
+// cborDecoder decodes the data model of RFC 8949 into values that can be
+// encoded as JSON
+type cborDecoder struct {
+	b   []byte
+	pos int
+}
+
+func (d *cborDecoder) next(n uint64) ([]byte, error) {
+	if n > uint64(len(d.b)-d.pos) {
+		return nil, errCBORUnexpectedEnd
+	}
+
+	b := d.b[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+	return b, nil
+}
+
+// head reads the initial byte of an item and its argument. indefinite is
+// set for items with an indefinite length
+func (d *cborDecoder) head() (major byte, info byte, arg uint64, indefinite bool, err error) {
+	b, err := d.next(1)
+	if err != nil {
+		return 0, 0, 0, false, err
+	}
+
+	major, info = b[0]>>5, b[0]&0x1f
+
+	switch {
+	case info < 24:
+		return major, info, uint64(info), false, nil
+
+	case info == 24:
+		b, err = d.next(1)
+		if err != nil {
+			return 0, 0, 0, false, err
+		}
+
+		return major, info, uint64(b[0]), false, nil
+
+	case info == 25:
+		b, err = d.next(2)
+		if err != nil {
+			return 0, 0, 0, false, err
+		}
+
+		return major, info, uint64(binary.BigEndian.Uint16(b)), false, nil
+
+	case info == 26:
+		b, err = d.next(4)
+		if err != nil {
+			return 0, 0, 0, false, err
+		}
+
+		return major, info, uint64(binary.BigEndian.Uint32(b)), false, nil
+
+	case info == 27:
+		b, err = d.next(8)
+		if err != nil {
+			return 0, 0, 0, false, err
+		}
+
+		return major, info, binary.BigEndian.Uint64(b), false, nil
+
+	case info == 31 && major >= 2 && major != 6:
+		return major, info, 0, true, nil
+	}
+
+	return 0, 0, 0, false, fmt.Errorf("invalid additional information %d", info)
+}
+
+func (d *cborDecoder) isBreak() bool {
+	if d.pos < len(d.b) && d.b[d.pos] == cborBreak {
+		d.pos++
+		return true
+	}
+
+	return false
+}
+
+func (d *cborDecoder) value(depth int) (any, error) {
+	if depth > cborMaxDepth {
+		return nil, errors.New("cbor body is nested too deeply")
+	}
+
+	major, info, arg, indefinite, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+
+	switch major {
+	case 0:
+		return arg, nil
+
+	case 1:
+		if arg > math.MaxInt64 {
+			n := new(big.Int).SetUint64(arg)
+			return n.Neg(n).Sub(n, big.NewInt(1)), nil
+		}
+
+		return -1 - int64(arg), nil
+
+	case 2, 3:
+		b, err := d.bytes(major, arg, indefinite)
+		if err != nil {
+			return nil, err
+		}
+
+		if major == 3 {
+			return string(b), nil
+		}
+
+		return b, nil
+
+	case 4:
+		var items []any
+
+		for i := uint64(0); indefinite || i < arg; i++ {
+			if indefinite && d.isBreak() {
+				break
+			}
+
+			item, err := d.value(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+
+			items = append(items, item)
+		}
+
+		if items == nil {
+			items = []any{}
+		}
+
+		return items, nil
+
+	case 5:
+		m := make(map[string]any)
+
+		for i := uint64(0); indefinite || i < arg; i++ {
+			if indefinite && d.isBreak() {
+				break
+			}
+
+			key, err := d.value(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+
+			item, err := d.value(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+
+			m[mapKey(key)] = item
+		}
+
+		return m, nil
+
+	case 6:
+		item, err := d.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+
+		// bignums are the only tags that change how a value is read. Every
+		// other tag such as dates is shown as the value it wraps
+		if b, ok := item.([]byte); ok && (arg == 2 || arg == 3) {
+			n := new(big.Int).SetBytes(b)
+			if arg == 3 {
+				n.Neg(n).Sub(n, big.NewInt(1))
+			}
+
+			return n, nil
+		}
+
+		return item, nil
+	}
+
+	return d.simple(info, arg)
+}
+
+// bytes reads a byte or text string. Indefinite strings are made of chunks
+// of the same type
+func (d *cborDecoder) bytes(major byte, arg uint64, indefinite bool) ([]byte, error) {
+	if !indefinite {
+		return d.next(arg)
+	}
+
+	var b []byte
+
+	for !d.isBreak() {
+		chunkMajor, _, n, chunkIndefinite, err := d.head()
+		if err != nil {
+			return nil, err
+		}
+
+		if chunkMajor != major || chunkIndefinite {
+			return nil, errors.New("invalid chunk in indefinite length string")
+		}
+
+		chunk, err := d.next(n)
+		if err != nil {
+			return nil, err
+		}
+
+		b = append(b, chunk...)
+	}
+
+	return b, nil
+}
+
+func (d *cborDecoder) simple(info byte, arg uint64) (any, error) {
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23:
+		return nil, nil
+	case 25:
+		return cborFloat(float64(halfToFloat32(uint16(arg)))), nil
+	case 26:
+		return cborFloat(float64(math.Float32frombits(uint32(arg)))), nil
+	case 27:
+		return cborFloat(math.Float64frombits(arg)), nil
+	case 31:
+		return nil, errors.New("unexpected break")
+	}
+
+	// unassigned simple values have no meaning in JSON so their number is
+	// shown instead
+	return arg, nil
+}
+
+// cborFloat keeps values JSON cannot represent as strings
+func cborFloat(f float64) any {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	}
+
+	return f
+}
+
+func halfToFloat32(h uint16) float32 {
+	sign := uint32(h>>15) << 31
+	exp := uint32(h>>10) & 0x1f
+	frac := uint32(h) & 0x3ff
+
+	switch exp {
+	case 0:
+		// subnormal numbers
+		f := float32(frac) / (1 << 24)
+		if sign != 0 {
+			return -f
+		}
+
+		return f
+
+	case 0x1f:
+		return math.Float32frombits(sign | 0xff<<23 | frac<<13)
+	}
+
+	return math.Float32frombits(sign | (exp+127-15)<<23 | frac<<13)
+}
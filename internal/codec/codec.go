@@ -0,0 +1,100 @@
+// Package codec turns binary request bodies such as protobuf, MessagePack
+// and CBOR payloads into JSON so they can be inspected and searched
+package codec
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// Format is a binary format a body can be decoded from
+type Format string
+
+const (
+	Protobuf    Format = "protobuf"
+	MessagePack Format = "msgpack"
+	CBOR        Format = "cbor"
+)
+
+// MessageTypeHeader can be sent with protobuf payloads to pick the message
+// the body should be decoded as
+const MessageTypeHeader = "X-Sdump-Message-Type"
+
+var ErrNoDescriptorSet = errors.New("no protobuf descriptor set was uploaded for this endpoint")
+
+// Detect picks the format of the body from its Content-Type. For protobuf
+// payloads, the message type is read from the proto or messageType
+// parameter of the Content-Type or the X-Sdump-Message-Type header
+func Detect(h http.Header) (Format, string) {
+	mediaType, params, _ := mime.ParseMediaType(h.Get("Content-Type"))
+
+	switch mediaType {
+	case "application/x-protobuf", "application/protobuf",
+		"application/vnd.google.protobuf", "application/x-google-protobuf":
+
+		messageType := strings.TrimSpace(h.Get(MessageTypeHeader))
+
+		for _, name := range []string{"proto", "messagetype"} {
+			if messageType == "" {
+				messageType = params[name]
+			}
+		}
+
+		return Protobuf, messageType
+
+	case "application/msgpack", "application/x-msgpack", "application/vnd.msgpack":
+		return MessagePack, ""
+
+	case "application/cbor":
+		return CBOR, ""
+	}
+
+	if messageType := strings.TrimSpace(h.Get(MessageTypeHeader)); messageType != "" {
+		return Protobuf, messageType
+	}
+
+	return "", ""
+}
+
+// ToJSON decodes the body into JSON. registry holds the protobuf messages of
+// the endpoint and can be nil. The message type that was used is returned
+// for protobuf payloads
+func ToJSON(format Format, messageType string, body []byte,
+	registry *Registry,
+) ([]byte, string, error) {
+	switch format {
+	case Protobuf:
+		if registry == nil {
+			return nil, messageType, ErrNoDescriptorSet
+		}
+
+		return registry.ToJSON(messageType, body)
+
+	case MessagePack:
+		b, err := msgpackToJSON(body)
+		return b, "", err
+
+	case CBOR:
+		b, err := cborToJSON(body)
+		return b, "", err
+	}
+
+	return nil, "", fmt.Errorf("unsupported format %q", format)
+}
+
+// mapKey converts a map key of any type to a JSON object key. Binary keys
+// are hex encoded
+func mapKey(key any) string {
+	switch k := key.(type) {
+	case string:
+		return k
+	case []byte:
+		return strings.ToUpper(hex.EncodeToString(k))
+	}
+
+	return fmt.Sprint(key)
+}
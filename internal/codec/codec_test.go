@@ -0,0 +1,210 @@
+package codec
+
+import (
+	"encoding/hex"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func TestDetect(t *testing.T) {
+	tt := []struct {
+		name        string
+		headers     http.Header
+		format      Format
+		messageType string
+	}{
+		{name: "json", headers: http.Header{"Content-Type": []string{"application/json"}}},
+		{name: "msgpack", headers: http.Header{"Content-Type": []string{"application/msgpack"}}, format: MessagePack},
+		{name: "cbor", headers: http.Header{"Content-Type": []string{"application/cbor"}}, format: CBOR},
+		{name: "protobuf", headers: http.Header{"Content-Type": []string{"application/x-protobuf"}}, format: Protobuf},
+		{
+			name:        "protobuf with message type parameter",
+			headers:     http.Header{"Content-Type": []string{"application/x-protobuf; proto=sdump.Event"}},
+			format:      Protobuf,
+			messageType: "sdump.Event",
+		},
+		{
+			name: "message type header takes precedence",
+			headers: http.Header{
+				"Content-Type":         []string{"application/protobuf; messageType=sdump.Other"},
+				"X-Sdump-Message-Type": []string{"sdump.Event"},
+			},
+			format:      Protobuf,
+			messageType: "sdump.Event",
+		},
+		{
+			name: "message type header without content type",
+			headers: http.Header{
+				"Content-Type":         []string{"application/octet-stream"},
+				"X-Sdump-Message-Type": []string{"sdump.Event"},
+			},
+			format:      Protobuf,
+			messageType: "sdump.Event",
+		},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			format, messageType := Detect(v.headers)
+			require.Equal(t, v.format, format)
+			require.Equal(t, v.messageType, messageType)
+		})
+	}
+}
+
+func TestToJSON_CBOR(t *testing.T) {
+	// see appendix A of RFC 8949
+	tt := []struct {
+		hex      string
+		expected string
+	}{
+		{hex: "00", expected: `0`},
+		{hex: "1bffffffffffffffff", expected: `18446744073709551615`},
+		{hex: "3863", expected: `-100`},
+		{hex: "c249010000000000000000", expected: `18446744073709551616`},
+		{hex: "f93e00", expected: `1.5`},
+		{hex: "fa47c35000", expected: `100000`},
+		{hex: "fb3ff199999999999a", expected: `1.1`},
+		{hex: "f97c00", expected: `"Infinity"`},
+		{hex: "f4", expected: `false`},
+		{hex: "f6", expected: `null`},
+		{hex: "c074323031332d30332d32315432303a30343a30305a", expected: `"2013-03-21T20:04:00Z"`},
+		{hex: "6449455446", expected: `"IETF"`},
+		{hex: "4401020304", expected: `"AQIDBA=="`},
+		{hex: "83010203", expected: `[1,2,3]`},
+		{hex: "a201020304", expected: `{"1":2,"3":4}`},
+		{hex: "a26161016162820203", expected: `{"a":1,"b":[2,3]}`},
+		{hex: "7f657374726561646d696e67ff", expected: `"streaming"`},
+		{hex: "9f018202039f0405ffff", expected: `[1,[2,3],[4,5]]`},
+		{hex: "bf61610161629f0203ffff", expected: `{"a":1,"b":[2,3]}`},
+	}
+
+	for _, v := range tt {
+		t.Run(v.hex, func(t *testing.T) {
+			body, err := hex.DecodeString(v.hex)
+			require.NoError(t, err)
+
+			b, _, err := ToJSON(CBOR, "", body, nil)
+			require.NoError(t, err)
+			require.JSONEq(t, v.expected, string(b))
+		})
+	}
+}
+
+func TestToJSON_CBOR_Invalid(t *testing.T) {
+	for _, v := range []string{"", "830102", "1c", "ff", "0000", "5f01ff"} {
+		t.Run(v, func(t *testing.T) {
+			body, err := hex.DecodeString(v)
+			require.NoError(t, err)
+
+			_, _, err = ToJSON(CBOR, "", body, nil)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestToJSON_MessagePack(t *testing.T) {
+	body, err := msgpack.Marshal(map[string]any{
+		"event": "push",
+		"ids":   []int{1, 2},
+		"nested": map[int]string{
+			1: "one",
+		},
+	})
+	require.NoError(t, err)
+
+	b, _, err := ToJSON(MessagePack, "", body, nil)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"event":"push","ids":[1,2],"nested":{"1":"one"}}`, string(b))
+
+	_, _, err = ToJSON(MessagePack, "", []byte{0xc1}, nil)
+	require.Error(t, err)
+}
+
+func descriptorSet(t *testing.T) []byte {
+	t.Helper()
+
+	set := &descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{
+			{
+				Name:    proto.String("event.proto"),
+				Package: proto.String("sdump"),
+				Syntax:  proto.String("proto3"),
+				MessageType: []*descriptorpb.DescriptorProto{
+					{
+						Name: proto.String("Event"),
+						Field: []*descriptorpb.FieldDescriptorProto{
+							{
+								Name:     proto.String("name"),
+								JsonName: proto.String("name"),
+								Number:   proto.Int32(1),
+								Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+								Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+							},
+							{
+								Name:     proto.String("attempts"),
+								JsonName: proto.String("attempts"),
+								Number:   proto.Int32(2),
+								Type:     descriptorpb.FieldDescriptorProto_TYPE_INT32.Enum(),
+								Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	b, err := proto.Marshal(set)
+	require.NoError(t, err)
+
+	return b
+}
+
+func TestRegistry(t *testing.T) {
+	_, err := NewRegistry([]byte("not a descriptor set"), "")
+	require.Error(t, err)
+
+	_, err = NewRegistry(descriptorSet(t), "sdump.Missing")
+	require.Error(t, err)
+
+	registry, err := NewRegistry(descriptorSet(t), "")
+	require.NoError(t, err)
+	require.Equal(t, []string{"sdump.Event"}, registry.Messages())
+
+	md, err := registry.message("sdump.Event")
+	require.NoError(t, err)
+
+	msg := dynamicpb.NewMessage(md)
+	msg.Set(md.Fields().ByName("name"), protoreflect.ValueOfString("push"))
+	msg.Set(md.Fields().ByName("attempts"), protoreflect.ValueOfInt32(3))
+
+	body, err := proto.Marshal(msg)
+	require.NoError(t, err)
+
+	_, _, err = ToJSON(Protobuf, "sdump.Event", body, nil)
+	require.ErrorIs(t, err, ErrNoDescriptorSet)
+
+	_, _, err = ToJSON(Protobuf, "", body, registry)
+	require.Error(t, err)
+
+	b, messageType, err := ToJSON(Protobuf, "sdump.Event", body, registry)
+	require.NoError(t, err)
+	require.Equal(t, "sdump.Event", messageType)
+	require.JSONEq(t, `{"name":"push","attempts":3}`, string(b))
+
+	registry, err = NewRegistry(descriptorSet(t), "sdump.Event")
+	require.NoError(t, err)
+
+	b, messageType, err = ToJSON(Protobuf, "", body, registry)
+	require.NoError(t, err)
+	require.Equal(t, "sdump.Event", messageType)
+	require.JSONEq(t, `{"name":"push","attempts":3}`, string(b))
+}
@@ -0,0 +1,47 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func msgpackToJSON(body []byte) ([]byte, error) {
+	decoder := msgpack.NewDecoder(bytes.NewReader(body))
+
+	// maps can have keys of any type so they are converted to strings like
+	// JSON expects
+	decoder.SetMapDecoder(func(d *msgpack.Decoder) (any, error) {
+		n, err := d.DecodeMapLen()
+		if err != nil || n == -1 {
+			return nil, err
+		}
+
+		m := make(map[string]any)
+
+		for i := 0; i < n; i++ {
+			key, err := d.DecodeInterface()
+			if err != nil {
+				return nil, err
+			}
+
+			value, err := d.DecodeInterface()
+			if err != nil {
+				return nil, err
+			}
+
+			m[mapKey(key)] = value
+		}
+
+		return m, nil
+	})
+
+	v, err := decoder.DecodeInterface()
+	if err != nil {
+		return nil, fmt.Errorf("invalid msgpack body: %w", err)
+	}
+
+	return json.Marshal(v)
+}
@@ -0,0 +1,128 @@
+package codec
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// Registry holds the protobuf messages of a FileDescriptorSet as produced
+// by protoc --descriptor_set_out --include_imports
+type Registry struct {
+	files          *protoregistry.Files
+	types          *dynamicpb.Types
+	defaultMessage string
+}
+
+// NewRegistry parses a serialized FileDescriptorSet. defaultMessage is used
+// for payloads that do not specify their message type and can be empty
+func NewRegistry(descriptorSet []byte, defaultMessage string) (*Registry, error) {
+	set := new(descriptorpb.FileDescriptorSet)
+	// the errors of the protobuf package are randomized on purpose so they
+	// are not passed on to users
+	if err := proto.Unmarshal(descriptorSet, set); err != nil {
+		return nil, errors.New("invalid descriptor set: it is not a serialized FileDescriptorSet")
+	}
+
+	if len(set.GetFile()) == 0 {
+		return nil, errors.New("descriptor set does not contain any file")
+	}
+
+	files, err := protodesc.NewFiles(set)
+	if err != nil {
+		return nil, fmt.Errorf("invalid descriptor set: %w", err)
+	}
+
+	r := &Registry{
+		files: files,
+		types: dynamicpb.NewTypes(files),
+	}
+
+	if defaultMessage != "" {
+		if _, err := r.message(defaultMessage); err != nil {
+			return nil, err
+		}
+
+		r.defaultMessage = defaultMessage
+	}
+
+	return r, nil
+}
+
+// Messages lists the full names of every message in the registry
+func (r *Registry) Messages() []string {
+	var names []string
+
+	r.files.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		names = appendMessages(names, fd.Messages())
+		return true
+	})
+
+	sort.Strings(names)
+
+	return names
+}
+
+func appendMessages(names []string, messages protoreflect.MessageDescriptors) []string {
+	for i := 0; i < messages.Len(); i++ {
+		md := messages.Get(i)
+
+		// map entries are generated by protoc and never sent on their own
+		if md.IsMapEntry() {
+			continue
+		}
+
+		names = append(names, string(md.FullName()))
+		names = appendMessages(names, md.Messages())
+	}
+
+	return names
+}
+
+func (r *Registry) message(name string) (protoreflect.MessageDescriptor, error) {
+	d, err := r.files.FindDescriptorByName(protoreflect.FullName(name))
+	if err != nil {
+		return nil, fmt.Errorf("message %s does not exist in the descriptor set", name)
+	}
+
+	md, ok := d.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a message", name)
+	}
+
+	return md, nil
+}
+
+// ToJSON decodes a payload as the given message type. The default message of
+// the registry is used if messageType is empty
+func (r *Registry) ToJSON(messageType string, body []byte) ([]byte, string, error) {
+	if messageType == "" {
+		messageType = r.defaultMessage
+	}
+
+	if messageType == "" {
+		return nil, "", fmt.Errorf("please send the message type in the %s header", MessageTypeHeader)
+	}
+
+	md, err := r.message(messageType)
+	if err != nil {
+		return nil, messageType, err
+	}
+
+	msg := dynamicpb.NewMessage(md)
+
+	if err := (proto.UnmarshalOptions{Resolver: r.types}).Unmarshal(body, msg); err != nil {
+		return nil, messageType, fmt.Errorf("could not decode body as %s: %w", messageType, err)
+	}
+
+	b, err := protojson.MarshalOptions{Resolver: r.types}.Marshal(msg)
+	return b, messageType, err
+}
@@ -0,0 +1,119 @@
+// Package decompress reverses the Content-Encoding of captured request
+// bodies
+package decompress
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+var ErrTooLarge = errors.New("decoded body is larger than the allowed size")
+
+// UnsupportedEncodingError is returned for encodings that cannot be decoded
+type UnsupportedEncodingError struct {
+	Encoding string
+}
+
+func (u UnsupportedEncodingError) Error() string {
+	return fmt.Sprintf("unsupported content encoding %q", u.Encoding)
+}
+
+// IsEncoded reports if the Content-Encoding header requires the body to be
+// decoded
+func IsEncoded(contentEncoding string) bool {
+	for _, encoding := range encodings(contentEncoding) {
+		if encoding != "identity" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Decode reverses every encoding listed in the Content-Encoding header.
+// Encodings are listed in the order they were applied so they are removed in
+// reverse. Decoding stops with ErrTooLarge once the body grows past limit
+func Decode(contentEncoding string, body []byte, limit int64) ([]byte, error) {
+	list := encodings(contentEncoding)
+
+	for i := len(list) - 1; i >= 0; i-- {
+		var err error
+
+		body, err = decode(list[i], body, limit)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return body, nil
+}
+
+func encodings(contentEncoding string) []string {
+	var list []string
+
+	for _, encoding := range strings.Split(contentEncoding, ",") {
+		encoding = strings.ToLower(strings.TrimSpace(encoding))
+		if encoding != "" {
+			list = append(list, encoding)
+		}
+	}
+
+	return list
+}
+
+func decode(encoding string, body []byte, limit int64) ([]byte, error) {
+	var r io.Reader
+
+	switch encoding {
+	case "identity":
+		return body, nil
+
+	case "gzip", "x-gzip":
+		gr, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip body: %w", err)
+		}
+
+		defer gr.Close()
+		r = gr
+
+	case "br":
+		r = brotli.NewReader(bytes.NewReader(body))
+
+	case "deflate":
+		// deflate is meant to be zlib wrapped but a lot of clients send raw
+		// deflate streams
+		zr, err := zlib.NewReader(bytes.NewReader(body))
+		if err != nil {
+			fr := flate.NewReader(bytes.NewReader(body))
+			defer fr.Close()
+			r = fr
+			break
+		}
+
+		defer zr.Close()
+		r = zr
+
+	default:
+		return nil, UnsupportedEncodingError{Encoding: encoding}
+	}
+
+	decoded, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s body: %w", encoding, err)
+	}
+
+	if int64(len(decoded)) > limit {
+		return nil, ErrTooLarge
+	}
+
+	return decoded, nil
+}
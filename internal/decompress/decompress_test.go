@@ -0,0 +1,92 @@
+package decompress
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/stretchr/testify/require"
+)
+
+const body = `{"event": "push", "repository": "ayinke-llc/sdump"}`
+
+func compress(t *testing.T, encoding string, b []byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	var w io.WriteCloser
+
+	switch encoding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "br":
+		w = brotli.NewWriter(&buf)
+	case "deflate":
+		w = zlib.NewWriter(&buf)
+	case "raw-deflate":
+		fw, err := flate.NewWriter(&buf, flate.DefaultCompression)
+		require.NoError(t, err)
+		w = fw
+	}
+
+	_, err := w.Write(b)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	return buf.Bytes()
+}
+
+func TestDecode(t *testing.T) {
+	tt := []struct {
+		name            string
+		contentEncoding string
+		body            []byte
+	}{
+		{name: "gzip", contentEncoding: "gzip", body: compress(t, "gzip", []byte(body))},
+		{name: "x-gzip", contentEncoding: "X-Gzip", body: compress(t, "gzip", []byte(body))},
+		{name: "brotli", contentEncoding: "br", body: compress(t, "br", []byte(body))},
+		{name: "deflate", contentEncoding: "deflate", body: compress(t, "deflate", []byte(body))},
+		{name: "raw deflate", contentEncoding: "deflate", body: compress(t, "raw-deflate", []byte(body))},
+		{name: "identity", contentEncoding: "identity", body: []byte(body)},
+		{
+			name:            "multiple encodings",
+			contentEncoding: "deflate, gzip",
+			body:            compress(t, "gzip", compress(t, "deflate", []byte(body))),
+		},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			decoded, err := Decode(v.contentEncoding, v.body, 1024)
+			require.NoError(t, err)
+			require.Equal(t, body, string(decoded))
+		})
+	}
+}
+
+func TestDecode_TooLarge(t *testing.T) {
+	bomb := compress(t, "gzip", []byte(strings.Repeat("a", 1024*1024)))
+
+	_, err := Decode("gzip", bomb, 1024)
+	require.ErrorIs(t, err, ErrTooLarge)
+}
+
+func TestDecode_Invalid(t *testing.T) {
+	_, err := Decode("gzip", []byte(body), 1024)
+	require.Error(t, err)
+
+	_, err = Decode("compress", []byte(body), 1024)
+	require.ErrorAs(t, err, &UnsupportedEncodingError{})
+}
+
+func TestIsEncoded(t *testing.T) {
+	require.False(t, IsEncoded(""))
+	require.False(t, IsEncoded("identity"))
+	require.True(t, IsEncoded("gzip"))
+	require.True(t, IsEncoded("identity, br"))
+}
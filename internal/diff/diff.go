@@ -0,0 +1,396 @@
+// Package diff compares two captured requests. Headers and query parameters
+// are compared by name, JSON bodies structurally so the order of keys does
+// not matter and any other body line by line
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/ayinke-llc/sdump"
+	"github.com/ayinke-llc/sdump/internal/inspect"
+)
+
+// maxTextLines bounds the line diff since it needs memory proportional to
+// the product of the line counts of both bodies
+const maxTextLines = 2000
+
+type ChangeKind string
+
+const (
+	Added   ChangeKind = "added"
+	Removed ChangeKind = "removed"
+	Changed ChangeKind = "changed"
+)
+
+// Change is a header, query parameter or JSON value that differs. Name is
+// the JSONPath of the value for JSON bodies
+type Change struct {
+	Kind ChangeKind `json:"kind"`
+	Name string     `json:"name"`
+	Old  string     `json:"old,omitempty"`
+	New  string     `json:"new,omitempty"`
+}
+
+type BodyFormat string
+
+const (
+	BodyJSON BodyFormat = "json"
+	BodyText BodyFormat = "text"
+)
+
+// LineOp is how a line of a text diff changed
+type LineOp string
+
+const (
+	LineEqual   LineOp = " "
+	LineAdded   LineOp = "+"
+	LineRemoved LineOp = "-"
+)
+
+type Line struct {
+	Op   LineOp `json:"op"`
+	Text string `json:"text"`
+}
+
+type Body struct {
+	Format BodyFormat `json:"format"`
+	Equal  bool       `json:"equal"`
+	// Changes is set for JSON bodies
+	Changes []Change `json:"changes,omitempty"`
+	// Lines is set for text bodies
+	Lines []Line `json:"lines,omitempty"`
+	// Truncated is set when the bodies were too large for a line diff. Lines
+	// then only lists the first line that differs
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// Result describes how the second request differs from the first
+type Result struct {
+	Method  *Change  `json:"method,omitempty"`
+	Headers []Change `json:"headers"`
+	Query   []Change `json:"query"`
+	Body    Body     `json:"body"`
+}
+
+// Equal reports if nothing differs
+func (r *Result) Equal() bool {
+	return r.Method == nil && len(r.Headers) == 0 && len(r.Query) == 0 && r.Body.Equal
+}
+
+// Requests compares two requests
+func Requests(a, b sdump.RequestDefinition) (*Result, error) {
+	query, err := Query(a.Query, b.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		Headers: Headers(a.Headers, b.Headers),
+		Query:   query,
+		Body:    Bodies(a.Body, b.Body),
+	}
+
+	if !strings.EqualFold(a.Method, b.Method) {
+		result.Method = &Change{Kind: Changed, Name: "method", Old: a.Method, New: b.Method}
+	}
+
+	return result, nil
+}
+
+// Headers compares headers by their canonical name. Headers sent more than
+// once are compared with all of their values
+func Headers(a, b http.Header) []Change {
+	return compareValues(canonical(a), canonical(b))
+}
+
+func canonical(h http.Header) map[string][]string {
+	values := make(map[string][]string, len(h))
+	for name, v := range h {
+		key := http.CanonicalHeaderKey(name)
+		values[key] = append(values[key], v...)
+	}
+
+	return values
+}
+
+// Query compares two raw query strings
+func Query(a, b string) ([]Change, error) {
+	first, err := url.ParseQuery(a)
+	if err != nil {
+		return nil, err
+	}
+
+	second, err := url.ParseQuery(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return compareValues(first, second), nil
+}
+
+func compareValues(a, b map[string][]string) []Change {
+	names := make(map[string]struct{}, len(a)+len(b))
+	for name := range a {
+		names[name] = struct{}{}
+	}
+
+	for name := range b {
+		names[name] = struct{}{}
+	}
+
+	changes := []Change{}
+
+	for name := range names {
+		old, inFirst := a[name]
+		current, inSecond := b[name]
+
+		switch {
+		case !inFirst:
+			changes = append(changes, Change{Kind: Added, Name: name, New: strings.Join(current, ", ")})
+
+		case !inSecond:
+			changes = append(changes, Change{Kind: Removed, Name: name, Old: strings.Join(old, ", ")})
+
+		case strings.Join(old, "\x00") != strings.Join(current, "\x00"):
+			changes = append(changes, Change{
+				Kind: Changed,
+				Name: name,
+				Old:  strings.Join(old, ", "),
+				New:  strings.Join(current, ", "),
+			})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Name < changes[j].Name
+	})
+
+	return changes
+}
+
+// Bodies compares JSON bodies structurally. If either body is not JSON, they
+// are compared line by line
+func Bodies(a, b string) Body {
+	first, firstErr := decodeJSON(a)
+	second, secondErr := decodeJSON(b)
+
+	if firstErr != nil || secondErr != nil {
+		return Text(a, b)
+	}
+
+	changes := []Change{}
+	compareJSON("$", first, second, &changes)
+
+	return Body{
+		Format:  BodyJSON,
+		Equal:   len(changes) == 0,
+		Changes: changes,
+	}
+}
+
+func decodeJSON(s string) (any, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, fmt.Errorf("empty body")
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(s))
+	decoder.UseNumber()
+
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	if decoder.More() {
+		return nil, fmt.Errorf("unexpected data after the JSON document")
+	}
+
+	return v, nil
+}
+
+func compareJSON(path string, a, b any, changes *[]Change) {
+	switch first := a.(type) {
+	case map[string]any:
+		second, ok := b.(map[string]any)
+		if !ok {
+			break
+		}
+
+		keys := make([]string, 0, len(first)+len(second))
+		for key := range first {
+			keys = append(keys, key)
+		}
+
+		for key := range second {
+			if _, ok := first[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			old, inFirst := first[key]
+			current, inSecond := second[key]
+			keyPath := path + inspect.PathKey(key)
+
+			switch {
+			case !inFirst:
+				*changes = append(*changes, Change{Kind: Added, Name: keyPath, New: compact(current)})
+			case !inSecond:
+				*changes = append(*changes, Change{Kind: Removed, Name: keyPath, Old: compact(old)})
+			default:
+				compareJSON(keyPath, old, current, changes)
+			}
+		}
+
+		return
+
+	case []any:
+		second, ok := b.([]any)
+		if !ok {
+			break
+		}
+
+		for i := 0; i < max(len(first), len(second)); i++ {
+			itemPath := fmt.Sprintf("%s[%d]", path, i)
+
+			switch {
+			case i >= len(first):
+				*changes = append(*changes, Change{Kind: Added, Name: itemPath, New: compact(second[i])})
+			case i >= len(second):
+				*changes = append(*changes, Change{Kind: Removed, Name: itemPath, Old: compact(first[i])})
+			default:
+				compareJSON(itemPath, first[i], second[i], changes)
+			}
+		}
+
+		return
+	}
+
+	if old, current := compact(a), compact(b); old != current {
+		*changes = append(*changes, Change{Kind: Changed, Name: path, Old: old, New: current})
+	}
+}
+
+func compact(v any) string {
+	var b bytes.Buffer
+
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+
+	if err := encoder.Encode(v); err != nil {
+		return fmt.Sprint(v)
+	}
+
+	return strings.TrimSpace(b.String())
+}
+
+// Text compares two bodies line by line
+func Text(a, b string) Body {
+	body := Body{
+		Format: BodyText,
+		Equal:  a == b,
+		Lines:  []Line{},
+	}
+
+	if body.Equal {
+		return body
+	}
+
+	first, second := splitLines(a), splitLines(b)
+
+	if len(first) > maxTextLines || len(second) > maxTextLines {
+		body.Truncated = true
+		body.Lines = firstDifference(first, second)
+		return body
+	}
+
+	body.Lines = lines(first, second)
+	return body
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// lines is a diff based on the longest common subsequence of both bodies
+func lines(a, b []string) []Line {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+				continue
+			}
+
+			lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+		}
+	}
+
+	var result []Line
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			result = append(result, Line{Op: LineEqual, Text: a[i]})
+			i++
+			j++
+
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			result = append(result, Line{Op: LineRemoved, Text: a[i]})
+			i++
+
+		default:
+			result = append(result, Line{Op: LineAdded, Text: b[j]})
+			j++
+		}
+	}
+
+	for ; i < len(a); i++ {
+		result = append(result, Line{Op: LineRemoved, Text: a[i]})
+	}
+
+	for ; j < len(b); j++ {
+		result = append(result, Line{Op: LineAdded, Text: b[j]})
+	}
+
+	return result
+}
+
+func firstDifference(a, b []string) []Line {
+	for i := 0; i < max(len(a), len(b)); i++ {
+		var result []Line
+
+		if i < len(a) {
+			result = append(result, Line{Op: LineRemoved, Text: a[i]})
+		}
+
+		if i < len(b) {
+			result = append(result, Line{Op: LineAdded, Text: b[i]})
+		}
+
+		if i >= len(a) || i >= len(b) || a[i] != b[i] {
+			return result
+		}
+	}
+
+	return []Line{}
+}
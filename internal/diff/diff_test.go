@@ -0,0 +1,126 @@
+package diff
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ayinke-llc/sdump"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeaders(t *testing.T) {
+	changes := Headers(http.Header{
+		"Content-Type":      []string{"application/json"},
+		"X-Github-Event":    []string{"push"},
+		"X-Hub-Signature":   []string{"sha1=abc"},
+		"X-Forwarded-For":   []string{"10.0.0.1", "10.0.0.2"},
+		"x-lowercase-value": []string{"same"},
+	}, http.Header{
+		"Content-Type":      []string{"application/json"},
+		"X-Github-Event":    []string{"pull_request"},
+		"X-Forwarded-For":   []string{"10.0.0.1"},
+		"User-Agent":        []string{"GitHub-Hookshot"},
+		"X-Lowercase-Value": []string{"same"},
+	})
+
+	require.Equal(t, []Change{
+		{Kind: Added, Name: "User-Agent", New: "GitHub-Hookshot"},
+		{Kind: Changed, Name: "X-Forwarded-For", Old: "10.0.0.1, 10.0.0.2", New: "10.0.0.1"},
+		{Kind: Changed, Name: "X-Github-Event", Old: "push", New: "pull_request"},
+		{Kind: Removed, Name: "X-Hub-Signature", Old: "sha1=abc"},
+	}, changes)
+
+	require.Empty(t, Headers(nil, http.Header{}))
+}
+
+func TestQuery(t *testing.T) {
+	changes, err := Query("page=1&sort=asc&tag=a", "page=2&tag=a&debug")
+	require.NoError(t, err)
+
+	require.Equal(t, []Change{
+		{Kind: Added, Name: "debug"},
+		{Kind: Changed, Name: "page", Old: "1", New: "2"},
+		{Kind: Removed, Name: "sort", Old: "asc"},
+	}, changes)
+
+	_, err = Query("a=%zz", "")
+	require.Error(t, err)
+}
+
+func TestBodies_JSON(t *testing.T) {
+	body := Bodies(
+		`{"id": "evt_1", "data": {"amount": 100, "tags": ["a", "b"], "meta": {"x": 1}}, "livemode": false}`,
+		`{"livemode": false, "data": {"tags": ["a"], "amount": 100.5, "meta": null, "currency": "usd"}, "id": "evt_1"}`,
+	)
+
+	require.Equal(t, BodyJSON, body.Format)
+	require.False(t, body.Equal)
+	require.Equal(t, []Change{
+		{Kind: Changed, Name: "$.data.amount", Old: "100", New: "100.5"},
+		{Kind: Added, Name: "$.data.currency", New: `"usd"`},
+		{Kind: Changed, Name: "$.data.meta", Old: `{"x":1}`, New: "null"},
+		{Kind: Removed, Name: "$.data.tags[1]", Old: `"b"`},
+	}, body.Changes)
+
+	// key order and whitespace do not matter
+	body = Bodies(`{"a": 1, "b": [1, 2]}`, `{"b":[1,2],"a":1}`)
+	require.True(t, body.Equal)
+	require.Empty(t, body.Changes)
+}
+
+func TestBodies_Text(t *testing.T) {
+	body := Bodies("a=1\nb=2\nc=3\n", "a=1\nc=3\nd=4")
+
+	require.Equal(t, BodyText, body.Format)
+	require.False(t, body.Equal)
+	require.Equal(t, []Line{
+		{Op: LineEqual, Text: "a=1"},
+		{Op: LineRemoved, Text: "b=2"},
+		{Op: LineEqual, Text: "c=3"},
+		{Op: LineAdded, Text: "d=4"},
+	}, body.Lines)
+
+	// a JSON body compared to one that is not falls back to text
+	body = Bodies(`{"a": 1}`, "")
+	require.Equal(t, BodyText, body.Format)
+	require.Equal(t, []Line{{Op: LineRemoved, Text: `{"a": 1}`}}, body.Lines)
+
+	body = Bodies("same", "same")
+	require.True(t, body.Equal)
+}
+
+func TestBodies_TextTooLarge(t *testing.T) {
+	a := strings.Repeat("line\n", maxTextLines+1)
+	b := strings.Repeat("line\n", 10) + "changed\n" + strings.Repeat("line\n", maxTextLines)
+
+	body := Bodies(a, b)
+	require.True(t, body.Truncated)
+	require.Equal(t, []Line{
+		{Op: LineRemoved, Text: "line"},
+		{Op: LineAdded, Text: "changed"},
+	}, body.Lines)
+}
+
+func TestRequests(t *testing.T) {
+	result, err := Requests(sdump.RequestDefinition{
+		Method: "post",
+		Body:   `{"a": 1}`,
+	}, sdump.RequestDefinition{
+		Method: "POST",
+		Body:   `{"a": 1}`,
+	})
+	require.NoError(t, err)
+	require.True(t, result.Equal())
+
+	result, err = Requests(sdump.RequestDefinition{
+		Method: http.MethodPost,
+		Query:  "a=1",
+	}, sdump.RequestDefinition{
+		Method: http.MethodPut,
+		Query:  "a=1",
+	})
+	require.NoError(t, err)
+	require.False(t, result.Equal())
+	require.Equal(t, &Change{Kind: Changed, Name: "method", Old: http.MethodPost, New: http.MethodPut}, result.Method)
+}
@@ -0,0 +1,422 @@
+// Package filter implements the small query language used to search
+// captured requests. A query is a list of space separated terms and a
+// request must match every term:
+//
+//	method:POST                   HTTP method
+//	status:valid|invalid          whether the body is valid JSON
+//	header:x-github-event=push    header value. header:x-github-event only checks presence
+//	query:page=2                  query string value. query:page only checks presence
+//	body.data.id=123              value at a JSON path in the body. Array items are indexed as body.items.0
+//	ip:10.0.0.0/8                 source ip address or network
+//	id:0c7b3b0a                   id prefix
+//	anything else                 case insensitive text search in the body and headers
+//
+// Terms can be negated with a leading - and values with spaces can be
+// quoted, e.g -method:GET body.user.name="John Doe"
+package filter
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/ayinke-llc/sdump"
+)
+
+type Kind string
+
+const (
+	KindMethod Kind = "method"
+	KindStatus Kind = "status"
+	KindHeader Kind = "header"
+	KindQuery  Kind = "query"
+	KindBody   Kind = "body"
+	KindIP     Kind = "ip"
+	KindID     Kind = "id"
+	KindText   Kind = "text"
+)
+
+// Term is a single condition of a query
+type Term struct {
+	Kind   Kind
+	Negate bool
+
+	// Key is the header or query name, or the JSON path for body terms
+	Key string
+	// Value is empty when only the presence of Key is checked
+	Value string
+
+	network *net.IPNet
+	path    []string
+}
+
+// Query is a parsed filter. The zero value matches every request
+type Query struct {
+	Raw   string
+	Terms []Term
+}
+
+// Request is what a query is matched against
+type Request struct {
+	ID      string
+	Request sdump.RequestDefinition
+}
+
+func (q *Query) IsEmpty() bool { return q == nil || len(q.Terms) == 0 }
+
+// Match reports whether the request satisfies every term of the query
+func (q *Query) Match(r Request) bool {
+	if q.IsEmpty() {
+		return true
+	}
+
+	for _, term := range q.Terms {
+		if term.match(r) == term.Negate {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Method returns the method the query requires if there is exactly one
+// non negated method term. Stores can use it to narrow results before
+// matching
+func (q *Query) Method() string {
+	var method string
+
+	for _, term := range q.Terms {
+		if term.Kind != KindMethod || term.Negate {
+			continue
+		}
+
+		if method != "" {
+			return ""
+		}
+
+		method = term.Value
+	}
+
+	return method
+}
+
+// Text returns the free text terms a request must contain. Stores can use
+// them to narrow results before matching
+func (q *Query) Text() []string {
+	var terms []string
+
+	for _, term := range q.Terms {
+		if term.Kind == KindText && !term.Negate {
+			terms = append(terms, term.Value)
+		}
+	}
+
+	return terms
+}
+
+// Body returns the JSON body values a request must have. Stores can use
+// them to narrow results before matching
+func (q *Query) Body() []sdump.BodyMatch {
+	var matches []sdump.BodyMatch
+
+	for _, term := range q.Terms {
+		if term.Kind != KindBody || term.Negate || term.Value == "" {
+			continue
+		}
+
+		matches = append(matches, sdump.BodyMatch{
+			Path:  term.path,
+			Value: term.Value,
+		})
+	}
+
+	return matches
+}
+
+// Parse parses a query. An empty string yields an empty query
+func Parse(s string) (*Query, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &Query{Raw: strings.TrimSpace(s)}
+
+	for _, token := range tokens {
+		term, err := parseTerm(token)
+		if err != nil {
+			return nil, err
+		}
+
+		q.Terms = append(q.Terms, term)
+	}
+
+	return q, nil
+}
+
+// tokenize splits on spaces outside of double quotes and removes the quotes
+func tokenize(s string) ([]string, error) {
+	var tokens []string
+	var current strings.Builder
+
+	inQuotes := false
+	hasToken := false
+
+	for _, r := range s {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			hasToken = true
+
+		case r == ' ' && !inQuotes:
+			if hasToken {
+				tokens = append(tokens, current.String())
+				current.Reset()
+				hasToken = false
+			}
+
+		default:
+			current.WriteRune(r)
+			hasToken = true
+		}
+	}
+
+	if inQuotes {
+		return nil, fmt.Errorf("unterminated quote in %q", s)
+	}
+
+	if hasToken {
+		tokens = append(tokens, current.String())
+	}
+
+	return tokens, nil
+}
+
+func parseTerm(token string) (Term, error) {
+	term := Term{}
+
+	if strings.HasPrefix(token, "-") && len(token) > 1 {
+		term.Negate = true
+		token = token[1:]
+	}
+
+	if strings.HasPrefix(token, "body.") {
+		path, value, found := strings.Cut(strings.TrimPrefix(token, "body."), "=")
+		if path == "" {
+			return Term{}, fmt.Errorf("%q is missing a JSON path", token)
+		}
+
+		term.Kind = KindBody
+		term.Key = path
+		term.path = strings.Split(path, ".")
+		if found {
+			term.Value = value
+		}
+
+		return term, nil
+	}
+
+	name, value, found := strings.Cut(token, ":")
+	if !found {
+		term.Kind = KindText
+		term.Value = strings.ToLower(token)
+		return term, nil
+	}
+
+	if value == "" {
+		return Term{}, fmt.Errorf("%q is missing a value", token)
+	}
+
+	switch Kind(strings.ToLower(name)) {
+	case KindMethod:
+		term.Kind = KindMethod
+		term.Value = strings.ToUpper(value)
+
+	case KindStatus:
+		value = strings.ToLower(value)
+		if value != "valid" && value != "invalid" {
+			return Term{}, fmt.Errorf("status must be valid or invalid, got %q", value)
+		}
+
+		term.Kind = KindStatus
+		term.Value = value
+
+	case KindHeader, KindQuery:
+		term.Kind = Kind(strings.ToLower(name))
+		term.Key, term.Value, _ = strings.Cut(value, "=")
+
+	case KindIP:
+		network, err := parseNetwork(value)
+		if err != nil {
+			return Term{}, err
+		}
+
+		term.Kind = KindIP
+		term.Value = value
+		term.network = network
+
+	case KindID:
+		term.Kind = KindID
+		term.Value = strings.ToLower(value)
+
+	default:
+		// things like urls contain colons too
+		term.Kind = KindText
+		term.Value = strings.ToLower(token)
+	}
+
+	return term, nil
+}
+
+func parseNetwork(s string) (*net.IPNet, error) {
+	if strings.Contains(s, "/") {
+		_, network, err := net.ParseCIDR(s)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a valid ip network", s)
+		}
+
+		return network, nil
+	}
+
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, fmt.Errorf("%q is not a valid ip address", s)
+	}
+
+	bits := 8 * net.IPv4len
+	if ip.To4() == nil {
+		bits = 8 * net.IPv6len
+	} else {
+		ip = ip.To4()
+	}
+
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+}
+
+func (t Term) match(r Request) bool {
+	req := r.Request
+
+	switch t.Kind {
+	case KindMethod:
+		method := req.Method
+		if method == "" {
+			method = http.MethodGet
+		}
+
+		return strings.EqualFold(method, t.Value)
+
+	case KindStatus:
+		return json.Valid([]byte(req.Body)) == (t.Value == "valid")
+
+	case KindHeader:
+		values := req.Headers.Values(t.Key)
+		if len(values) == 0 {
+			return false
+		}
+
+		return t.Value == "" || containsFold(values, t.Value)
+
+	case KindQuery:
+		values, err := url.ParseQuery(req.Query)
+		if err != nil || !values.Has(t.Key) {
+			return false
+		}
+
+		return t.Value == "" || containsFold(values[t.Key], t.Value)
+
+	case KindBody:
+		return t.matchBody(req.Body)
+
+	case KindIP:
+		return req.IPAddress != nil && t.network.Contains(req.IPAddress)
+
+	case KindID:
+		return strings.HasPrefix(strings.ToLower(r.ID), t.Value)
+
+	default:
+		return strings.Contains(strings.ToLower(req.Body), t.Value) ||
+			headersContain(req.Headers, t.Value)
+	}
+}
+
+// headersContain reports if a header, written as name: value, contains s.
+// s must be lower case
+func headersContain(h http.Header, s string) bool {
+	for name, values := range h {
+		for _, value := range values {
+			if strings.Contains(strings.ToLower(name+": "+value), s) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (t Term) matchBody(body string) bool {
+	var v any
+	if err := json.Unmarshal([]byte(body), &v); err != nil {
+		return false
+	}
+
+	for _, key := range t.path {
+		switch node := v.(type) {
+		case map[string]any:
+			value, ok := node[key]
+			if !ok {
+				return false
+			}
+
+			v = value
+
+		case []any:
+			idx, err := strconv.Atoi(key)
+			if err != nil || idx < 0 || idx >= len(node) {
+				return false
+			}
+
+			v = node[idx]
+
+		default:
+			return false
+		}
+	}
+
+	if t.Value == "" {
+		return true
+	}
+
+	s, ok := scalarString(v)
+	return ok && s == t.Value
+}
+
+// scalarString formats a decoded JSON value the way it is written in a
+// query. Objects and arrays cannot be compared to a value
+func scalarString(v any) (string, bool) {
+	switch value := v.(type) {
+	case string:
+		return value, true
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(value), true
+	case nil:
+		return "null", true
+	default:
+		return "", false
+	}
+}
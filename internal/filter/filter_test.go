@@ -0,0 +1,132 @@
+package filter
+
+import (
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/ayinke-llc/sdump"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tt := []struct {
+		name     string
+		query    string
+		hasError bool
+		terms    int
+	}{
+		{name: "empty query", query: "   "},
+		{name: "single term", query: "method:post", terms: 1},
+		{name: "multiple terms", query: "method:POST header:x-github-event=push ip:10.0.0.0/8", terms: 3},
+		{name: "quoted value", query: `body.user.name="John Doe" -status:invalid`, terms: 2},
+		{name: "unterminated quote", query: `body.user.name="John`, hasError: true},
+		{name: "missing value", query: "method:", hasError: true},
+		{name: "invalid status", query: "status:broken", hasError: true},
+		{name: "invalid network", query: "ip:10.0.0.0/99", hasError: true},
+		{name: "invalid ip", query: "ip:localhost", hasError: true},
+		{name: "missing json path", query: "body.=1", hasError: true},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			q, err := Parse(v.query)
+			if v.hasError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, q.Terms, v.terms)
+		})
+	}
+}
+
+func TestQuery_Match(t *testing.T) {
+	r := Request{
+		ID: "0c7b3b0a-6f4d-4f0e-9a6e-0c7f6fd2c6a1",
+		Request: sdump.RequestDefinition{
+			Method: http.MethodPost,
+			Body:   `{"data": {"id": 123, "object": {"id": "ch_123"}, "items": [{"name": "John Doe"}], "live": false}}`,
+			Query:  "page=2&sort=desc",
+			Headers: http.Header{
+				"X-Github-Event": []string{"push"},
+				"Content-Type":   []string{"application/json"},
+			},
+			IPAddress: net.ParseIP("10.1.2.3"),
+		},
+	}
+
+	tt := []struct {
+		query    string
+		expected bool
+	}{
+		{query: "", expected: true},
+		{query: "method:post", expected: true},
+		{query: "method:GET", expected: false},
+		{query: "-method:GET", expected: true},
+		{query: "status:valid", expected: true},
+		{query: "status:invalid", expected: false},
+		{query: "header:x-github-event=push", expected: true},
+		{query: "header:X-GitHub-Event=PUSH", expected: true},
+		{query: "header:x-github-event=ping", expected: false},
+		{query: "header:x-github-event", expected: true},
+		{query: "header:x-stripe-signature", expected: false},
+		{query: "query:page=2", expected: true},
+		{query: "query:page=3", expected: false},
+		{query: "query:sort", expected: true},
+		{query: "body.data.id=123", expected: true},
+		{query: "body.data.id=124", expected: false},
+		{query: "body.data.object.id=ch_123", expected: true},
+		{query: "body.data.object=ch_123", expected: false},
+		{query: `body.data.items.0.name="John Doe"`, expected: true},
+		{query: "body.data.items.1.name", expected: false},
+		{query: "body.data.live=false", expected: true},
+		{query: "body.data.missing", expected: false},
+		{query: "ip:10.0.0.0/8", expected: true},
+		{query: "ip:192.168.0.0/16", expected: false},
+		{query: "ip:10.1.2.3", expected: true},
+		{query: "id:0C7B3B0A", expected: true},
+		{query: "id:1c7b", expected: false},
+		{query: "ch_123", expected: true},
+		{query: "JOHN", expected: true},
+		{query: "jane", expected: false},
+		{query: "push", expected: true},
+		{query: "x-github-event", expected: true},
+		{query: "-push", expected: false},
+		{query: "method:POST status:valid header:x-github-event=push body.data.id=123 ip:10.0.0.0/8", expected: true},
+		{query: "method:POST status:invalid", expected: false},
+	}
+
+	for _, v := range tt {
+		t.Run(v.query, func(t *testing.T) {
+			q, err := Parse(v.query)
+			require.NoError(t, err)
+			require.Equal(t, v.expected, q.Match(r))
+		})
+	}
+}
+
+func TestQuery_Method(t *testing.T) {
+	q, err := Parse("method:post body.id=1")
+	require.NoError(t, err)
+	require.Equal(t, http.MethodPost, q.Method())
+
+	q, err = Parse("method:post method:put")
+	require.NoError(t, err)
+	require.Empty(t, q.Method())
+
+	q, err = Parse("-method:post")
+	require.NoError(t, err)
+	require.Empty(t, q.Method())
+}
+
+func TestQuery_Pushdown(t *testing.T) {
+	q, err := Parse(`method:post body.data.object.id=ch_123 -body.data.live=true body.data.items Stripe -ping`)
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"stripe"}, q.Text())
+	require.Equal(t, []sdump.BodyMatch{
+		{Path: []string{"data", "object", "id"}, Value: "ch_123"},
+	}, q.Body())
+}
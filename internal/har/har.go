@@ -0,0 +1,339 @@
+// Package har converts captured requests to and from HTTP Archive (HAR) 1.2
+// documents. See http://www.softwareishard.com/blog/har-12-spec/
+package har
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ayinke-llc/sdump"
+	"github.com/google/uuid"
+)
+
+const (
+	version     = "1.2"
+	httpVersion = "HTTP/1.1"
+
+	// ingestedResponseBody is what sdump replies with to every captured request
+	ingestedResponseBody = `{"message":"Request ingested"}`
+)
+
+type HAR struct {
+	Log Log `json:"log"`
+}
+
+type Log struct {
+	Version string  `json:"version"`
+	Creator Creator `json:"creator"`
+	Entries []Entry `json:"entries"`
+}
+
+type Creator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type Entry struct {
+	StartedDateTime time.Time `json:"startedDateTime"`
+	Time            float64   `json:"time"`
+	Request         Request   `json:"request"`
+	Response        Response  `json:"response"`
+	Cache           struct{}  `json:"cache"`
+	Timings         Timings   `json:"timings"`
+	ServerIPAddress string    `json:"serverIPAddress,omitempty"`
+	Comment         string    `json:"comment,omitempty"`
+
+	// Custom fields. The spec requires them to be prefixed with an underscore
+
+	// ID is the id of the captured request
+	ID string `json:"_id,omitempty"`
+	// ClientIPAddress is the ip address the captured request was sent from
+	ClientIPAddress string `json:"_clientIPAddress,omitempty"`
+	// ReplayOf is set on replays and references the captured request that
+	// was replayed
+	ReplayOf string `json:"_replayOf,omitempty"`
+}
+
+type NameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type Cookie struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type Request struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []Cookie    `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	QueryString []NameValue `json:"queryString"`
+	PostData    *PostData   `json:"postData,omitempty"`
+	HeadersSize int64       `json:"headersSize"`
+	BodySize    int64       `json:"bodySize"`
+}
+
+type PostData struct {
+	MimeType string      `json:"mimeType"`
+	Text     string      `json:"text"`
+	Params   []NameValue `json:"params,omitempty"`
+	// Encoding is base64 when Text holds a body that is not text. It mirrors
+	// the encoding field of the response content
+	Encoding string `json:"encoding,omitempty"`
+}
+
+type Response struct {
+	Status      int         `json:"status"`
+	StatusText  string      `json:"statusText"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []Cookie    `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	Content     Content     `json:"content"`
+	RedirectURL string      `json:"redirectURL"`
+	HeadersSize int64       `json:"headersSize"`
+	BodySize    int64       `json:"bodySize"`
+}
+
+type Content struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+type Timings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// New builds a HAR document out of captured requests. endpointURL is the
+// sdump url the requests were sent to. Replays, if any, are added as
+// additional entries right after the request they replayed
+func New(endpointURL string,
+	ingests []sdump.IngestHTTPRequest,
+	replays []sdump.Replay,
+) *HAR {
+	replaysByIngest := make(map[uuid.UUID][]sdump.Replay)
+	for _, r := range replays {
+		replaysByIngest[r.IngestID] = append(replaysByIngest[r.IngestID], r)
+	}
+
+	entries := make([]Entry, 0, len(ingests)+len(replays))
+
+	for _, ingest := range ingests {
+		entries = append(entries, fromIngest(endpointURL, ingest))
+
+		ingestReplays := replaysByIngest[ingest.ID]
+		sort.Slice(ingestReplays, func(i, j int) bool {
+			return ingestReplays[i].CreatedAt.Before(ingestReplays[j].CreatedAt)
+		})
+
+		for _, r := range ingestReplays {
+			entries = append(entries, fromReplay(r))
+		}
+	}
+
+	return &HAR{
+		Log: Log{
+			Version: version,
+			Creator: Creator{
+				Name:    "sdump",
+				Version: version,
+			},
+			Entries: entries,
+		},
+	}
+}
+
+func fromIngest(endpointURL string, ingest sdump.IngestHTTPRequest) Entry {
+	ip := ""
+	if len(ingest.Request.IPAddress) > 0 {
+		ip = ingest.Request.IPAddress.String()
+	}
+
+	return Entry{
+		StartedDateTime: ingest.CreatedAt,
+		Request:         toRequest(endpointURL, ingest.Request),
+		Response: Response{
+			Status:      http.StatusAccepted,
+			StatusText:  http.StatusText(http.StatusAccepted),
+			HTTPVersion: httpVersion,
+			Cookies:     []Cookie{},
+			Headers: []NameValue{
+				{Name: "Content-Type", Value: "application/json"},
+			},
+			Content: Content{
+				Size:     int64(len(ingestedResponseBody)),
+				MimeType: "application/json",
+				Text:     ingestedResponseBody,
+			},
+			HeadersSize: -1,
+			BodySize:    int64(len(ingestedResponseBody)),
+		},
+		ID:              ingest.ID.String(),
+		ClientIPAddress: ip,
+	}
+}
+
+func fromReplay(r sdump.Replay) Entry {
+	entry := Entry{
+		StartedDateTime: r.CreatedAt,
+		Time:            durationToMilliseconds(r.Response.Duration),
+		Request:         toRequest(r.TargetURL, r.Request),
+		Response: Response{
+			Status:      r.Response.StatusCode,
+			StatusText:  http.StatusText(r.Response.StatusCode),
+			HTTPVersion: httpVersion,
+			Cookies:     toResponseCookies(r.Response.Headers),
+			Headers:     toNameValues(r.Response.Headers),
+			Content: Content{
+				Size:     r.Response.Size,
+				MimeType: r.Response.Headers.Get("Content-Type"),
+				Text:     r.Response.Body,
+			},
+			RedirectURL: r.Response.Headers.Get("Location"),
+			HeadersSize: -1,
+			BodySize:    r.Response.Size,
+		},
+		Timings: Timings{
+			Wait: durationToMilliseconds(r.Response.Duration),
+		},
+		ID:       r.ID.String(),
+		ReplayOf: r.IngestID.String(),
+		Comment:  r.Error,
+	}
+
+	return entry
+}
+
+func toRequest(baseURL string, def sdump.RequestDefinition) Request {
+	// compressed bodies that could be decoded are exported as the decoded
+	// text so they can be read from any HAR viewer
+	def = def.WithoutEncoding()
+
+	u := baseURL
+	if def.Query != "" {
+		separator := "?"
+		if strings.Contains(u, "?") {
+			separator = "&"
+		}
+
+		u += separator + def.Query
+	}
+
+	var queryString []NameValue
+
+	values, _ := url.ParseQuery(def.Query)
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		for _, value := range values[key] {
+			queryString = append(queryString, NameValue{Name: key, Value: value})
+		}
+	}
+
+	if queryString == nil {
+		queryString = []NameValue{}
+	}
+
+	method := def.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	req := Request{
+		Method:      method,
+		URL:         u,
+		HTTPVersion: httpVersion,
+		Cookies:     toRequestCookies(def.Headers),
+		Headers:     toNameValues(def.Headers),
+		QueryString: queryString,
+		HeadersSize: -1,
+		BodySize:    int64(len(def.Body)),
+	}
+
+	postData := &PostData{
+		MimeType: def.Headers.Get("Content-Type"),
+		Text:     def.Body,
+	}
+
+	// the body of binary and undecodable requests only holds a JSON view of
+	// them, if anything. The bytes that were sent are exported instead so
+	// they match the Content-Type and survive an import
+	if def.Binary != nil || def.Encoding != nil {
+		if b, err := def.OriginalBody(); err == nil {
+			postData.Text = base64.StdEncoding.EncodeToString(b)
+			postData.Encoding = "base64"
+			req.BodySize = int64(len(b))
+		}
+	}
+
+	if postData.Text != "" {
+		req.PostData = postData
+	}
+
+	return req
+}
+
+func toNameValues(headers http.Header) []NameValue {
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	values := []NameValue{}
+	for _, key := range keys {
+		for _, value := range headers[key] {
+			values = append(values, NameValue{Name: key, Value: value})
+		}
+	}
+
+	return values
+}
+
+func toRequestCookies(headers http.Header) []Cookie {
+	cookies := []Cookie{}
+
+	req := http.Request{Header: headers}
+	for _, c := range req.Cookies() {
+		cookies = append(cookies, Cookie{Name: c.Name, Value: c.Value})
+	}
+
+	return cookies
+}
+
+func toResponseCookies(headers http.Header) []Cookie {
+	cookies := []Cookie{}
+
+	resp := http.Response{Header: headers}
+	for _, c := range resp.Cookies() {
+		cookies = append(cookies, Cookie{Name: c.Name, Value: c.Value})
+	}
+
+	return cookies
+}
+
+func durationToMilliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// Filename returns a sensible file name for an exported archive
+func Filename(name string) string {
+	return fmt.Sprintf("sdump-%s.har", name)
+}
@@ -0,0 +1,242 @@
+package har
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ayinke-llc/sdump"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	ingestID := uuid.New()
+	createdAt := time.Date(2024, time.January, 20, 14, 30, 0, 0, time.UTC)
+
+	ingests := []sdump.IngestHTTPRequest{
+		{
+			ID: ingestID,
+			Request: sdump.RequestDefinition{
+				Method: http.MethodPost,
+				Body:   `{"name": "sdump"}`,
+				Query:  "sort=desc&page=1",
+				Headers: http.Header{
+					"Content-Type": []string{"application/json"},
+					"Cookie":       []string{"session=abc; theme=dark"},
+				},
+				IPAddress: net.ParseIP("10.0.0.1"),
+			},
+			CreatedAt: createdAt,
+		},
+	}
+
+	replays := []sdump.Replay{
+		{
+			ID:        uuid.New(),
+			IngestID:  ingestID,
+			TargetURL: "http://localhost:3000/webhooks",
+			Request:   ingests[0].Request,
+			Response: sdump.ResponseDefinition{
+				StatusCode: http.StatusCreated,
+				Headers: http.Header{
+					"Content-Type": []string{"text/plain"},
+				},
+				Body:     "created",
+				Size:     7,
+				Duration: 1500 * time.Microsecond,
+			},
+			CreatedAt: createdAt.Add(time.Minute),
+		},
+	}
+
+	doc := New("https://sdump.app/cmltfm6g330l5l1vq110", ingests, replays)
+
+	require.Equal(t, "1.2", doc.Log.Version)
+	require.Len(t, doc.Log.Entries, 2)
+
+	captured := doc.Log.Entries[0]
+	require.Equal(t, ingestID.String(), captured.ID)
+	require.Equal(t, "10.0.0.1", captured.ClientIPAddress)
+	require.Equal(t, createdAt, captured.StartedDateTime)
+	require.Equal(t, "https://sdump.app/cmltfm6g330l5l1vq110?sort=desc&page=1", captured.Request.URL)
+	require.Equal(t, []NameValue{
+		{Name: "page", Value: "1"},
+		{Name: "sort", Value: "desc"},
+	}, captured.Request.QueryString)
+	require.Equal(t, []Cookie{
+		{Name: "session", Value: "abc"},
+		{Name: "theme", Value: "dark"},
+	}, captured.Request.Cookies)
+	require.Equal(t, "application/json", captured.Request.PostData.MimeType)
+	require.Equal(t, `{"name": "sdump"}`, captured.Request.PostData.Text)
+	require.Equal(t, http.StatusAccepted, captured.Response.Status)
+
+	replayed := doc.Log.Entries[1]
+	require.Equal(t, ingestID.String(), replayed.ReplayOf)
+	require.Equal(t, "http://localhost:3000/webhooks?sort=desc&page=1", replayed.Request.URL)
+	require.Equal(t, http.StatusCreated, replayed.Response.Status)
+	require.Equal(t, "created", replayed.Response.Content.Text)
+	require.Equal(t, "text/plain", replayed.Response.Content.MimeType)
+	require.Equal(t, 1.5, replayed.Time)
+}
+
+func TestNew_WithoutBody(t *testing.T) {
+	doc := New("https://sdump.app/cmltfm6g330l5l1vq110", []sdump.IngestHTTPRequest{
+		{ID: uuid.New()},
+	}, nil)
+
+	require.Len(t, doc.Log.Entries, 1)
+	require.Nil(t, doc.Log.Entries[0].Request.PostData)
+	require.Equal(t, http.MethodGet, doc.Log.Entries[0].Request.Method)
+	require.Empty(t, doc.Log.Entries[0].Request.QueryString)
+}
+
+func TestHAR_Ingests(t *testing.T) {
+	createdAt := time.Date(2024, time.January, 20, 14, 30, 0, 0, time.UTC)
+
+	ingest := sdump.IngestHTTPRequest{
+		ID: uuid.New(),
+		Request: sdump.RequestDefinition{
+			Method: http.MethodPost,
+			Body:   `{"name": "sdump"}`,
+			Query:  "page=1&sort=desc",
+			Headers: http.Header{
+				"Content-Type": []string{"application/json"},
+				"X-Event":      []string{"push", "ping"},
+			},
+			IPAddress: net.ParseIP("10.0.0.1"),
+			Size:      17,
+		},
+		CreatedAt: createdAt,
+	}
+
+	doc := New("https://sdump.app/cmltfm6g330l5l1vq110", []sdump.IngestHTTPRequest{ingest},
+		[]sdump.Replay{{IngestID: ingest.ID, TargetURL: "http://localhost:3000"}})
+
+	ingests, err := doc.Ingests()
+	require.NoError(t, err)
+	require.Len(t, ingests, 1)
+
+	require.Equal(t, ingest.Request, ingests[0].Request)
+	require.Equal(t, createdAt, ingests[0].CreatedAt)
+}
+
+func TestHAR_Ingests_OriginalBytes(t *testing.T) {
+	msgpack := []byte{0x81, 0xa4, 'n', 'a', 'm', 'e', 0xa5, 's', 'd', 'u', 'm', 'p'}
+	corrupt := []byte{0x1f, 0x8b, 0x00, 0xff}
+
+	tt := []struct {
+		name    string
+		request sdump.RequestDefinition
+		body    []byte
+	}{
+		{
+			name: "binary body",
+			request: sdump.RequestDefinition{
+				Method: http.MethodPost,
+				Body:   `{"name":"sdump"}`,
+				Headers: http.Header{
+					"Content-Type": []string{"application/msgpack"},
+				},
+				Binary: &sdump.BinaryBody{
+					Format:   "msgpack",
+					Original: base64.StdEncoding.EncodeToString(msgpack),
+				},
+			},
+			body: msgpack,
+		},
+		{
+			name: "undecodable compressed body",
+			request: sdump.RequestDefinition{
+				Method: http.MethodPost,
+				Headers: http.Header{
+					"Content-Type":     []string{"application/json"},
+					"Content-Encoding": []string{"gzip"},
+				},
+				Encoding: &sdump.BodyEncoding{
+					ContentEncoding: "gzip",
+					Original:        base64.StdEncoding.EncodeToString(corrupt),
+					Error:           "unexpected EOF",
+				},
+			},
+			body: corrupt,
+		},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			doc := New("https://sdump.app/cmltfm6g330l5l1vq110", []sdump.IngestHTTPRequest{
+				{ID: uuid.New(), Request: v.request},
+			}, nil)
+
+			postData := doc.Log.Entries[0].Request.PostData
+			require.NotNil(t, postData)
+			require.Equal(t, "base64", postData.Encoding)
+			require.Equal(t, v.request.Headers.Get("Content-Type"), postData.MimeType)
+			require.Equal(t, int64(len(v.body)), doc.Log.Entries[0].Request.BodySize)
+
+			b, err := json.Marshal(doc)
+			require.NoError(t, err)
+
+			imported := new(HAR)
+			require.NoError(t, json.Unmarshal(b, imported))
+
+			ingests, err := imported.Ingests()
+			require.NoError(t, err)
+			require.Len(t, ingests, 1)
+
+			require.Equal(t, string(v.body), ingests[0].Request.Body)
+			require.Equal(t, v.request.Headers, ingests[0].Request.Headers)
+		})
+	}
+}
+
+func TestHAR_Ingests_BrowserExport(t *testing.T) {
+	doc := &HAR{
+		Log: Log{
+			Entries: []Entry{
+				{
+					Request: Request{
+						Method: "post",
+						URL:    "https://example.com/hooks",
+						Headers: []NameValue{
+							{Name: ":authority", Value: "example.com"},
+							{Name: "accept", Value: "*/*"},
+						},
+						Cookies: []Cookie{
+							{Name: "session", Value: "abc"},
+						},
+						QueryString: []NameValue{
+							{Name: "page", Value: "1"},
+						},
+						PostData: &PostData{
+							MimeType: "application/x-www-form-urlencoded",
+							Params: []NameValue{
+								{Name: "name", Value: "sdump"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	ingests, err := doc.Ingests()
+	require.NoError(t, err)
+	require.Len(t, ingests, 1)
+
+	req := ingests[0].Request
+	require.Equal(t, http.MethodPost, req.Method)
+	require.Equal(t, "page=1", req.Query)
+	require.Equal(t, "name=sdump", req.Body)
+	require.Equal(t, http.Header{
+		"Accept":       []string{"*/*"},
+		"Cookie":       []string{"session=abc"},
+		"Content-Type": []string{"application/x-www-form-urlencoded"},
+	}, req.Headers)
+	require.Nil(t, req.IPAddress)
+}
@@ -0,0 +1,114 @@
+package har
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ayinke-llc/sdump"
+)
+
+// Ingests converts the entries of the document back into captured
+// requests. Entries that are replays of another request are skipped since
+// they were never received by sdump
+func (h *HAR) Ingests() ([]sdump.IngestHTTPRequest, error) {
+	ingests := make([]sdump.IngestHTTPRequest, 0, len(h.Log.Entries))
+
+	for _, entry := range h.Log.Entries {
+		if entry.ReplayOf != "" {
+			continue
+		}
+
+		ingest, err := toIngest(entry)
+		if err != nil {
+			return nil, err
+		}
+
+		ingests = append(ingests, ingest)
+	}
+
+	return ingests, nil
+}
+
+func toIngest(entry Entry) (sdump.IngestHTTPRequest, error) {
+	u, err := url.Parse(entry.Request.URL)
+	if err != nil {
+		return sdump.IngestHTTPRequest{}, err
+	}
+
+	headers := make(http.Header, len(entry.Request.Headers))
+	for _, header := range entry.Request.Headers {
+		// HTTP/2 pseudo headers exported by browsers are not real headers
+		if strings.HasPrefix(header.Name, ":") {
+			continue
+		}
+
+		headers.Add(header.Name, header.Value)
+	}
+
+	if len(entry.Request.Cookies) > 0 && headers.Get("Cookie") == "" {
+		cookies := make([]string, 0, len(entry.Request.Cookies))
+		for _, c := range entry.Request.Cookies {
+			cookies = append(cookies, (&http.Cookie{Name: c.Name, Value: c.Value}).String())
+		}
+
+		headers.Set("Cookie", strings.Join(cookies, "; "))
+	}
+
+	query := u.RawQuery
+	if query == "" && len(entry.Request.QueryString) > 0 {
+		values := url.Values{}
+		for _, v := range entry.Request.QueryString {
+			values.Add(v.Name, v.Value)
+		}
+
+		query = values.Encode()
+	}
+
+	var body string
+	if entry.Request.PostData != nil {
+		body = entry.Request.PostData.Text
+
+		if entry.Request.PostData.Encoding == "base64" {
+			b, err := base64.StdEncoding.DecodeString(body)
+			if err != nil {
+				return sdump.IngestHTTPRequest{}, fmt.Errorf("could not decode body of %s: %v", entry.Request.URL, err)
+			}
+
+			body = string(b)
+		}
+
+		if body == "" && len(entry.Request.PostData.Params) > 0 {
+			values := url.Values{}
+			for _, v := range entry.Request.PostData.Params {
+				values.Add(v.Name, v.Value)
+			}
+
+			body = values.Encode()
+		}
+
+		if headers.Get("Content-Type") == "" && entry.Request.PostData.MimeType != "" {
+			headers.Set("Content-Type", entry.Request.PostData.MimeType)
+		}
+	}
+
+	method := strings.ToUpper(entry.Request.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	return sdump.IngestHTTPRequest{
+		Request: sdump.RequestDefinition{
+			Body:      body,
+			Query:     query,
+			Headers:   headers,
+			IPAddress: net.ParseIP(entry.ClientIPAddress),
+			Size:      int64(len(body)),
+			Method:    method,
+		},
+		CreatedAt: entry.StartedDateTime,
+	}, nil
+}
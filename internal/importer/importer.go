@@ -0,0 +1,157 @@
+// Package importer reads archives of captured requests so they can be
+// loaded into an endpoint. Both HAR documents and NDJSON dumps of
+// sdump.IngestHTTPRequest records are supported
+package importer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ayinke-llc/sdump"
+	"github.com/ayinke-llc/sdump/internal/har"
+	"github.com/ayinke-llc/sdump/internal/payload"
+	"github.com/google/uuid"
+)
+
+var ErrEmptyArchive = errors.New("archive does not contain any request")
+
+// Decode reads captured requests from r. The format is detected from the
+// content. A JSON document with a top level log key is treated as a HAR
+// file, anything else as a stream of IngestHTTPRequest records
+func Decode(r io.Reader) ([]sdump.IngestHTTPRequest, error) {
+	dec := json.NewDecoder(r)
+
+	var first json.RawMessage
+	if err := dec.Decode(&first); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, ErrEmptyArchive
+		}
+
+		return nil, fmt.Errorf("could not decode archive: %w", err)
+	}
+
+	var probe struct {
+		Log json.RawMessage `json:"log"`
+	}
+
+	if bytes.HasPrefix(bytes.TrimSpace(first), []byte("{")) {
+		if err := json.Unmarshal(first, &probe); err != nil {
+			return nil, fmt.Errorf("could not decode archive: %w", err)
+		}
+	}
+
+	var ingests []sdump.IngestHTTPRequest
+	var err error
+
+	if probe.Log != nil {
+		ingests, err = decodeHAR(first)
+	} else {
+		ingests, err = decodeNDJSON(first, dec)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ingests) == 0 {
+		return nil, ErrEmptyArchive
+	}
+
+	return ingests, nil
+}
+
+func decodeHAR(b json.RawMessage) ([]sdump.IngestHTTPRequest, error) {
+	doc := new(har.HAR)
+	if err := json.Unmarshal(b, doc); err != nil {
+		return nil, fmt.Errorf("could not decode HAR file: %w", err)
+	}
+
+	return doc.Ingests()
+}
+
+func decodeNDJSON(first json.RawMessage, dec *json.Decoder) ([]sdump.IngestHTTPRequest, error) {
+	var ingests []sdump.IngestHTTPRequest
+
+	ingest := sdump.IngestHTTPRequest{}
+	if err := json.Unmarshal(first, &ingest); err != nil {
+		return nil, fmt.Errorf("could not decode record 1: %w", err)
+	}
+
+	ingests = append(ingests, ingest)
+
+	for {
+		ingest := sdump.IngestHTTPRequest{}
+
+		err := dec.Decode(&ingest)
+		if errors.Is(err, io.EOF) {
+			return ingests, nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("could not decode record %d: %w", len(ingests)+1, err)
+		}
+
+		ingests = append(ingests, ingest)
+	}
+}
+
+// Options configures how an archive is imported
+type Options struct {
+	// KeepAnnotations keeps the stars, notes and tags found in the archive.
+	// They are dropped by default as annotated requests are never pruned
+	KeepAnnotations bool
+}
+
+// Import stores the captured requests against the endpoint. Every request
+// gets a new id so an archive can be imported more than once. Timestamps
+// and ip addresses from the archive are kept. Bodies go through the same
+// decoding as requests sent to the endpoint. The archive is imported as a
+// whole. If any request cannot be stored, none are
+func Import(ctx context.Context,
+	ingestRepo sdump.IngestRepository,
+	decoder *payload.Decoder,
+	endpoint *sdump.URLEndpoint,
+	ingests []sdump.IngestHTTPRequest,
+	opts *Options,
+) (int, error) {
+	records := make([]sdump.IngestHTTPRequest, 0, len(ingests))
+
+	for i := range ingests {
+		ingest := ingests[i]
+
+		ingest.ID = uuid.New()
+		ingest.UrlID = endpoint.ID
+		ingest.UpdatedAt = time.Time{}
+		ingest.DeletedAt = nil
+
+		if !opts.KeepAnnotations {
+			ingest.Starred = false
+			ingest.Note = ""
+			ingest.Tags = nil
+		}
+
+		body, err := ingest.Request.OriginalBody()
+		if err != nil {
+			return 0, fmt.Errorf("could not decode body of record %d: %w", i+1, err)
+		}
+
+		if ingest.Request.Size == 0 {
+			ingest.Request.Size = int64(len(body))
+		}
+
+		decoder.Decode(endpoint, &ingest.Request, body)
+
+		records = append(records, ingest)
+	}
+
+	if err := ingestRepo.CreateMany(ctx, records); err != nil {
+		return 0, err
+	}
+
+	return len(records), nil
+}
@@ -0,0 +1,284 @@
+package importer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ayinke-llc/sdump"
+	"github.com/ayinke-llc/sdump/internal/har"
+	"github.com/ayinke-llc/sdump/internal/payload"
+	"github.com/ayinke-llc/sdump/mocks"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDecode(t *testing.T) {
+	createdAt := time.Date(2024, time.January, 20, 14, 30, 0, 0, time.UTC)
+
+	ingest := sdump.IngestHTTPRequest{
+		ID: uuid.New(),
+		Request: sdump.RequestDefinition{
+			Method: http.MethodPost,
+			Body:   `{"name": "sdump"}`,
+		},
+		CreatedAt: createdAt,
+	}
+
+	harFile, err := json.MarshalIndent(har.New("https://sdump.app/cmltfm6g330l5l1vq110",
+		[]sdump.IngestHTTPRequest{ingest, ingest}, nil), "", "  ")
+	require.NoError(t, err)
+
+	ndjson := new(bytes.Buffer)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, json.NewEncoder(ndjson).Encode(ingest))
+	}
+
+	tt := []struct {
+		name     string
+		archive  string
+		expected int
+		hasError bool
+	}{
+		{
+			name:     "empty archive",
+			archive:  "  ",
+			hasError: true,
+		},
+		{
+			name:     "invalid json",
+			archive:  "{oops",
+			hasError: true,
+		},
+		{
+			name:     "invalid record in ndjson",
+			archive:  ndjson.String() + `{"request": "oops"}`,
+			hasError: true,
+		},
+		{
+			name:     "har file without entries",
+			archive:  `{"log": {"version": "1.2", "entries": []}}`,
+			hasError: true,
+		},
+		{
+			name:     "har file",
+			archive:  string(harFile),
+			expected: 2,
+		},
+		{
+			name:     "ndjson",
+			archive:  ndjson.String(),
+			expected: 3,
+		},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			ingests, err := Decode(strings.NewReader(v.archive))
+			if v.hasError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, ingests, v.expected)
+
+			for _, i := range ingests {
+				require.Equal(t, createdAt, i.CreatedAt.UTC())
+				require.Equal(t, ingest.Request.Body, i.Request.Body)
+			}
+		})
+	}
+}
+
+func TestImport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	endpoint := &sdump.URLEndpoint{ID: uuid.New()}
+	originalID := uuid.New()
+
+	ingestRepo := mocks.NewMockIngestRepository(ctrl)
+
+	ingestRepo.EXPECT().CreateMany(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, ingests []sdump.IngestHTTPRequest) error {
+			require.Len(t, ingests, 2)
+			require.NotEqual(t, originalID, ingests[0].ID)
+			require.NotEqual(t, ingests[0].ID, ingests[1].ID)
+			require.Equal(t, endpoint.ID, ingests[0].UrlID)
+			require.Equal(t, int64(2), ingests[0].Request.Size)
+			return nil
+		})
+
+	ingests := []sdump.IngestHTTPRequest{
+		{ID: originalID, Request: sdump.RequestDefinition{Body: "ok"}},
+		{ID: originalID},
+	}
+
+	imported, err := Import(context.Background(), ingestRepo, testDecoder(),
+		endpoint, ingests, &Options{})
+	require.NoError(t, err)
+	require.Equal(t, 2, imported)
+
+	// the caller's records are left untouched
+	require.Equal(t, originalID, ingests[0].ID)
+}
+
+func TestImport_NothingStoredOnError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ingestRepo := mocks.NewMockIngestRepository(ctrl)
+
+	ingestRepo.EXPECT().CreateMany(gomock.Any(), gomock.Len(2)).
+		Times(1).
+		Return(errors.New("could not create ingest"))
+
+	imported, err := Import(context.Background(), ingestRepo, testDecoder(),
+		&sdump.URLEndpoint{ID: uuid.New()}, []sdump.IngestHTTPRequest{
+			{Request: sdump.RequestDefinition{Body: "ok"}},
+			{Request: sdump.RequestDefinition{Body: "ok"}},
+		}, &Options{})
+	require.Error(t, err)
+	require.Equal(t, 0, imported)
+}
+
+func TestImport_InvalidBody(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// nothing is stored when a record of the archive is invalid
+	ingestRepo := mocks.NewMockIngestRepository(ctrl)
+
+	imported, err := Import(context.Background(), ingestRepo, testDecoder(),
+		&sdump.URLEndpoint{ID: uuid.New()}, []sdump.IngestHTTPRequest{
+			{Request: sdump.RequestDefinition{Body: "ok"}},
+			{Request: sdump.RequestDefinition{Binary: &sdump.BinaryBody{Original: "not base64"}}},
+		}, &Options{})
+	require.ErrorContains(t, err, "record 2")
+	require.Equal(t, 0, imported)
+}
+
+func TestImport_DecodesBodies(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	compressed := new(bytes.Buffer)
+	gz := gzip.NewWriter(compressed)
+	_, err := gz.Write([]byte(`{"name": "sdump"}`))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+
+	// a msgpack map of {"name": "sdump"}
+	msgpack := []byte{0x81, 0xa4, 'n', 'a', 'm', 'e', 0xa5, 's', 'd', 'u', 'm', 'p'}
+
+	ingests := []sdump.IngestHTTPRequest{
+		{
+			Request: sdump.RequestDefinition{
+				Headers: http.Header{"Content-Encoding": []string{"gzip"}},
+				Body:    compressed.String(),
+			},
+		},
+		{
+			Request: sdump.RequestDefinition{
+				Headers: http.Header{"Content-Type": []string{"application/msgpack"}},
+				Binary: &sdump.BinaryBody{
+					Original: base64.StdEncoding.EncodeToString(msgpack),
+				},
+			},
+		},
+	}
+
+	var stored []sdump.IngestHTTPRequest
+
+	ingestRepo := mocks.NewMockIngestRepository(ctrl)
+
+	ingestRepo.EXPECT().CreateMany(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, ingests []sdump.IngestHTTPRequest) error {
+			stored = ingests
+			return nil
+		})
+
+	imported, err := Import(context.Background(), ingestRepo, testDecoder(),
+		&sdump.URLEndpoint{ID: uuid.New()}, ingests, &Options{})
+	require.NoError(t, err)
+	require.Equal(t, 2, imported)
+
+	require.Equal(t, `{"name": "sdump"}`, stored[0].Request.Body)
+	require.True(t, stored[0].Request.IsDecoded())
+	require.Equal(t, int64(compressed.Len()), stored[0].Request.Size)
+
+	original, err := stored[0].Request.OriginalBody()
+	require.NoError(t, err)
+	require.Equal(t, compressed.Bytes(), original)
+
+	require.NotNil(t, stored[1].Request.Binary)
+	require.Equal(t, "msgpack", stored[1].Request.Binary.Format)
+	require.JSONEq(t, `{"name": "sdump"}`, stored[1].Request.Body)
+	require.Equal(t, int64(len(msgpack)), stored[1].Request.Size)
+}
+
+func TestImport_Annotations(t *testing.T) {
+	tt := []struct {
+		name            string
+		keepAnnotations bool
+	}{
+		{
+			name: "annotations are dropped",
+		},
+		{
+			name:            "annotations are kept",
+			keepAnnotations: true,
+		},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ingests := []sdump.IngestHTTPRequest{
+				{
+					Request: sdump.RequestDefinition{Body: "ok"},
+					Starred: true,
+					Note:    "first order",
+					Tags:    []string{"stripe"},
+				},
+			}
+
+			ingestRepo := mocks.NewMockIngestRepository(ctrl)
+
+			ingestRepo.EXPECT().CreateMany(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(_ context.Context, ingests []sdump.IngestHTTPRequest) error {
+					require.Equal(t, v.keepAnnotations, ingests[0].IsAnnotated())
+					return nil
+				})
+
+			_, err := Import(context.Background(), ingestRepo, testDecoder(),
+				&sdump.URLEndpoint{ID: uuid.New()}, ingests, &Options{
+					KeepAnnotations: v.keepAnnotations,
+				})
+			require.NoError(t, err)
+		})
+	}
+}
+
+func testDecoder() *payload.Decoder {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	return payload.NewDecoder(logrus.NewEntry(logger), 1024)
+}
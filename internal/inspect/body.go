@@ -0,0 +1,226 @@
+package inspect
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Format is how a request body is rendered
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatXML  Format = "xml"
+	FormatHTML Format = "html"
+	FormatYAML Format = "yaml"
+	// FormatGraphQL shows the query and variables of GraphQL operations
+	FormatGraphQL Format = "graphql"
+	FormatForm    Format = "form"
+	FormatText    Format = "text"
+	FormatBinary  Format = "binary"
+)
+
+// Formats lists every supported format in the order they are cycled through
+var Formats = []Format{
+	FormatJSON, FormatXML, FormatHTML, FormatYAML,
+	FormatGraphQL, FormatForm, FormatText, FormatBinary,
+}
+
+// Lexer is the name of the chroma lexer used to highlight the format
+func (f Format) Lexer() string {
+	switch f {
+	case FormatJSON, FormatXML, FormatHTML, FormatYAML, FormatGraphQL:
+		return string(f)
+	default:
+		return "plaintext"
+	}
+}
+
+// DetectFormat picks a format from the Content-Type of the request. If the
+// header is missing or too generic, the body is sniffed instead
+func DetectFormat(h http.Header, body string) Format {
+	if isBinary(body) {
+		return FormatBinary
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(h.Get("Content-Type"))
+
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		return FormatForm
+
+	case mediaType == "application/graphql":
+		return FormatGraphQL
+
+	case mediaType == "application/json", strings.HasSuffix(mediaType, "+json"):
+		return FormatJSON
+
+	case mediaType == "text/html", mediaType == "application/xhtml+xml":
+		return FormatHTML
+
+	case mediaType == "application/xml", mediaType == "text/xml",
+		strings.HasSuffix(mediaType, "+xml"):
+		return FormatXML
+
+	case strings.Contains(mediaType, "yaml"):
+		return FormatYAML
+
+	case strings.HasPrefix(mediaType, "image/"),
+		strings.HasPrefix(mediaType, "audio/"),
+		strings.HasPrefix(mediaType, "video/"),
+		mediaType == "application/octet-stream",
+		mediaType == "application/pdf",
+		mediaType == "application/zip":
+		return FormatBinary
+
+	case strings.HasPrefix(mediaType, "text/") && mediaType != "text/plain":
+		return FormatText
+	}
+
+	return sniff(body)
+}
+
+func sniff(body string) Format {
+	trimmed := strings.TrimSpace(body)
+
+	switch {
+	case trimmed == "":
+		return FormatText
+
+	case json.Valid([]byte(trimmed)):
+		return FormatJSON
+
+	case strings.HasPrefix(strings.ToLower(trimmed), "<!doctype html"),
+		strings.HasPrefix(strings.ToLower(trimmed), "<html"):
+		return FormatHTML
+
+	case strings.HasPrefix(trimmed, "<"):
+		return FormatXML
+	}
+
+	return FormatText
+}
+
+// isBinary reports if the body cannot be shown as text
+func isBinary(body string) bool {
+	if !utf8.ValidString(body) {
+		return true
+	}
+
+	for _, r := range body {
+		if unicode.IsControl(r) && !unicode.IsSpace(r) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Pretty formats the body for display. Formats that have no pretty printer
+// are returned as is
+func Pretty(format Format, body string) (string, error) {
+	switch format {
+	case FormatJSON:
+		var b bytes.Buffer
+		if err := json.Indent(&b, []byte(body), "", "    "); err != nil {
+			return "", err
+		}
+
+		return b.String(), nil
+
+	case FormatXML:
+		return indentXML(body)
+
+	case FormatGraphQL:
+		return PrettyGraphQL(body), nil
+
+	case FormatBinary:
+		return HexDump(body), nil
+	}
+
+	return body, nil
+}
+
+func indentXML(body string) (string, error) {
+	decoder := xml.NewDecoder(strings.NewReader(body))
+	decoder.Strict = false
+
+	var b bytes.Buffer
+
+	encoder := xml.NewEncoder(&b)
+	encoder.Indent("", "    ")
+
+	for {
+		// raw tokens keep namespace prefixes as they were sent
+		token, err := decoder.RawToken()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return "", err
+		}
+
+		// whitespace between elements is replaced by the indentation
+		if data, ok := token.(xml.CharData); ok && len(bytes.TrimSpace(data)) == 0 {
+			continue
+		}
+
+		if err := encoder.EncodeToken(withPrefixes(xml.CopyToken(token))); err != nil {
+			return "", err
+		}
+	}
+
+	if err := encoder.Flush(); err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
+
+// withPrefixes moves namespace prefixes into the local name so the encoder
+// writes them back as is instead of declaring new namespaces
+func withPrefixes(token xml.Token) xml.Token {
+	prefixed := func(name xml.Name) xml.Name {
+		if name.Space == "" {
+			return name
+		}
+
+		return xml.Name{Local: name.Space + ":" + name.Local}
+	}
+
+	switch t := token.(type) {
+	case xml.StartElement:
+		t.Name = prefixed(t.Name)
+		for i := range t.Attr {
+			t.Attr[i].Name = prefixed(t.Attr[i].Name)
+		}
+
+		return t
+
+	case xml.EndElement:
+		t.Name = prefixed(t.Name)
+		return t
+	}
+
+	return token
+}
+
+// Form parses an application/x-www-form-urlencoded body
+func Form(body string) ([]Pair, error) {
+	return Query(strings.TrimSpace(body))
+}
+
+// HexDump renders the body like hexdump -C
+func HexDump(body string) string {
+	return hex.Dump([]byte(body))
+}
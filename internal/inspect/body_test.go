@@ -0,0 +1,80 @@
+package inspect
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectFormat(t *testing.T) {
+	tt := []struct {
+		name        string
+		contentType string
+		body        string
+		expected    Format
+	}{
+		{name: "json", contentType: "application/json; charset=utf-8", body: `{"a":1}`, expected: FormatJSON},
+		{name: "json suffix", contentType: "application/vnd.api+json", body: `{}`, expected: FormatJSON},
+		{name: "xml", contentType: "text/xml", body: `<a/>`, expected: FormatXML},
+		{name: "atom", contentType: "application/atom+xml", body: `<feed/>`, expected: FormatXML},
+		{name: "html", contentType: "text/html", body: `<p>hi</p>`, expected: FormatHTML},
+		{name: "yaml", contentType: "application/x-yaml", body: "a: 1", expected: FormatYAML},
+		{name: "graphql", contentType: "application/graphql", body: "{ me { id } }", expected: FormatGraphQL},
+		{name: "form", contentType: "application/x-www-form-urlencoded", body: "a=1&b=2", expected: FormatForm},
+		{name: "csv", contentType: "text/csv", body: "a,b", expected: FormatText},
+		{name: "octet stream", contentType: "application/octet-stream", body: "abc", expected: FormatBinary},
+		{name: "binary body", contentType: "application/json", body: "\x1f\x8b\x08\x00", expected: FormatBinary},
+		{name: "sniffed json", body: `[1, 2]`, expected: FormatJSON},
+		{name: "sniffed json from plain text", contentType: "text/plain", body: `{"a":1}`, expected: FormatJSON},
+		{name: "sniffed html", body: `<!DOCTYPE html><html></html>`, expected: FormatHTML},
+		{name: "sniffed xml", body: `<?xml version="1.0"?><a/>`, expected: FormatXML},
+		{name: "sniffed text", body: "hello world", expected: FormatText},
+		{name: "empty body", expected: FormatText},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			h := http.Header{}
+			if v.contentType != "" {
+				h.Set("Content-Type", v.contentType)
+			}
+
+			require.Equal(t, v.expected, DetectFormat(h, v.body))
+		})
+	}
+}
+
+func TestPretty(t *testing.T) {
+	s, err := Pretty(FormatJSON, `{"a":1}`)
+	require.NoError(t, err)
+	require.Equal(t, "{\n    \"a\": 1\n}", s)
+
+	_, err = Pretty(FormatJSON, `{"a":`)
+	require.Error(t, err)
+
+	s, err = Pretty(FormatXML, `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"> <soap:Body><id>1</id></soap:Body></soap:Envelope>`)
+	require.NoError(t, err)
+	require.Equal(t, `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+    <soap:Body>
+        <id>1</id>
+    </soap:Body>
+</soap:Envelope>`, s)
+
+	s, err = Pretty(FormatYAML, "a: 1")
+	require.NoError(t, err)
+	require.Equal(t, "a: 1", s)
+
+	s, err = Pretty(FormatBinary, "sdump\x00")
+	require.NoError(t, err)
+	require.Equal(t, "00000000  73 64 75 6d 70 00                                 |sdump.|\n", s)
+}
+
+func TestForm(t *testing.T) {
+	pairs, err := Form("name=John+Doe&email=john%40example.com\n")
+	require.NoError(t, err)
+	require.Equal(t, []Pair{
+		{Name: "name", Value: "John Doe"},
+		{Name: "email", Value: "john@example.com"},
+	}, pairs)
+}
@@ -0,0 +1,485 @@
+package inspect
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ayinke-llc/sdump"
+)
+
+// GraphQL is a single GraphQL operation sent over http
+type GraphQL struct {
+	Query         string
+	OperationName string
+	// OperationType is query, mutation or subscription. It is empty when
+	// only a persisted query hash was sent
+	OperationType string
+	Variables     json.RawMessage
+	Extensions    json.RawMessage
+
+	// PersistedQueryHash is the sha256 hash of an automatic persisted query
+	PersistedQueryHash string
+}
+
+// Name describes the operation for lists such as "mutation CreateUser"
+func (g GraphQL) Name() string {
+	name := g.OperationName
+	if name == "" {
+		name = "anonymous"
+	}
+
+	if g.OperationType == "" {
+		return name
+	}
+
+	return g.OperationType + " " + name
+}
+
+type graphQLPayload struct {
+	Query         *string         `json:"query"`
+	OperationName string          `json:"operationName"`
+	Variables     json.RawMessage `json:"variables"`
+	Extensions    json.RawMessage `json:"extensions"`
+}
+
+// ParseGraphQL extracts the GraphQL operations of a request. Batched requests
+// return an operation per item. false is returned if the request is not a
+// GraphQL request
+func ParseGraphQL(def sdump.RequestDefinition) ([]GraphQL, bool) {
+	mediaType, _, _ := mime.ParseMediaType(def.Headers.Get("Content-Type"))
+
+	if mediaType == "application/graphql" {
+		return []GraphQL{newGraphQL(graphQLPayload{Query: &def.Body})}, true
+	}
+
+	body := bytes.TrimSpace([]byte(def.Body))
+
+	switch {
+	case len(body) > 0 && body[0] == '{':
+		var payload graphQLPayload
+		if err := json.Unmarshal(body, &payload); err != nil || !payload.isGraphQL() {
+			return nil, false
+		}
+
+		return []GraphQL{newGraphQL(payload)}, true
+
+	case len(body) > 0 && body[0] == '[':
+		var payloads []graphQLPayload
+		if err := json.Unmarshal(body, &payloads); err != nil || len(payloads) == 0 {
+			return nil, false
+		}
+
+		operations := make([]GraphQL, 0, len(payloads))
+		for _, payload := range payloads {
+			if !payload.isGraphQL() {
+				return nil, false
+			}
+
+			operations = append(operations, newGraphQL(payload))
+		}
+
+		return operations, true
+
+	case len(body) == 0 && (def.Method == "" || def.Method == http.MethodGet):
+		// GET requests send the operation in the query string. This is
+		// common for persisted queries so they can be cached
+		values, err := url.ParseQuery(def.Query)
+		if err != nil {
+			return nil, false
+		}
+
+		payload := graphQLPayload{
+			OperationName: values.Get("operationName"),
+			Variables:     json.RawMessage(values.Get("variables")),
+			Extensions:    json.RawMessage(values.Get("extensions")),
+		}
+
+		if values.Has("query") {
+			query := values.Get("query")
+			payload.Query = &query
+		}
+
+		if !payload.isGraphQL() {
+			return nil, false
+		}
+
+		return []GraphQL{newGraphQL(payload)}, true
+	}
+
+	return nil, false
+}
+
+func (p graphQLPayload) isGraphQL() bool {
+	if p.Query != nil {
+		return operationType(*p.Query, "") != ""
+	}
+
+	return persistedQueryHash(p.Extensions) != ""
+}
+
+func newGraphQL(p graphQLPayload) GraphQL {
+	g := GraphQL{
+		OperationName:      p.OperationName,
+		PersistedQueryHash: persistedQueryHash(p.Extensions),
+	}
+
+	if json.Valid(p.Variables) && string(p.Variables) != "null" {
+		g.Variables = p.Variables
+	}
+
+	if json.Valid(p.Extensions) && string(p.Extensions) != "null" {
+		g.Extensions = p.Extensions
+	}
+
+	if p.Query != nil {
+		g.Query = *p.Query
+		g.OperationType = operationType(g.Query, g.OperationName)
+
+		if g.OperationName == "" {
+			g.OperationName = operationName(g.Query)
+		}
+	}
+
+	return g
+}
+
+func persistedQueryHash(extensions json.RawMessage) string {
+	var e struct {
+		PersistedQuery struct {
+			Hash string `json:"sha256Hash"`
+		} `json:"persistedQuery"`
+	}
+
+	if err := json.Unmarshal(extensions, &e); err != nil {
+		return ""
+	}
+
+	return e.PersistedQuery.Hash
+}
+
+// operation is a top level definition of a GraphQL document
+type operation struct {
+	kind string
+	name string
+}
+
+// operations lists the operations of a document. Fragments are skipped
+func operations(query string) []operation {
+	var (
+		ops   []operation
+		depth int
+		kind  string
+	)
+
+	tokens := tokenizeGraphQL(query)
+
+	for i, token := range tokens {
+		switch {
+		case token.kind == graphQLComment || token.kind == graphQLString:
+			continue
+
+		case token.value == "{":
+			if depth == 0 {
+				if kind == "" {
+					// shorthand anonymous query
+					ops = append(ops, operation{kind: "query"})
+				}
+
+				kind = ""
+			}
+
+			depth++
+
+		case token.value == "}":
+			depth--
+
+		case depth == 0 && token.kind == graphQLName && kind == "":
+			switch token.value {
+			case "query", "mutation", "subscription":
+				kind = token.value
+
+				var name string
+				if next := nextGraphQLToken(tokens, i); next != nil && next.kind == graphQLName {
+					name = next.value
+				}
+
+				ops = append(ops, operation{kind: kind, name: name})
+
+			case "fragment":
+				kind = token.value
+			}
+		}
+	}
+
+	return ops
+}
+
+func nextGraphQLToken(tokens []graphQLToken, i int) *graphQLToken {
+	for j := i + 1; j < len(tokens); j++ {
+		if tokens[j].kind != graphQLComment {
+			return &tokens[j]
+		}
+	}
+
+	return nil
+}
+
+// operationType is the type of the named operation or the first operation
+// of the document if name is empty
+func operationType(query, name string) string {
+	for _, op := range operations(query) {
+		if name == "" || op.name == name {
+			return op.kind
+		}
+	}
+
+	return ""
+}
+
+func operationName(query string) string {
+	ops := operations(query)
+	if len(ops) == 0 {
+		return ""
+	}
+
+	return ops[0].name
+}
+
+type graphQLTokenKind int
+
+const (
+	graphQLPunctuator graphQLTokenKind = iota
+	graphQLName
+	graphQLValue
+	graphQLString
+	graphQLComment
+)
+
+type graphQLToken struct {
+	kind  graphQLTokenKind
+	value string
+}
+
+func tokenizeGraphQL(query string) []graphQLToken {
+	var tokens []graphQLToken
+
+	for i := 0; i < len(query); {
+		c := query[i]
+
+		switch {
+		// commas are insignificant in GraphQL just like whitespace
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			i++
+
+		case c == '#':
+			end := strings.IndexAny(query[i:], "\r\n")
+			if end == -1 {
+				end = len(query) - i
+			}
+
+			tokens = append(tokens, graphQLToken{kind: graphQLComment, value: query[i : i+end]})
+			i += end
+
+		case strings.HasPrefix(query[i:], `"""`):
+			end := strings.Index(query[i+3:], `"""`)
+			if end == -1 {
+				end = len(query) - i - 3
+			} else {
+				end += 3
+			}
+
+			tokens = append(tokens, graphQLToken{kind: graphQLString, value: query[i : i+3+end]})
+			i += 3 + end
+
+		case c == '"':
+			j := i + 1
+			for j < len(query) && query[j] != '"' && query[j] != '\n' {
+				if query[j] == '\\' {
+					j++
+				}
+
+				j++
+			}
+
+			if j < len(query) && query[j] == '"' {
+				j++
+			}
+
+			j = min(j, len(query))
+
+			tokens = append(tokens, graphQLToken{kind: graphQLString, value: query[i:j]})
+			i = j
+
+		case strings.HasPrefix(query[i:], "..."):
+			tokens = append(tokens, graphQLToken{kind: graphQLPunctuator, value: "..."})
+			i += 3
+
+		case strings.ContainsRune("{}()[]:!$@=|&", rune(c)):
+			tokens = append(tokens, graphQLToken{kind: graphQLPunctuator, value: string(c)})
+			i++
+
+		default:
+			j := i
+			for j < len(query) && !strings.ContainsRune(" \t\r\n,#\"{}()[]:!$@=|&", rune(query[j])) &&
+				!strings.HasPrefix(query[j:], "...") {
+				j++
+			}
+
+			if j == i {
+				j++
+			}
+
+			kind := graphQLName
+			if c == '-' || (c >= '0' && c <= '9') {
+				kind = graphQLValue
+			}
+
+			tokens = append(tokens, graphQLToken{kind: kind, value: query[i:j]})
+			i = j
+		}
+	}
+
+	return tokens
+}
+
+// PrettyGraphQL pretty prints a GraphQL document. Selection sets are
+// indented with a field per line while arguments stay on the line of their
+// field
+func PrettyGraphQL(query string) string {
+	var (
+		b      strings.Builder
+		indent int
+		parens int
+		prev   *graphQLToken
+	)
+
+	newline := func() {
+		b.WriteString("\n")
+		b.WriteString(strings.Repeat("  ", indent))
+	}
+
+	tokens := tokenizeGraphQL(query)
+
+	for i := range tokens {
+		token := tokens[i]
+
+		switch {
+		case token.kind == graphQLComment:
+			if prev != nil {
+				newline()
+			}
+
+			b.WriteString(token.value)
+
+		case parens > 0 && token.value == "{":
+			// input objects stay on the line of their argument
+			b.WriteString(" {")
+
+		case parens > 0 && token.value == "}":
+			b.WriteString(" }")
+
+		case token.value == "{":
+			switch {
+			case prev == nil:
+			case prev.kind == graphQLComment:
+				newline()
+			default:
+				b.WriteString(" ")
+			}
+
+			b.WriteString("{")
+			indent++
+
+		case token.value == "}":
+			indent = max(indent-1, 0)
+			newline()
+			b.WriteString("}")
+
+			if indent == 0 {
+				b.WriteString("\n")
+			}
+
+		case token.value == "(" || token.value == "[":
+			if token.value == "(" {
+				parens++
+			}
+
+			if prev != nil && prev.value == ":" {
+				b.WriteString(" ")
+			}
+
+			b.WriteString(token.value)
+
+		case token.value == ")" || token.value == "]":
+			if token.value == ")" {
+				parens = max(parens-1, 0)
+			}
+
+			b.WriteString(token.value)
+
+		case token.value == ":" || token.value == "!":
+			b.WriteString(token.value)
+
+		case prev == nil:
+			b.WriteString(token.value)
+
+		case prev.kind == graphQLComment:
+			// comments run until the end of the line
+			newline()
+			b.WriteString(token.value)
+
+		case indent > 0 && parens == 0 && startsSelection(*prev, token):
+			newline()
+			b.WriteString(token.value)
+
+		case indent == 0 && parens == 0 && prev.value == "}":
+			// a blank line between definitions
+			b.WriteString("\n")
+			b.WriteString(token.value)
+
+		case prev.value == "(" || prev.value == "[" || prev.value == "$" ||
+			prev.value == "@" || (prev.value == "..." && token.value != "on"):
+			b.WriteString(token.value)
+
+		case parens > 0 && prev.kind != graphQLPunctuator && token.kind != graphQLPunctuator:
+			// arguments were separated by commas
+			b.WriteString(", ")
+			b.WriteString(token.value)
+
+		case parens > 0 && token.value == "$" && !strings.Contains("(:[=", prev.value):
+			// variable definitions were separated by commas
+			b.WriteString(", ")
+			b.WriteString(token.value)
+
+		default:
+			b.WriteString(" ")
+			b.WriteString(token.value)
+		}
+
+		prev = &tokens[i]
+	}
+
+	return strings.TrimSpace(b.String())
+}
+
+// startsSelection reports if the token starts a new field, spread or inline
+// fragment within a selection set
+func startsSelection(prev, token graphQLToken) bool {
+	if token.kind != graphQLName && token.value != "..." {
+		return false
+	}
+
+	switch prev.value {
+	case ":", "...", "@", "$", "on":
+		return false
+	}
+
+	// directives such as @include are followed by their name
+	return prev.kind == graphQLName || prev.kind == graphQLValue ||
+		prev.kind == graphQLString || prev.kind == graphQLComment ||
+		prev.value == "{" || prev.value == "}" || prev.value == ")" || prev.value == "]"
+}
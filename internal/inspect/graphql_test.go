@@ -0,0 +1,156 @@
+package inspect
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ayinke-llc/sdump"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGraphQL(t *testing.T) {
+	tt := []struct {
+		name        string
+		method      string
+		contentType string
+		body        string
+		query       string
+		isGraphQL   bool
+		expected    []GraphQL
+	}{
+		{
+			name:      "query with variables",
+			method:    http.MethodPost,
+			body:      `{"query":"query GetUser($id: ID!) { user(id: $id) { name } }","variables":{"id":"1"}}`,
+			isGraphQL: true,
+			expected: []GraphQL{
+				{
+					Query:         "query GetUser($id: ID!) { user(id: $id) { name } }",
+					OperationName: "GetUser",
+					OperationType: "query",
+					Variables:     []byte(`{"id":"1"}`),
+				},
+			},
+		},
+		{
+			name:      "operation name picks the operation of the document",
+			method:    http.MethodPost,
+			body:      `{"query":"query A { a } mutation B { b }","operationName":"B","variables":null}`,
+			isGraphQL: true,
+			expected: []GraphQL{
+				{
+					Query:         "query A { a } mutation B { b }",
+					OperationName: "B",
+					OperationType: "mutation",
+				},
+			},
+		},
+		{
+			name:      "shorthand query",
+			method:    http.MethodPost,
+			body:      `{"query":"# comment\n{ me { id } }"}`,
+			isGraphQL: true,
+			expected: []GraphQL{
+				{Query: "# comment\n{ me { id } }", OperationType: "query"},
+			},
+		},
+		{
+			name:      "batched operations",
+			method:    http.MethodPost,
+			body:      `[{"query":"query A { a }"},{"query":"subscription B { b }"}]`,
+			isGraphQL: true,
+			expected: []GraphQL{
+				{Query: "query A { a }", OperationName: "A", OperationType: "query"},
+				{Query: "subscription B { b }", OperationName: "B", OperationType: "subscription"},
+			},
+		},
+		{
+			name:        "application/graphql",
+			method:      http.MethodPost,
+			contentType: "application/graphql",
+			body:        "mutation Logout { logout }",
+			isGraphQL:   true,
+			expected: []GraphQL{
+				{Query: "mutation Logout { logout }", OperationName: "Logout", OperationType: "mutation"},
+			},
+		},
+		{
+			name:      "persisted query over GET",
+			method:    http.MethodGet,
+			query:     `operationName=Feed&extensions={"persistedQuery":{"version":1,"sha256Hash":"ecf4edb46db40b5132295c0291d62fb65d6759a9eedfa4d5d612dd5ec54a6b38"}}`,
+			isGraphQL: true,
+			expected: []GraphQL{
+				{
+					OperationName:      "Feed",
+					Extensions:         []byte(`{"persistedQuery":{"version":1,"sha256Hash":"ecf4edb46db40b5132295c0291d62fb65d6759a9eedfa4d5d612dd5ec54a6b38"}}`),
+					PersistedQueryHash: "ecf4edb46db40b5132295c0291d62fb65d6759a9eedfa4d5d612dd5ec54a6b38",
+				},
+			},
+		},
+		{
+			name:   "json that is not graphql",
+			method: http.MethodPost,
+			body:   `{"query":"select * from users"}`,
+		},
+		{
+			name:   "get without a query",
+			method: http.MethodGet,
+			query:  "page=1",
+		},
+		{
+			name:   "batch with an item that is not graphql",
+			method: http.MethodPost,
+			body:   `[{"query":"query A { a }"},{"name":"sdump"}]`,
+		},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			h := http.Header{}
+			if v.contentType != "" {
+				h.Set("Content-Type", v.contentType)
+			}
+
+			operations, ok := ParseGraphQL(sdump.RequestDefinition{
+				Method:  v.method,
+				Headers: h,
+				Body:    v.body,
+				Query:   v.query,
+			})
+			require.Equal(t, v.isGraphQL, ok)
+			require.Equal(t, v.expected, operations)
+		})
+	}
+}
+
+func TestGraphQL_Name(t *testing.T) {
+	require.Equal(t, "mutation CreateUser", GraphQL{OperationType: "mutation", OperationName: "CreateUser"}.Name())
+	require.Equal(t, "query anonymous", GraphQL{OperationType: "query"}.Name())
+	require.Equal(t, "Feed", GraphQL{OperationName: "Feed"}.Name())
+}
+
+func TestPrettyGraphQL(t *testing.T) {
+	query := "# fetch a user\n" + `query GetUser($id: ID!, $first: Int = 10) { user(id: $id) { id, name ...UserFields ` +
+		`friends(first: $first, filter: {name: "bob"}) @include(if: true) { ... on User { id } } } } ` +
+		`fragment UserFields on User { email }`
+
+	expected := `# fetch a user
+query GetUser($id: ID!, $first: Int = 10) {
+  user(id: $id) {
+    id
+    name
+    ...UserFields
+    friends(first: $first, filter: { name: "bob" }) @include(if: true) {
+      ... on User {
+        id
+      }
+    }
+  }
+}
+
+fragment UserFields on User {
+  email
+}`
+
+	require.Equal(t, expected, PrettyGraphQL(query))
+}
@@ -0,0 +1,242 @@
+// Package inspect breaks a captured request down into the parts shown in
+// the detail view of the TUI
+package inspect
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ayinke-llc/sdump"
+)
+
+// Pair is a single name and value. Repeated names produce multiple pairs
+type Pair struct {
+	Name  string
+	Value string
+}
+
+// Headers returns every header value, sorted by name
+func Headers(h http.Header) []Pair {
+	keys := make([]string, 0, len(h))
+	for key := range h {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	var pairs []Pair
+	for _, key := range keys {
+		for _, value := range h[key] {
+			pairs = append(pairs, Pair{Name: key, Value: value})
+		}
+	}
+
+	return pairs
+}
+
+// Query parses a raw query string. Values are decoded and keep the order
+// they were sent in
+func Query(raw string) ([]Pair, error) {
+	var pairs []Pair
+
+	for raw != "" {
+		var part string
+		part, raw, _ = strings.Cut(raw, "&")
+		if part == "" {
+			continue
+		}
+
+		name, value, _ := strings.Cut(part, "=")
+
+		decodedName, err := url.QueryUnescape(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid query parameter %q: %w", name, err)
+		}
+
+		decodedValue, err := url.QueryUnescape(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for query parameter %q: %w", decodedName, err)
+		}
+
+		pairs = append(pairs, Pair{Name: decodedName, Value: decodedValue})
+	}
+
+	return pairs, nil
+}
+
+// Cookies parses the Cookie headers of a request
+func Cookies(h http.Header) []Pair {
+	req := http.Request{Header: h}
+
+	var pairs []Pair
+	for _, c := range req.Cookies() {
+		pairs = append(pairs, Pair{Name: c.Name, Value: c.Value})
+	}
+
+	return pairs
+}
+
+// Auth is the decoded Authorization header of a request
+type Auth struct {
+	Scheme      string
+	Credentials string
+
+	// Username and Password are set for Basic auth
+	Username string
+	Password string
+
+	// JWT is set when a Bearer token is a JSON web token
+	JWT *JWT
+}
+
+// JWT is a decoded JSON web token. The signature is not verified
+type JWT struct {
+	Header json.RawMessage
+	Claims json.RawMessage
+
+	// Times holds the registered time claims that are present such as
+	// exp, iat and nbf
+	Times map[string]time.Time
+}
+
+var ErrNoAuthorization = errors.New("no Authorization header")
+
+// Authorization decodes the Authorization header. Unknown schemes are
+// returned with only Scheme and Credentials set
+func Authorization(h http.Header) (*Auth, error) {
+	value := strings.TrimSpace(h.Get("Authorization"))
+	if value == "" {
+		return nil, ErrNoAuthorization
+	}
+
+	scheme, credentials, _ := strings.Cut(value, " ")
+
+	auth := &Auth{
+		Scheme:      scheme,
+		Credentials: strings.TrimSpace(credentials),
+	}
+
+	switch strings.ToLower(scheme) {
+	case "basic":
+		b, err := base64.StdEncoding.DecodeString(auth.Credentials)
+		if err != nil {
+			return auth, fmt.Errorf("basic credentials are not valid base64: %w", err)
+		}
+
+		username, password, found := strings.Cut(string(b), ":")
+		if !found {
+			return auth, errors.New("basic credentials are not in the username:password format")
+		}
+
+		auth.Username = username
+		auth.Password = password
+
+	case "bearer":
+		// opaque tokens are fine, only JWTs can be decoded
+		if strings.Count(auth.Credentials, ".") == 2 {
+			jwt, err := DecodeJWT(auth.Credentials)
+			if err != nil {
+				return auth, err
+			}
+
+			auth.JWT = jwt
+		}
+	}
+
+	return auth, nil
+}
+
+// DecodeJWT decodes the header and claims of a JSON web token
+func DecodeJWT(token string) (*JWT, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("a JWT must have three parts")
+	}
+
+	header, err := decodeSegment(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT header: %w", err)
+	}
+
+	claims, err := decodeSegment(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT claims: %w", err)
+	}
+
+	jwt := &JWT{
+		Header: header,
+		Claims: claims,
+		Times:  make(map[string]time.Time),
+	}
+
+	var registered map[string]any
+	if err := json.Unmarshal(claims, &registered); err != nil {
+		return nil, fmt.Errorf("invalid JWT claims: %w", err)
+	}
+
+	for _, name := range []string{"exp", "iat", "nbf", "auth_time"} {
+		if v, ok := registered[name].(float64); ok {
+			jwt.Times[name] = time.Unix(int64(v), 0).UTC()
+		}
+	}
+
+	return jwt, nil
+}
+
+func decodeSegment(s string) (json.RawMessage, error) {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return nil, err
+	}
+
+	if !json.Valid(b) {
+		return nil, errors.New("segment is not JSON")
+	}
+
+	return b, nil
+}
+
+// Raw reconstructs the request as it would look on the wire. target is the
+// url the request was sent to
+func Raw(def sdump.RequestDefinition, target *url.URL) string {
+	var b bytes.Buffer
+
+	method := def.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	path := "/"
+	host := ""
+	if target != nil {
+		path = target.EscapedPath()
+		host = target.Host
+	}
+
+	if def.Query != "" {
+		path += "?" + def.Query
+	}
+
+	fmt.Fprintf(&b, "%s %s HTTP/1.1\r\n", method, path)
+
+	if host != "" && def.Headers.Get("Host") == "" {
+		fmt.Fprintf(&b, "Host: %s\r\n", host)
+	}
+
+	for _, header := range Headers(def.Headers) {
+		fmt.Fprintf(&b, "%s: %s\r\n", header.Name, header.Value)
+	}
+
+	b.WriteString("\r\n")
+	b.WriteString(def.Body)
+
+	return b.String()
+}
@@ -0,0 +1,180 @@
+package inspect
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// NodeKind is the JSON type of a node
+type NodeKind int
+
+const (
+	NodeObject NodeKind = iota
+	NodeArray
+	NodeString
+	NodeNumber
+	NodeBool
+	NodeNull
+)
+
+// Node is a value of a JSON document. Object keys keep the order they were
+// sent in
+type Node struct {
+	Kind NodeKind
+	// Key is the object key or array index of the node. It is empty for the
+	// root
+	Key string
+	// Path is the JSONPath of the node such as $.data.items[0].id
+	Path  string
+	Depth int
+
+	// Value is the JSON literal of scalars
+	Value string
+	// Raw is the node as it was sent
+	Raw json.RawMessage
+
+	Parent   *Node
+	Children []*Node
+}
+
+// IsContainer reports if the node is an object or array
+func (n *Node) IsContainer() bool {
+	return n.Kind == NodeObject || n.Kind == NodeArray
+}
+
+// Pretty is the indented JSON of the node and its children
+func (n *Node) Pretty() string {
+	var b bytes.Buffer
+	if err := json.Indent(&b, n.Raw, "", "    "); err != nil {
+		return string(n.Raw)
+	}
+
+	return b.String()
+}
+
+// Walk calls fn for the node and its children depth first. Children are
+// skipped when fn returns false
+func (n *Node) Walk(fn func(*Node) bool) {
+	if !fn(n) {
+		return
+	}
+
+	for _, child := range n.Children {
+		child.Walk(fn)
+	}
+}
+
+// ParseTree parses a JSON document into a tree
+func ParseTree(body string) (*Node, error) {
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber()
+
+	root, err := parseNode(decoder, []byte(body), nil, "", "$", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return nil, errors.New("unexpected data after the JSON document")
+	}
+
+	return root, nil
+}
+
+func parseNode(decoder *json.Decoder, body []byte, parent *Node,
+	key, path string, depth int,
+) (*Node, error) {
+	start := decoder.InputOffset()
+
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	n := &Node{
+		Key:    key,
+		Path:   path,
+		Depth:  depth,
+		Parent: parent,
+	}
+
+	switch t := token.(type) {
+	case json.Delim:
+		if t == '{' {
+			n.Kind = NodeObject
+		} else {
+			n.Kind = NodeArray
+		}
+
+		for i := 0; decoder.More(); i++ {
+			var child *Node
+
+			if n.Kind == NodeObject {
+				keyToken, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+
+				name, _ := keyToken.(string)
+				child, err = parseNode(decoder, body, n, name, path+PathKey(name), depth+1)
+				if err != nil {
+					return nil, err
+				}
+			} else {
+				child, err = parseNode(decoder, body, n, strconv.Itoa(i),
+					fmt.Sprintf("%s[%d]", path, i), depth+1)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			n.Children = append(n.Children, child)
+		}
+
+		// the closing delimiter
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+
+	case string:
+		n.Kind = NodeString
+
+	case json.Number:
+		n.Kind = NodeNumber
+
+	case bool:
+		n.Kind = NodeBool
+
+	default:
+		n.Kind = NodeNull
+	}
+
+	// the offset before the token includes the separator that came before
+	// the value
+	n.Raw = bytes.TrimLeft(body[start:decoder.InputOffset()], " \t\r\n,:")
+
+	if !n.IsContainer() {
+		n.Value = string(n.Raw)
+	}
+
+	return n, nil
+}
+
+var identifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// PathKey is the JSONPath segment of an object key. Keys that are not valid
+// identifiers use the bracket notation
+func PathKey(key string) string {
+	if identifier.MatchString(key) {
+		return "." + key
+	}
+
+	b, _ := json.Marshal(key)
+	return "[" + string(b) + "]"
+}
@@ -0,0 +1,69 @@
+package inspect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTree(t *testing.T) {
+	root, err := ParseTree(`{"type": "invoice.paid", "data": {"object": {"lines": [{"id": 1}, {"id": 2.5}]}}, "content-type": null, "ok": true}`)
+	require.NoError(t, err)
+
+	require.Equal(t, NodeObject, root.Kind)
+	require.Equal(t, "$", root.Path)
+	require.Len(t, root.Children, 4)
+
+	var paths []string
+	root.Walk(func(n *Node) bool {
+		paths = append(paths, n.Path)
+		return true
+	})
+
+	// keys keep the order they were sent in
+	require.Equal(t, []string{
+		"$",
+		"$.type",
+		"$.data",
+		"$.data.object",
+		"$.data.object.lines",
+		"$.data.object.lines[0]",
+		"$.data.object.lines[0].id",
+		"$.data.object.lines[1]",
+		"$.data.object.lines[1].id",
+		`$["content-type"]`,
+		"$.ok",
+	}, paths)
+
+	require.Equal(t, `"invoice.paid"`, root.Children[0].Value)
+	require.Equal(t, NodeNull, root.Children[2].Kind)
+	require.Equal(t, NodeBool, root.Children[3].Kind)
+
+	lines := root.Children[1].Children[0].Children[0]
+	require.Equal(t, NodeArray, lines.Kind)
+	require.Equal(t, `[{"id": 1}, {"id": 2.5}]`, string(lines.Raw))
+	require.Equal(t, "1", lines.Children[1].Key)
+	require.Equal(t, "2.5", lines.Children[1].Children[0].Value)
+	require.Equal(t, lines, lines.Children[0].Parent)
+
+	require.Equal(t, "{\n    \"id\": 1\n}", lines.Children[0].Pretty())
+
+	var skipped []string
+	root.Walk(func(n *Node) bool {
+		skipped = append(skipped, n.Path)
+		return n.Depth < 1
+	})
+	require.Len(t, skipped, 5)
+}
+
+func TestParseTree_Invalid(t *testing.T) {
+	for _, body := range []string{"", "hello", `{"a": }`, `{"a": 1} {}`} {
+		_, err := ParseTree(body)
+		require.Error(t, err, body)
+	}
+
+	root, err := ParseTree(` "text" `)
+	require.NoError(t, err)
+	require.Equal(t, NodeString, root.Kind)
+	require.Equal(t, `"text"`, root.Value)
+}
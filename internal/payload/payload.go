@@ -0,0 +1,174 @@
+// Package payload turns the body of a captured request into something that
+// can be displayed and searched. Compressed bodies are decompressed and
+// protobuf, MessagePack and CBOR payloads are converted to JSON. The bytes
+// that were sent are always kept alongside
+package payload
+
+import (
+	"bytes"
+	"encoding/base64"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ayinke-llc/sdump"
+	"github.com/ayinke-llc/sdump/internal/codec"
+	"github.com/ayinke-llc/sdump/internal/decompress"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type Decoder struct {
+	logger *logrus.Entry
+
+	// maxDecodedSize limits how large a compressed body can grow when
+	// decoded
+	maxDecodedSize int64
+
+	mu sync.Mutex
+	// registries caches the parsed protobuf descriptor set of endpoints so
+	// it is not parsed again for every request
+	registries map[uuid.UUID]cachedRegistry
+}
+
+// cachedRegistry is a parsed descriptor set. uploadedAt tells apart sets
+// replaced through another server instance
+type cachedRegistry struct {
+	registry   *codec.Registry
+	err        error
+	uploadedAt time.Time
+}
+
+func NewDecoder(logger *logrus.Entry, maxDecodedSize int64) *Decoder {
+	return &Decoder{
+		logger:         logger,
+		maxDecodedSize: maxDecodedSize,
+		registries:     make(map[uuid.UUID]cachedRegistry),
+	}
+}
+
+// Forget drops the cached descriptor set of an endpoint. It is called when
+// a new one is uploaded
+func (d *Decoder) Forget(endpointID uuid.UUID) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.registries, endpointID)
+}
+
+// registry returns the parsed descriptor set of the endpoint. A set is only
+// parsed once per upload
+func (d *Decoder) registry(endpoint *sdump.URLEndpoint) (*codec.Registry, error) {
+	schema := endpoint.Metadata.Protobuf
+
+	d.mu.Lock()
+	cached, ok := d.registries[endpoint.ID]
+	d.mu.Unlock()
+
+	if ok && cached.uploadedAt.Equal(schema.UploadedAt) {
+		return cached.registry, cached.err
+	}
+
+	registry, err := codec.NewRegistry(schema.DescriptorSet, schema.DefaultMessage)
+	if err != nil {
+		d.logger.WithError(err).
+			WithField("reference", endpoint.Reference).
+			Error("could not load protobuf descriptor set")
+	}
+
+	d.mu.Lock()
+	d.registries[endpoint.ID] = cachedRegistry{
+		registry:   registry,
+		err:        err,
+		uploadedAt: schema.UploadedAt,
+	}
+	d.mu.Unlock()
+
+	return registry, err
+}
+
+// Decode sets the body of def from body, the bytes that were sent to
+// endpoint. Any decoded view def already carries is replaced
+func (d *Decoder) Decode(endpoint *sdump.URLEndpoint,
+	def *sdump.RequestDefinition,
+	body []byte,
+) {
+	def.Body = string(body)
+	def.Encoding = nil
+	def.Binary = nil
+
+	if decompress.IsEncoded(def.Headers.Get("Content-Encoding")) {
+		d.decompress(def, body)
+	}
+
+	if def.Encoding == nil || def.IsDecoded() {
+		d.decodeBinary(endpoint, def)
+	}
+}
+
+// decompress replaces the body of a compressed request with its decoded
+// version. If the body cannot be decoded, only the bytes that were sent are
+// kept as compressed data is not valid text
+func (d *Decoder) decompress(def *sdump.RequestDefinition, body []byte) {
+	def.Encoding = &sdump.BodyEncoding{
+		ContentEncoding: def.Headers.Get("Content-Encoding"),
+		Original:        base64.StdEncoding.EncodeToString(body),
+	}
+
+	decoded, err := decompress.Decode(def.Encoding.ContentEncoding, body, d.maxDecodedSize)
+	if err != nil {
+		def.Body = ""
+		def.Encoding.Error = err.Error()
+		return
+	}
+
+	def.Body = string(decoded)
+	def.Encoding.DecodedSize = int64(len(decoded))
+}
+
+// decodeBinary converts protobuf, msgpack and cbor payloads to JSON. Bodies
+// that are not text are kept base64 encoded as they cannot be stored as a
+// string
+func (d *Decoder) decodeBinary(endpoint *sdump.URLEndpoint, def *sdump.RequestDefinition) {
+	format, messageType := codec.Detect(def.Headers)
+
+	body := []byte(def.Body)
+
+	if format == "" && isText(body) {
+		return
+	}
+
+	def.Body = ""
+	def.Binary = &sdump.BinaryBody{
+		Format:      string(format),
+		MessageType: messageType,
+		Original:    base64.StdEncoding.EncodeToString(body),
+	}
+
+	if format == "" {
+		return
+	}
+
+	var registry *codec.Registry
+
+	if format == codec.Protobuf && endpoint.Metadata.Protobuf != nil {
+		// the error is logged once per upload. Payloads are then decoded
+		// as if no descriptor set was uploaded
+		registry, _ = d.registry(endpoint)
+	}
+
+	decoded, messageType, err := codec.ToJSON(format, messageType, body, registry)
+	def.Binary.MessageType = messageType
+	if err != nil {
+		def.Binary.Error = err.Error()
+		return
+	}
+
+	def.Body = string(decoded)
+}
+
+// isText reports if the body can be stored as a string. Invalid utf8 would
+// be mangled when encoded as JSON and postgres cannot store null bytes
+func isText(b []byte) bool {
+	return utf8.Valid(b) && bytes.IndexByte(b, 0) == -1
+}
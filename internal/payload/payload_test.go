@@ -0,0 +1,84 @@
+package payload
+
+import (
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ayinke-llc/sdump"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func testDecoder() *Decoder {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	return NewDecoder(logrus.NewEntry(logger), 1024)
+}
+
+func TestDecoder_Decode_Protobuf(t *testing.T) {
+	descriptorSet, err := proto.Marshal(&descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{
+			protodesc.ToFileDescriptorProto(timestamppb.File_google_protobuf_timestamp_proto),
+		},
+	})
+	require.NoError(t, err)
+
+	body, err := proto.Marshal(timestamppb.New(time.Date(2024, time.January, 20, 14, 30, 0, 0, time.UTC)))
+	require.NoError(t, err)
+
+	endpoint := &sdump.URLEndpoint{
+		ID: uuid.New(),
+		Metadata: sdump.URLEndpointMetadata{
+			Protobuf: &sdump.ProtobufSchema{
+				DescriptorSet:  descriptorSet,
+				DefaultMessage: "google.protobuf.Timestamp",
+				UploadedAt:     time.Now(),
+			},
+		},
+	}
+
+	decoder := testDecoder()
+
+	decode := func() sdump.RequestDefinition {
+		def := sdump.RequestDefinition{
+			Headers: http.Header{"Content-Type": []string{"application/x-protobuf"}},
+		}
+
+		decoder.Decode(endpoint, &def, body)
+		return def
+	}
+
+	def := decode()
+	require.Empty(t, def.Binary.Error)
+	require.Equal(t, `"2024-01-20T14:30:00Z"`, def.Body)
+
+	registry := decoder.registries[endpoint.ID].registry
+	require.NotNil(t, registry)
+
+	decode()
+	require.Same(t, registry, decoder.registries[endpoint.ID].registry,
+		"the descriptor set was parsed again")
+
+	// an upload through another instance only shows in the upload time
+	endpoint.Metadata.Protobuf.UploadedAt = time.Now().Add(time.Minute)
+
+	decode()
+	require.NotSame(t, registry, decoder.registries[endpoint.ID].registry)
+
+	registry = decoder.registries[endpoint.ID].registry
+
+	decoder.Forget(endpoint.ID)
+	require.NotContains(t, decoder.registries, endpoint.ID)
+
+	def = decode()
+	require.Equal(t, `"2024-01-20T14:30:00Z"`, def.Body)
+	require.NotSame(t, registry, decoder.registries[endpoint.ID].registry)
+}
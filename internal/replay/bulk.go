@@ -0,0 +1,223 @@
+package replay
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ayinke-llc/sdump"
+)
+
+type BulkOptions struct {
+	Options
+
+	// Concurrency is the number of requests that can be in flight at once
+	Concurrency int
+
+	// Rate caps how many requests are started per second. Zero means no limit
+	Rate float64
+
+	// PreserveTiming waits between requests for as long as the gap between
+	// their original arrival times
+	PreserveTiming bool
+
+	// Speed scales the preserved gaps. 2 replays twice as fast as the
+	// original traffic
+	Speed float64
+}
+
+// Result is the outcome of replaying a single captured request
+type Result struct {
+	Ingest sdump.IngestHTTPRequest
+	Replay *sdump.Replay
+	Err    error
+}
+
+// DoAll replays the captured requests in the order they are provided. fn, if
+// not nil, is called for every result as soon as it is available. It is
+// called from the workers so it must be safe for concurrent use
+func (c *Client) DoAll(ctx context.Context,
+	ingests []sdump.IngestHTTPRequest,
+	opts *BulkOptions,
+	fn func(Result),
+) *Report {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	speed := opts.Speed
+	if speed <= 0 {
+		speed = 1
+	}
+
+	var ticker *time.Ticker
+	if opts.Rate > 0 {
+		ticker = time.NewTicker(time.Duration(float64(time.Second) / opts.Rate))
+		defer ticker.Stop()
+	}
+
+	jobs := make(chan sdump.IngestHTTPRequest)
+	report := newReport()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for ingest := range jobs {
+				replay, err := c.Do(ctx, ingest.Request, &opts.Options)
+				if replay != nil {
+					replay.IngestID = ingest.ID
+				}
+
+				result := Result{Ingest: ingest, Replay: replay, Err: err}
+
+				mu.Lock()
+				report.add(result)
+				mu.Unlock()
+
+				// fn can be slow, like storing the replay, and must not hold
+				// up the other workers
+				if fn != nil {
+					fn(result)
+				}
+			}
+		}()
+	}
+
+	start := time.Now()
+
+dispatch:
+	for i, ingest := range ingests {
+		if opts.PreserveTiming && i > 0 {
+			gap := ingest.CreatedAt.Sub(ingests[i-1].CreatedAt)
+			if gap > 0 {
+				select {
+				case <-ctx.Done():
+					break dispatch
+				case <-time.After(time.Duration(float64(gap) / speed)):
+				}
+			}
+		}
+
+		if ticker != nil && i > 0 {
+			select {
+			case <-ctx.Done():
+				break dispatch
+			case <-ticker.C:
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			break dispatch
+		case jobs <- ingest:
+		}
+	}
+
+	close(jobs)
+	wg.Wait()
+
+	report.Duration = time.Since(start)
+	return report
+}
+
+// Report summarises a bulk replay
+type Report struct {
+	Total       int
+	Failed      int
+	StatusCodes map[int]int
+	Duration    time.Duration
+
+	latencies []time.Duration
+}
+
+func newReport() *Report {
+	return &Report{
+		StatusCodes: make(map[int]int),
+	}
+}
+
+func (r *Report) add(result Result) {
+	r.Total++
+
+	if result.Err != nil || result.Replay == nil || result.Replay.Error != "" {
+		r.Failed++
+		return
+	}
+
+	r.StatusCodes[result.Replay.Response.StatusCode]++
+	r.latencies = append(r.latencies, result.Replay.Response.Duration)
+}
+
+// Percentile returns the latency at or under which p percent of the
+// successful requests completed
+func (r *Report) Percentile(p float64) time.Duration {
+	if len(r.latencies) == 0 {
+		return 0
+	}
+
+	latencies := make([]time.Duration, len(r.latencies))
+	copy(latencies, r.latencies)
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	idx := int(float64(len(latencies)-1) * p / 100)
+	return latencies[idx]
+}
+
+func (r *Report) Average() time.Duration {
+	if len(r.latencies) == 0 {
+		return 0
+	}
+
+	var total time.Duration
+	for _, latency := range r.latencies {
+		total += latency
+	}
+
+	return total / time.Duration(len(r.latencies))
+}
+
+// Write prints the report as a human readable table
+func (r *Report) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Requests:\t%d\n", r.Total)
+	fmt.Fprintf(tw, "Failed:\t%d\n", r.Failed)
+	fmt.Fprintf(tw, "Duration:\t%s\n", r.Duration.Round(time.Millisecond))
+	fmt.Fprintln(tw)
+
+	codes := make([]int, 0, len(r.StatusCodes))
+	for code := range r.StatusCodes {
+		codes = append(codes, code)
+	}
+
+	sort.Ints(codes)
+
+	fmt.Fprintln(tw, "Status code\tCount")
+	for _, code := range codes {
+		fmt.Fprintf(tw, "%d %s\t%d\n", code, http.StatusText(code), r.StatusCodes[code])
+	}
+
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "Latency\t")
+	fmt.Fprintf(tw, "min\t%s\n", r.Percentile(0).Round(time.Microsecond))
+	fmt.Fprintf(tw, "avg\t%s\n", r.Average().Round(time.Microsecond))
+	fmt.Fprintf(tw, "p50\t%s\n", r.Percentile(50).Round(time.Microsecond))
+	fmt.Fprintf(tw, "p90\t%s\n", r.Percentile(90).Round(time.Microsecond))
+	fmt.Fprintf(tw, "p99\t%s\n", r.Percentile(99).Round(time.Microsecond))
+	fmt.Fprintf(tw, "max\t%s\n", r.Percentile(100).Round(time.Microsecond))
+
+	return tw.Flush()
+}
@@ -0,0 +1,141 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ayinke-llc/sdump"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_DoAll(t *testing.T) {
+	var received atomic.Int64
+
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Add(1)
+
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer target.Close()
+
+	now := time.Now()
+
+	ingests := []sdump.IngestHTTPRequest{
+		{Request: sdump.RequestDefinition{Method: http.MethodPost}, CreatedAt: now},
+		{Request: sdump.RequestDefinition{Method: http.MethodPost}, CreatedAt: now.Add(time.Millisecond)},
+		{Request: sdump.RequestDefinition{Method: http.MethodDelete}, CreatedAt: now.Add(2 * time.Millisecond)},
+	}
+
+	var results atomic.Int64
+
+	report := New(time.Second, true).DoAll(context.Background(), ingests, &BulkOptions{
+		Options: Options{
+			Target: target.URL,
+		},
+		Concurrency:    2,
+		PreserveTiming: true,
+	}, func(_ Result) {
+		results.Add(1)
+	})
+
+	require.Equal(t, int64(3), received.Load())
+	require.Equal(t, int64(3), results.Load())
+	require.Equal(t, 3, report.Total)
+	require.Equal(t, 0, report.Failed)
+	require.Equal(t, 2, report.StatusCodes[http.StatusOK])
+	require.Equal(t, 1, report.StatusCodes[http.StatusNotFound])
+
+	b := new(bytes.Buffer)
+	require.NoError(t, report.Write(b))
+	require.Contains(t, b.String(), "404 Not Found")
+}
+
+func TestClient_DoAll_PrivateNetworksBlocked(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer target.Close()
+
+	report := New(time.Second, false).DoAll(context.Background(), []sdump.IngestHTTPRequest{
+		{Request: sdump.RequestDefinition{Method: http.MethodPost}},
+	}, &BulkOptions{
+		Options: Options{
+			Target: target.URL,
+		},
+	}, nil)
+
+	require.Equal(t, 1, report.Total)
+	require.Equal(t, 1, report.Failed)
+}
+
+func TestReport_Percentile(t *testing.T) {
+	r := newReport()
+
+	for i := 1; i <= 100; i++ {
+		r.add(Result{Replay: &sdump.Replay{
+			Response: sdump.ResponseDefinition{
+				StatusCode: http.StatusOK,
+				Duration:   time.Duration(i) * time.Millisecond,
+			},
+		}})
+	}
+
+	require.Equal(t, time.Millisecond, r.Percentile(0))
+	require.Equal(t, 50*time.Millisecond, r.Percentile(50))
+	require.Equal(t, 100*time.Millisecond, r.Percentile(100))
+	require.Equal(t, 50500*time.Microsecond, r.Average())
+}
+
+func TestClient_DoAll_DoesNotSerializeCallbacks(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer target.Close()
+
+	ingests := []sdump.IngestHTTPRequest{
+		{Request: sdump.RequestDefinition{Method: http.MethodPost}},
+		{Request: sdump.RequestDefinition{Method: http.MethodPost}},
+	}
+
+	// every callback waits for the other one. If they were called one at a
+	// time, both would time out
+	var wg sync.WaitGroup
+	wg.Add(len(ingests))
+
+	var timedOut atomic.Int64
+
+	report := New(time.Second, true).DoAll(context.Background(), ingests, &BulkOptions{
+		Options: Options{
+			Target: target.URL,
+		},
+		Concurrency: 2,
+	}, func(_ Result) {
+		wg.Done()
+
+		done := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(2 * time.Second):
+			timedOut.Add(1)
+		}
+	})
+
+	require.Equal(t, 2, report.Total)
+	require.Equal(t, int64(0), timedOut.Load())
+}
@@ -0,0 +1,167 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ayinke-llc/sdump"
+)
+
+const defaultMaxResponseBodySize = 1024 * 1024
+
+var ErrPrivateNetwork = errors.New("replaying to private or loopback addresses is not allowed")
+
+type Options struct {
+	// Target is the url the captured request should be sent to
+	Target string
+
+	// Headers overrides the captured headers. A header with an empty
+	// value removes it from the request entirely
+	Headers map[string]string
+}
+
+type Client struct {
+	inner               *http.Client
+	maxResponseBodySize int64
+}
+
+// New creates a client that sends captured requests to arbitrary targets.
+// Unless allowPrivateNetworks is true, the client refuses to connect to
+// loopback, link local or private addresses so a hosted instance cannot be
+// used to reach its own network
+func New(timeout time.Duration, allowPrivateNetworks bool) *Client {
+	dialer := &net.Dialer{
+		Timeout: 30 * time.Second,
+	}
+
+	if !allowPrivateNetworks {
+		dialer.Control = func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+
+			ip := net.ParseIP(host)
+			if ip == nil || isPrivateIP(ip) {
+				return ErrPrivateNetwork
+			}
+
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	transport.Proxy = nil
+
+	return &Client{
+		inner: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+			CheckRedirect: func(_ *http.Request, _ []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		maxResponseBodySize: defaultMaxResponseBodySize,
+	}
+}
+
+func isPrivateIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsUnspecified()
+}
+
+// ValidateTarget makes sure the target is an absolute http(s) url
+func ValidateTarget(target string) error {
+	u, err := url.Parse(target)
+	if err != nil {
+		return err
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errors.New("target url must use http or https")
+	}
+
+	if u.Host == "" {
+		return errors.New("target url must contain a host")
+	}
+
+	return nil
+}
+
+// Do sends the captured request to the target and returns a replay record
+// describing what was sent and what came back.
+// An error is only returned if the request could not be built. Failures to
+// reach the target are recorded on the returned replay instead
+func (c *Client) Do(ctx context.Context,
+	def sdump.RequestDefinition,
+	opts *Options,
+) (*sdump.Replay, error) {
+	if err := ValidateTarget(opts.Target); err != nil {
+		return nil, err
+	}
+
+	req, err := def.HTTPRequest(ctx, opts.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range opts.Headers {
+		if strings.TrimSpace(value) == "" {
+			req.Header.Del(key)
+			continue
+		}
+
+		req.Header.Set(key, value)
+	}
+
+	sent := def
+	sent.Headers = req.Header.Clone()
+
+	replay := &sdump.Replay{
+		TargetURL: opts.Target,
+		Request:   sent,
+	}
+
+	start := time.Now()
+
+	resp, err := c.inner.Do(req)
+	if err != nil {
+		replay.Error = err.Error()
+		replay.Response.Duration = time.Since(start)
+		return replay, nil
+	}
+
+	defer resp.Body.Close()
+
+	// one more byte than is kept tells if the body was cut
+	body, err := io.ReadAll(io.LimitReader(resp.Body, c.maxResponseBodySize+1))
+	if err != nil {
+		replay.Error = fmt.Sprintf("could not read response body: %v", err)
+	}
+
+	truncated := int64(len(body)) > c.maxResponseBodySize
+	if truncated {
+		body = body[:c.maxResponseBodySize]
+	}
+
+	replay.Response = sdump.ResponseDefinition{
+		StatusCode: resp.StatusCode,
+		Headers:    resp.Header,
+		Body:       string(body),
+		Size:       int64(len(body)),
+		Duration:   time.Since(start),
+		Truncated:  truncated,
+	}
+
+	return replay, nil
+}
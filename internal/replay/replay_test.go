@@ -0,0 +1,55 @@
+package replay
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ayinke-llc/sdump"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Do_TruncatesLargeResponses(t *testing.T) {
+	tt := []struct {
+		name      string
+		body      string
+		expected  string
+		truncated bool
+	}{
+		{
+			name:     "body at the limit",
+			body:     strings.Repeat("a", 8),
+			expected: strings.Repeat("a", 8),
+		},
+		{
+			name:      "body over the limit",
+			body:      strings.Repeat("a", 9),
+			expected:  strings.Repeat("a", 8),
+			truncated: true,
+		},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte(v.body))
+			}))
+			defer target.Close()
+
+			client := New(time.Second, true)
+			client.maxResponseBodySize = 8
+
+			replay, err := client.Do(context.Background(), sdump.RequestDefinition{
+				Method: http.MethodGet,
+			}, &Options{Target: target.URL})
+			require.NoError(t, err)
+
+			require.Equal(t, v.expected, replay.Response.Body)
+			require.Equal(t, int64(len(v.expected)), replay.Response.Size)
+			require.Equal(t, v.truncated, replay.Response.Truncated)
+		})
+	}
+}
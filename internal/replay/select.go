@@ -0,0 +1,55 @@
+package replay
+
+import (
+	"context"
+
+	"github.com/ayinke-llc/sdump"
+	"github.com/ayinke-llc/sdump/internal/filter"
+)
+
+const selectPageSize = 100
+
+// Select lists the captured requests of opts that match query, oldest first.
+// The store narrows the results with what it can filter on and every page is
+// then matched the same way the TUI search does. opts.Limit caps the number
+// of matches rather than the number of requests scanned
+func Select(ctx context.Context,
+	ingestRepo sdump.IngestRepository,
+	opts sdump.ListIngestOptions,
+	query *filter.Query,
+) ([]sdump.IngestHTTPRequest, error) {
+	limit := opts.Limit
+
+	opts.Method = query.Method()
+	opts.Text = query.Text()
+	opts.Body = query.Body()
+	opts.Limit = selectPageSize
+	opts.NewestFirst = false
+
+	var matches []sdump.IngestHTTPRequest
+
+	for {
+		page, err := ingestRepo.List(ctx, &opts)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, ingest := range page {
+			opts.After = &sdump.IngestCursor{CreatedAt: ingest.CreatedAt, ID: ingest.ID}
+
+			if !query.Match(filter.Request{ID: ingest.ID.String(), Request: ingest.Request}) {
+				continue
+			}
+
+			matches = append(matches, ingest)
+
+			if len(matches) == limit {
+				return matches, nil
+			}
+		}
+
+		if len(page) < selectPageSize {
+			return matches, nil
+		}
+	}
+}
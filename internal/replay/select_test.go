@@ -0,0 +1,73 @@
+package replay
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ayinke-llc/sdump"
+	"github.com/ayinke-llc/sdump/internal/filter"
+	"github.com/ayinke-llc/sdump/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSelect(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now()
+
+	var ingests []sdump.IngestHTTPRequest
+	for i := 0; i < selectPageSize+10; i++ {
+		headers := http.Header{}
+		if i%2 == 0 {
+			headers.Set("X-Event", "order.created")
+		}
+
+		ingests = append(ingests, sdump.IngestHTTPRequest{
+			ID:        uuid.New(),
+			CreatedAt: now.Add(time.Duration(i) * time.Second),
+			Request: sdump.RequestDefinition{
+				Method:  http.MethodPost,
+				Headers: headers,
+			},
+		})
+	}
+
+	urlID := uuid.New()
+
+	ingestRepo := mocks.NewMockIngestRepository(ctrl)
+	ingestRepo.EXPECT().List(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, opts *sdump.ListIngestOptions) ([]sdump.IngestHTTPRequest, error) {
+			require.Equal(t, urlID, opts.UrlID)
+			require.Equal(t, http.MethodPost, opts.Method)
+			require.Equal(t, []string{"order.created"}, opts.Text)
+			require.False(t, opts.NewestFirst)
+
+			if opts.After == nil {
+				return ingests[:selectPageSize], nil
+			}
+
+			require.Equal(t, ingests[selectPageSize-1].ID, opts.After.ID)
+			return ingests[selectPageSize:], nil
+		}).Times(2)
+
+	query, err := filter.Parse("method:post order.created")
+	require.NoError(t, err)
+
+	matches, err := Select(context.Background(), ingestRepo, sdump.ListIngestOptions{
+		UrlID: urlID,
+		Limit: 52,
+	}, query)
+	require.NoError(t, err)
+
+	require.Len(t, matches, 52)
+	for _, ingest := range matches {
+		require.Equal(t, "order.created", ingest.Request.Headers.Get("X-Event"))
+	}
+
+	require.Equal(t, ingests[102].ID, matches[51].ID)
+}
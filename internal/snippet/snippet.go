@@ -0,0 +1,302 @@
+// Package snippet turns a captured request into ready to run code in
+// various languages and tools
+package snippet
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ayinke-llc/sdump"
+)
+
+type Format string
+
+const (
+	Curl   Format = "curl"
+	HTTPie Format = "httpie"
+	Go     Format = "go"
+	Fetch  Format = "fetch"
+)
+
+// Formats lists every supported format in the order they should be offered
+var Formats = []Format{Curl, HTTPie, Go, Fetch}
+
+func (f Format) String() string {
+	switch f {
+	case Curl:
+		return "cURL"
+	case HTTPie:
+		return "HTTPie"
+	case Go:
+		return "Go (net/http)"
+	case Fetch:
+		return "JavaScript (fetch)"
+	default:
+		return string(f)
+	}
+}
+
+// Generate builds a snippet that sends the captured request to target. The
+// captured query string is merged into target and headers that only make
+// sense for the original connection are left out
+func Generate(format Format, def sdump.RequestDefinition, target string) (string, error) {
+	// snippets carry the body as text so compressed requests are shown
+	// decoded
+	def = def.WithoutEncoding()
+
+	req, err := def.HTTPRequest(context.Background(), target)
+	if err != nil {
+		return "", err
+	}
+
+	body, err := bodyOf(def)
+	if err != nil {
+		return "", err
+	}
+
+	switch format {
+	case Curl:
+		return curl(req, body), nil
+	case HTTPie:
+		return httpie(req, body), nil
+	case Go:
+		return goSnippet(req, body), nil
+	case Fetch:
+		return fetch(req, body)
+	default:
+		return "", fmt.Errorf("unsupported snippet format %q", format)
+	}
+}
+
+// payload is the body a snippet sends. Binary bodies cannot be written as
+// text so they are carried base64 encoded and decoded when sent
+type payload struct {
+	text   string
+	base64 string
+}
+
+func (p payload) isEmpty() bool { return p.text == "" && p.base64 == "" }
+
+// bodyOf returns the body of the request as it should be sent. Protobuf,
+// MessagePack and CBOR payloads are sent as they were captured instead of
+// the JSON they were decoded to. So are compressed bodies that could not be
+// decoded
+func bodyOf(def sdump.RequestDefinition) (payload, error) {
+	if def.Binary == nil && def.Encoding == nil {
+		return payload{text: def.Body}, nil
+	}
+
+	b, err := def.OriginalBody()
+	if err != nil {
+		return payload{}, err
+	}
+
+	return payload{base64: base64.StdEncoding.EncodeToString(b)}, nil
+}
+
+// decodePipe writes the base64 encoded body to the standard input of the
+// command that follows it
+func decodePipe(p payload) string {
+	return "printf '%s' " + shellQuote(p.base64) + " | base64 --decode | "
+}
+
+type header struct {
+	name, value string
+}
+
+// sortedHeaders keeps snippets stable between runs
+func sortedHeaders(h http.Header) []header {
+	keys := make([]string, 0, len(h))
+	for key := range h {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	var headers []header
+	for _, key := range keys {
+		for _, value := range h[key] {
+			headers = append(headers, header{name: key, value: value})
+		}
+	}
+
+	return headers
+}
+
+// shellQuote wraps s in single quotes so it is passed to a POSIX shell as is
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func curl(req *http.Request, body payload) string {
+	command := "curl "
+	if body.base64 != "" {
+		command = decodePipe(body) + command
+	}
+
+	if req.Method != http.MethodGet || !body.isEmpty() {
+		command += "-X " + req.Method + " "
+	}
+
+	parts := []string{command + shellQuote(req.URL.String())}
+
+	for _, h := range sortedHeaders(req.Header) {
+		parts = append(parts, "-H "+shellQuote(h.name+": "+h.value))
+	}
+
+	if body.text != "" {
+		parts = append(parts, "--data-raw "+shellQuote(body.text))
+	}
+
+	if body.base64 != "" {
+		parts = append(parts, "--data-binary @-")
+	}
+
+	return strings.Join(parts, " \\\n  ")
+}
+
+func httpie(req *http.Request, body payload) string {
+	command := "http "
+	if body.base64 != "" {
+		command = decodePipe(body) + command
+	}
+
+	parts := []string{command + req.Method + " " + shellQuote(req.URL.String())}
+
+	for _, h := range sortedHeaders(req.Header) {
+		parts = append(parts, shellQuote(h.name+":"+h.value))
+	}
+
+	if body.text != "" {
+		parts = append(parts, "--raw "+shellQuote(body.text))
+	}
+
+	return strings.Join(parts, " \\\n  ")
+}
+
+// goString returns a Go string literal for s, preferring raw strings so
+// JSON bodies stay readable
+func goString(s string) string {
+	if strconv.CanBackquote(strings.ReplaceAll(s, "\n", "")) {
+		return "`" + s + "`"
+	}
+
+	return strconv.Quote(s)
+}
+
+func goSnippet(req *http.Request, body payload) string {
+	var b strings.Builder
+
+	bodyArg := "nil"
+	if body.text != "" {
+		fmt.Fprintf(&b, "body := strings.NewReader(%s)\n\n", goString(body.text))
+		bodyArg = "body"
+	}
+
+	if body.base64 != "" {
+		fmt.Fprintf(&b, "body, err := base64.StdEncoding.DecodeString(%s)\n", strconv.Quote(body.base64))
+		b.WriteString("if err != nil {\n\tlog.Fatal(err)\n}\n\n")
+		bodyArg = "bytes.NewReader(body)"
+	}
+
+	fmt.Fprintf(&b, "req, err := http.NewRequest(%s, %s, %s)\n",
+		strconv.Quote(req.Method), strconv.Quote(req.URL.String()), bodyArg)
+	b.WriteString("if err != nil {\n\tlog.Fatal(err)\n}\n")
+
+	headers := sortedHeaders(req.Header)
+	if len(headers) > 0 {
+		b.WriteString("\n")
+	}
+
+	for _, h := range headers {
+		fmt.Fprintf(&b, "req.Header.Add(%s, %s)\n", strconv.Quote(h.name), strconv.Quote(h.value))
+	}
+
+	b.WriteString("\nresp, err := http.DefaultClient.Do(req)\n")
+	b.WriteString("if err != nil {\n\tlog.Fatal(err)\n}\n\n")
+	b.WriteString("defer resp.Body.Close()\n")
+
+	return b.String()
+}
+
+// jsString returns a JavaScript string literal for s
+func jsString(s string) (string, error) {
+	var b strings.Builder
+
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+
+	if err := enc.Encode(s); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+func fetch(req *http.Request, body payload) (string, error) {
+	var b strings.Builder
+
+	u, err := jsString(req.URL.String())
+	if err != nil {
+		return "", err
+	}
+
+	method, err := jsString(req.Method)
+	if err != nil {
+		return "", err
+	}
+
+	fmt.Fprintf(&b, "const response = await fetch(%s, {\n", u)
+	fmt.Fprintf(&b, "  method: %s,\n", method)
+
+	keys := make([]string, 0, len(req.Header))
+	for key := range req.Header {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	if len(keys) > 0 {
+		b.WriteString("  headers: {\n")
+
+		for _, key := range keys {
+			name, err := jsString(key)
+			if err != nil {
+				return "", err
+			}
+
+			// the Headers API joins repeated headers the same way
+			value, err := jsString(strings.Join(req.Header[key], ", "))
+			if err != nil {
+				return "", err
+			}
+
+			fmt.Fprintf(&b, "    %s: %s,\n", name, value)
+		}
+
+		b.WriteString("  },\n")
+	}
+
+	if body.text != "" {
+		s, err := jsString(body.text)
+		if err != nil {
+			return "", err
+		}
+
+		fmt.Fprintf(&b, "  body: %s,\n", s)
+	}
+
+	if body.base64 != "" {
+		fmt.Fprintf(&b, "  body: Uint8Array.from(atob(%q), (c) => c.charCodeAt(0)),\n", body.base64)
+	}
+
+	b.WriteString("});\n")
+
+	return b.String(), nil
+}
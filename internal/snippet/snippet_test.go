@@ -0,0 +1,105 @@
+package snippet
+
+import (
+	"encoding/base64"
+	"net/http"
+	"testing"
+
+	"github.com/ayinke-llc/sdump"
+	"github.com/sebdah/goldie/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	def := sdump.RequestDefinition{
+		Method: http.MethodPost,
+		Body:   "{\n    \"message\": \"it's here\"\n}",
+		Query:  "page=1",
+		Headers: http.Header{
+			"Content-Type":   []string{"application/json"},
+			"Content-Length": []string{"30"},
+			"X-Event":        []string{"push", "ping"},
+		},
+	}
+
+	g := goldie.New(t, goldie.WithFixtureDir("./testdata"))
+
+	for _, format := range Formats {
+		t.Run(string(format), func(t *testing.T) {
+			s, err := Generate(format, def, "http://localhost:3000/webhooks")
+			require.NoError(t, err)
+
+			g.Assert(t, t.Name(), []byte(s))
+		})
+	}
+}
+
+func TestGenerate_BinaryBody(t *testing.T) {
+	// a msgpack map of {"name": "sdump"}
+	msgpack := []byte{0x81, 0xa4, 'n', 'a', 'm', 'e', 0xa5, 's', 'd', 'u', 'm', 'p'}
+
+	def := sdump.RequestDefinition{
+		Method: http.MethodPost,
+		Body:   `{"name":"sdump"}`,
+		Headers: http.Header{
+			"Content-Type": []string{"application/msgpack"},
+		},
+		Binary: &sdump.BinaryBody{
+			Format:   "msgpack",
+			Original: base64.StdEncoding.EncodeToString(msgpack),
+		},
+	}
+
+	g := goldie.New(t, goldie.WithFixtureDir("./testdata"))
+
+	for _, format := range Formats {
+		t.Run(string(format), func(t *testing.T) {
+			s, err := Generate(format, def, "http://localhost:3000/webhooks")
+			require.NoError(t, err)
+			require.NotContains(t, s, def.Body)
+
+			g.Assert(t, t.Name(), []byte(s))
+		})
+	}
+}
+
+func TestGenerate_UndecodableBody(t *testing.T) {
+	compressed := []byte{0x1f, 0x8b, 0x00}
+
+	def := sdump.RequestDefinition{
+		Method: http.MethodPost,
+		Headers: http.Header{
+			"Content-Encoding": []string{"gzip"},
+		},
+		Encoding: &sdump.BodyEncoding{
+			ContentEncoding: "gzip",
+			Original:        base64.StdEncoding.EncodeToString(compressed),
+			Error:           "unexpected EOF",
+		},
+	}
+
+	s, err := Generate(Curl, def, "http://localhost:3000/webhooks")
+	require.NoError(t, err)
+	require.Contains(t, s, base64.StdEncoding.EncodeToString(compressed))
+	require.Contains(t, s, "-H 'Content-Encoding: gzip'")
+	require.Contains(t, s, "--data-binary @-")
+}
+
+func TestGenerate_WithoutBody(t *testing.T) {
+	def := sdump.RequestDefinition{
+		Method: http.MethodGet,
+	}
+
+	s, err := Generate(Curl, def, "http://localhost:3000/webhooks")
+	require.NoError(t, err)
+	require.Equal(t, "curl 'http://localhost:3000/webhooks'", s)
+
+	s, err = Generate(Go, def, "http://localhost:3000/webhooks")
+	require.NoError(t, err)
+	require.Contains(t, s, `http.NewRequest("GET", "http://localhost:3000/webhooks", nil)`)
+}
+
+func TestGenerate_UnsupportedFormat(t *testing.T) {
+	_, err := Generate(Format("wget"), sdump.RequestDefinition{}, "http://localhost:3000")
+	require.Error(t, err)
+}
@@ -0,0 +1,180 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ayinke-llc/sdump/internal/client"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// annotateForm edits the note and tags of a captured request
+type annotateForm struct {
+	note    textinput.Model
+	tags    textinput.Model
+	item    item
+	visible bool
+}
+
+func newAnnotateForm() annotateForm {
+	note := textinput.New()
+	note.Prompt = "Note: "
+	note.Placeholder = "reproduces bug #431"
+	note.CharLimit = 2000
+
+	tags := textinput.New()
+	tags.Prompt = "Tags: "
+	tags.Placeholder = "stripe, regression"
+
+	return annotateForm{
+		note: note,
+		tags: tags,
+	}
+}
+
+func (f *annotateForm) open(i item) tea.Cmd {
+	f.item = i
+	f.visible = true
+
+	f.note.SetValue(i.Note)
+	f.note.CursorEnd()
+	f.tags.SetValue(strings.Join(i.Tags, ", "))
+	f.tags.CursorEnd()
+
+	f.tags.Blur()
+	return f.note.Focus()
+}
+
+func (f *annotateForm) close() {
+	f.visible = false
+	f.note.Blur()
+	f.tags.Blur()
+}
+
+func (f *annotateForm) toggleFocus() tea.Cmd {
+	if f.note.Focused() {
+		f.note.Blur()
+		return f.tags.Focus()
+	}
+
+	f.tags.Blur()
+	return f.note.Focus()
+}
+
+func (f annotateForm) update(msg tea.Msg) (annotateForm, tea.Cmd) {
+	var noteCmd, tagsCmd tea.Cmd
+
+	f.note, noteCmd = f.note.Update(msg)
+	f.tags, tagsCmd = f.tags.Update(msg)
+
+	return f, tea.Batch(noteCmd, tagsCmd)
+}
+
+func (f annotateForm) annotation() *client.Annotation {
+	var tags []string
+	for _, tag := range strings.Split(f.tags.Value(), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	return &client.Annotation{
+		Starred: f.item.Starred,
+		Note:    strings.TrimSpace(f.note.Value()),
+		Tags:    tags,
+	}
+}
+
+func (f annotateForm) view(st styles) string {
+	return st.overlay.Render(lipgloss.JoinVertical(lipgloss.Left,
+		st.boldenString("Annotate request "+f.item.ID, false),
+		f.note.View(),
+		f.tags.View(),
+		st.makeString("tab to switch fields, enter to save, esc to cancel. Annotated requests are never pruned", true),
+	))
+}
+
+func (m model) updateAnnotateForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+
+	case tea.KeyEsc:
+		m.annotateForm.close()
+		return m, nil
+
+	case tea.KeyTab, tea.KeyShiftTab:
+		return m, m.annotateForm.toggleFocus()
+
+	case tea.KeyEnter:
+		m.annotateForm.close()
+		m.status = "Saving annotation..."
+		return m, m.annotate(m.annotateForm.item.ID, m.annotateForm.annotation())
+	}
+
+	var cmd tea.Cmd
+	m.annotateForm, cmd = m.annotateForm.update(msg)
+	return m, cmd
+}
+
+// toggleStar stars or unstars the selected request keeping its note and
+// tags
+func (m model) toggleStar() (tea.Model, tea.Cmd) {
+	selectedItem, ok := m.requestList.SelectedItem().(item)
+	if !ok {
+		return m, nil
+	}
+
+	return m, m.annotate(selectedItem.ID, &client.Annotation{
+		Starred: !selectedItem.Starred,
+		Note:    selectedItem.Note,
+		Tags:    selectedItem.Tags,
+	})
+}
+
+func (m model) annotate(ingestID string, annotation *client.Annotation) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		ingest, err := m.apiClient.Annotate(ctx, ingestID, annotation)
+		return AnnotationMsg{ingest: ingest, err: err}
+	}
+}
+
+func (m *model) applyAnnotation(msg AnnotationMsg) {
+	if msg.err != nil {
+		m.status = fmt.Sprintf("Could not save annotation: %v", msg.err)
+		return
+	}
+
+	annotated := func(i *item) {
+		i.Starred = msg.ingest.Starred
+		i.Note = msg.ingest.Note
+		i.Tags = msg.ingest.Tags
+	}
+
+	id := msg.ingest.ID.String()
+
+	for i := range m.search.items {
+		if m.search.items[i].ID == id {
+			annotated(&m.search.items[i])
+		}
+	}
+
+	for i, listItem := range m.requestList.Items() {
+		if current, ok := listItem.(item); ok && current.ID == id {
+			annotated(&current)
+			_ = m.requestList.SetItem(i, current)
+		}
+	}
+
+	m.status = "Saved annotation"
+	if msg.ingest.Starred {
+		m.status = "Starred request. It will not be pruned"
+	}
+}
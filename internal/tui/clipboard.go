@@ -0,0 +1,200 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/aymanbagabas/go-osc52/v2"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var ErrClipboardUnsupported = errors.New("terminal does not support OSC 52 clipboard access")
+
+// Output serialises writes to the terminal. The renderer writes each frame
+// in a single call, so guarding both the renderer and the clipboard with
+// the same lock keeps escape sequences from landing in the middle of a frame
+type Output struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewOutput(w io.Writer) *Output {
+	return &Output{w: w}
+}
+
+func (o *Output) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.w.Write(p)
+}
+
+// Clipboard sets the clipboard of the user's terminal with an OSC 52 escape
+// sequence. Unlike a system clipboard library, this works over ssh since
+// the sequence travels to the client with the rest of the output
+type Clipboard struct {
+	out  io.Writer
+	term string
+	// tmux and screen swallow escape sequences they do not know unless they
+	// are wrapped to be passed through to the terminal outside
+	tmux   bool
+	screen bool
+}
+
+// NewClipboard writes to out. environ is the environment of the client.
+// TERM is used to skip terminals that are known to not support OSC 52 and
+// TMUX or STY to pass the sequence through tmux or screen
+func NewClipboard(out io.Writer, environ []string) *Clipboard {
+	c := &Clipboard{out: out}
+
+	for _, v := range environ {
+		key, value, ok := strings.Cut(v, "=")
+		if !ok {
+			continue
+		}
+
+		switch key {
+		case "TERM":
+			c.term = value
+		case "TMUX":
+			c.tmux = value != ""
+		case "STY":
+			c.screen = value != ""
+		}
+	}
+
+	return c
+}
+
+func (c *Clipboard) Copy(text string) error {
+	if !supportsOSC52(c.term) {
+		return ErrClipboardUnsupported
+	}
+
+	seq := osc52.New(text)
+
+	switch {
+	case c.tmux:
+		seq = seq.Tmux()
+	case c.screen:
+		seq = seq.Screen()
+	}
+
+	if _, err := seq.WriteTo(c.out); err != nil {
+		return fmt.Errorf("could not write to your terminal: %w", err)
+	}
+
+	return nil
+}
+
+// supportsOSC52 cannot be exact since terminals do not advertise OSC 52.
+// Most modern terminals support it so only the ones that definitely do not
+// are ruled out. Terminals that silently ignore the sequence are handled by
+// letting the user view what was copied
+func supportsOSC52(term string) bool {
+	switch term {
+	case "", "dumb", "linux", "vt100", "vt102", "vt220":
+		return false
+	}
+
+	return !strings.HasPrefix(term, "cons")
+}
+
+// clipboardFallback shows copied text for terminals without OSC 52 support
+// so it can be selected and copied by hand
+type clipboardFallback struct {
+	what string
+	text string
+	// err is why the text could not be copied. It is nil when the text is
+	// shown on request
+	err     error
+	visible bool
+	view    viewport.Model
+}
+
+func newClipboardFallback() clipboardFallback {
+	return clipboardFallback{
+		view: viewport.New(0, 0),
+	}
+}
+
+func (c *clipboardFallback) open(width, height int) {
+	c.visible = true
+	c.view.Width = width
+	c.view.Height = height
+	c.view.SetContent(c.text)
+	c.view.GotoTop()
+}
+
+func (c *clipboardFallback) close() {
+	c.visible = false
+}
+
+// render shows the copied text. closeKey is the key that closes it besides
+// esc
+func (c clipboardFallback) render(st styles, reason, closeKey string) string {
+	title := fmt.Sprintf("Copied %s", c.what)
+	if c.err != nil {
+		title = fmt.Sprintf("Could not copy %s", c.what)
+	}
+
+	return st.overlay.Render(lipgloss.JoinVertical(lipgloss.Left,
+		st.boldenString(title, false),
+		st.makeString(reason, true),
+		"",
+		c.view.View(),
+		"",
+		st.makeString(fmt.Sprintf("up/down to scroll, esc or %s to close", closeKey), true),
+	))
+}
+
+// copyToClipboard copies text to the user's terminal. what describes the
+// text in status messages
+func (m *model) copyToClipboard(what, text string) {
+	m.copied.what = what
+	m.copied.text = text
+	m.copied.err = m.clipboard.Copy(text)
+
+	if m.copied.err != nil {
+		m.status = fmt.Sprintf("Could not copy %s: %v", what, m.copied.err)
+		m.copied.open(m.width-6, m.height/2)
+		return
+	}
+
+	m.status = fmt.Sprintf("Copied %s to your clipboard. Nothing to paste? Press %s to view it",
+		what, m.keys.ShowCopied.Help().Key)
+}
+
+func (m model) updateClipboardFallback(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Quit):
+		return m, tea.Quit
+
+	case msg.Type == tea.KeyEsc, key.Matches(msg, m.keys.ShowCopied):
+		m.copied.close()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.copied.view, cmd = m.copied.view.Update(msg)
+	return m, cmd
+}
+
+func (m model) clipboardFallbackView() string {
+	reason := "Your terminal may not support copying over ssh. Select the text below to copy it"
+
+	switch {
+	case errors.Is(m.copied.err, ErrClipboardUnsupported):
+		reason = "Your terminal does not support copying over ssh. Select the text below to copy it"
+	case m.copied.err != nil:
+		reason = fmt.Sprintf("%v. Select the text below to copy it", m.copied.err)
+	}
+
+	return m.copied.render(m.styles, reason, m.keys.ShowCopied.Help().Key)
+}
@@ -0,0 +1,587 @@
+package tui
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ayinke-llc/sdump"
+	"github.com/ayinke-llc/sdump/internal/client"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	composerMethod = iota
+	composerURL
+	composerHeaders
+	composerBody
+	composerFieldCount
+)
+
+// maxVisibleVariants limits how many previously sent variants are listed
+// under the response pane
+const maxVisibleVariants = 5
+
+// composer lets a captured request be edited before it is sent. Every send
+// is stored server side as a replay of the original capture, so variants
+// can be browsed and compared
+type composer struct {
+	ingest      string
+	visible     bool
+	sending     bool
+	colorscheme string
+	styles      styles
+	keys        keyMap
+
+	method  textinput.Model
+	url     textinput.Model
+	headers textarea.Model
+	body    textarea.Model
+	focus   int
+
+	// original is the body exactly as it was loaded. The textarea replaces
+	// tabs and carriage returns so an unedited body, which still reads as
+	// loaded, is sent as original to keep webhook signatures valid
+	original string
+	loaded   string
+
+	// binary and encoding hold the captured bytes of protobuf, MessagePack,
+	// CBOR and undecodable compressed bodies. The body field only shows
+	// them as text so they can be sent unedited, never edited
+	binary   *sdump.BinaryBody
+	encoding *sdump.BodyEncoding
+
+	response viewport.Model
+
+	// variants are previous sends of this capture, newest first
+	variants []sdump.Replay
+	selected int
+	err      error
+}
+
+func newComposer() composer {
+	method := textinput.New()
+	method.Prompt = "Method: "
+	method.CharLimit = 10
+
+	u := textinput.New()
+	u.Prompt = "URL:    "
+	u.Placeholder = "http://localhost:3000/webhooks"
+
+	headers := textarea.New()
+	headers.Placeholder = "Content-Type: application/json"
+	headers.ShowLineNumbers = false
+	headers.CharLimit = 0
+
+	body := textarea.New()
+	body.Placeholder = "Request body"
+	body.CharLimit = 0
+
+	return composer{
+		method:   method,
+		url:      u,
+		headers:  headers,
+		body:     body,
+		response: viewport.New(0, 0),
+		selected: -1,
+	}
+}
+
+func (c *composer) setSize(width, height int) {
+	paneWidth := width/2 - 4
+	if paneWidth < 20 {
+		paneWidth = 20
+	}
+
+	c.method.Width = paneWidth - len(c.method.Prompt)
+	c.url.Width = paneWidth - len(c.url.Prompt)
+
+	c.headers.SetWidth(paneWidth)
+	c.headers.SetHeight(6)
+
+	bodyHeight := height - 22
+	if bodyHeight < 5 {
+		bodyHeight = 5
+	}
+
+	c.body.SetWidth(paneWidth)
+	c.body.SetHeight(bodyHeight)
+
+	c.response.Width = paneWidth
+	c.response.Height = bodyHeight + 6
+}
+
+// open loads the selected capture into the form. target is the last url
+// requests were replayed to, if any
+func (c *composer) open(i item, target string, st styles, colorscheme string) tea.Cmd {
+	c.ingest = i.ID
+	c.styles = st
+	c.colorscheme = colorscheme
+	c.visible = true
+	c.sending = false
+	c.variants = nil
+	c.selected = -1
+	c.err = nil
+	c.response.SetContent(c.styles.makeString(
+		fmt.Sprintf("Press %s to send the request", c.keys.ComposerSend.Help().Key), true))
+
+	// compressed bodies are edited decoded so they are sent that way too
+	def := i.Request.WithoutEncoding()
+
+	var u string
+	if target != "" {
+		u = requestURL(def, target)
+	}
+
+	c.method.SetValue(def.Method)
+	c.url.SetValue(u)
+	c.headers.SetValue(formatHeaders(def.Headers))
+	c.setBody(def)
+
+	if c.binary != nil || c.encoding != nil {
+		c.response.SetContent(c.styles.makeString(fmt.Sprintf(
+			"Press %s to send the request. The body is sent as the captured bytes so it cannot be edited",
+			c.keys.ComposerSend.Help().Key), true))
+	}
+
+	return c.setFocus(composerURL)
+}
+
+func (c *composer) setBody(def sdump.RequestDefinition) {
+	c.body.SetValue(def.Body)
+	c.original = def.Body
+	c.loaded = c.body.Value()
+	c.binary = def.Binary
+	c.encoding = def.Encoding
+}
+
+// prettyPrintBody indents a JSON body. It is never done on load as it
+// changes the bytes that are sent
+func (c *composer) prettyPrintBody() {
+	body, err := prettyPrintJSON(c.body.Value())
+	if err != nil {
+		c.err = errors.New("the body is not valid JSON")
+		return
+	}
+
+	c.body.SetValue(body)
+	c.err = nil
+}
+
+func (c *composer) close() {
+	c.visible = false
+	c.method.Blur()
+	c.url.Blur()
+	c.headers.Blur()
+	c.body.Blur()
+}
+
+func (c *composer) setFocus(field int) tea.Cmd {
+	c.focus = (field + composerFieldCount) % composerFieldCount
+
+	c.method.Blur()
+	c.url.Blur()
+	c.headers.Blur()
+	c.body.Blur()
+
+	switch c.focus {
+	case composerMethod:
+		return c.method.Focus()
+	case composerURL:
+		return c.url.Focus()
+	case composerHeaders:
+		return c.headers.Focus()
+	default:
+		return c.body.Focus()
+	}
+}
+
+func (c composer) update(msg tea.Msg) (composer, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch c.focus {
+	case composerMethod:
+		c.method, cmd = c.method.Update(msg)
+	case composerURL:
+		c.url, cmd = c.url.Update(msg)
+	case composerHeaders:
+		c.headers, cmd = c.headers.Update(msg)
+	default:
+		c.body, cmd = c.body.Update(msg)
+	}
+
+	return c, cmd
+}
+
+// request builds the edited request out of the form fields
+func (c composer) request() (*sdump.RequestDefinition, string, error) {
+	method := strings.ToUpper(strings.TrimSpace(c.method.Value()))
+	if method == "" {
+		return nil, "", errors.New("please provide a http method")
+	}
+
+	target := strings.TrimSpace(c.url.Value())
+	if target == "" {
+		return nil, "", errors.New("please provide a url")
+	}
+
+	headers, err := parseHeaderLines(c.headers.Value())
+	if err != nil {
+		return nil, "", err
+	}
+
+	body := c.body.Value()
+	edited := body != c.loaded
+	if !edited {
+		body = c.original
+	}
+
+	def := &sdump.RequestDefinition{
+		Method:  method,
+		Headers: headers,
+		Body:    body,
+		Size:    int64(len(body)),
+	}
+
+	if c.binary == nil && c.encoding == nil {
+		return def, target, nil
+	}
+
+	if edited {
+		return nil, "", errors.New("binary bodies are sent exactly as captured and cannot be edited. Undo the changes to the body to send it")
+	}
+
+	def.Binary = c.binary
+	def.Encoding = c.encoding
+
+	original, err := def.OriginalBody()
+	if err != nil {
+		return nil, "", err
+	}
+
+	def.Size = int64(len(original))
+
+	return def, target, nil
+}
+
+// showVariant loads a previously sent variant into the form and response
+// pane
+func (c *composer) showVariant(idx int) {
+	if idx < 0 || idx >= len(c.variants) {
+		return
+	}
+
+	c.selected = idx
+	variant := c.variants[idx]
+	def := variant.Request.WithoutEncoding()
+
+	c.method.SetValue(def.Method)
+	c.url.SetValue(variantURL(variant))
+	c.headers.SetValue(formatHeaders(def.Headers))
+	c.setBody(def)
+
+	c.response.SetContent(renderReplayResponse(c.styles, variant, c.colorscheme))
+	c.response.GotoTop()
+}
+
+// hint lists the keys of the composer
+func (c composer) hint() string {
+	hints := []struct {
+		keys []key.Binding
+		desc string
+	}{
+		{[]key.Binding{c.keys.ComposerSend}, "send"},
+		{[]key.Binding{c.keys.ComposerFormat}, "format JSON body"},
+		{[]key.Binding{c.keys.ComposerNextField, c.keys.ComposerPreviousField}, "switch field"},
+		{[]key.Binding{c.keys.ComposerPreviousVariant, c.keys.ComposerNextVariant}, "browse sent variants"},
+		{[]key.Binding{c.keys.ComposerScrollUp, c.keys.ComposerScrollDown}, "scroll response"},
+		{[]key.Binding{c.keys.ComposerClose}, "close"},
+	}
+
+	parts := make([]string, 0, len(hints))
+	for _, hint := range hints {
+		var keys []string
+		for _, binding := range hint.keys {
+			if binding.Enabled() {
+				keys = append(keys, binding.Help().Key)
+			}
+		}
+
+		if len(keys) > 0 {
+			parts = append(parts, strings.Join(keys, "/")+" "+hint.desc)
+		}
+	}
+
+	return strings.Join(parts, " • ")
+}
+
+func (c composer) view() string {
+	fieldStyle := c.styles.pane
+	focusedStyle := c.styles.focusedPane
+
+	styleFor := func(field int) lipgloss.Style {
+		if c.focus == field {
+			return focusedStyle
+		}
+		return fieldStyle
+	}
+
+	form := lipgloss.JoinVertical(lipgloss.Left,
+		c.styles.boldenString("Compose request from "+c.ingest, false),
+		styleFor(composerMethod).Render(c.method.View()),
+		styleFor(composerURL).Render(c.url.View()),
+		c.styles.makeString("Headers", true),
+		styleFor(composerHeaders).Render(c.headers.View()),
+		c.styles.makeString("Body", true),
+		styleFor(composerBody).Render(c.body.View()),
+	)
+
+	status := c.styles.makeString(c.hint(), true)
+
+	if c.sending {
+		status = c.styles.makeString("Sending request...", false)
+	}
+
+	if c.err != nil {
+		status = c.styles.errorStyle.Render(c.err.Error())
+	}
+
+	response := lipgloss.JoinVertical(lipgloss.Left,
+		c.styles.boldenString("Response", false),
+		fieldStyle.Render(c.response.View()),
+		c.variantsView(),
+	)
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		lipgloss.JoinHorizontal(lipgloss.Top,
+			lipgloss.NewStyle().Margin(0, 2).Render(form),
+			lipgloss.NewStyle().Margin(0, 2).Render(response)),
+		status)
+}
+
+func (c composer) variantsView() string {
+	if len(c.variants) == 0 {
+		return c.styles.makeString("No variants have been sent yet", true)
+	}
+
+	lines := []string{c.styles.boldenString(fmt.Sprintf("Sent variants (%d)", len(c.variants)), false)}
+
+	start := 0
+	if c.selected >= maxVisibleVariants {
+		start = c.selected - maxVisibleVariants + 1
+	}
+
+	for i := start; i < len(c.variants) && i < start+maxVisibleVariants; i++ {
+		line := variantSummary(c.variants[i])
+		if i == c.selected {
+			lines = append(lines, c.styles.key.Render("> "+line))
+			continue
+		}
+
+		lines = append(lines, c.styles.makeString("  "+line, true))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+func variantSummary(r sdump.Replay) string {
+	status := fmt.Sprintf("%d", r.Response.StatusCode)
+	if r.Error != "" {
+		status = "ERR"
+	}
+
+	return fmt.Sprintf("%s  %-6s %s  %s  %s",
+		r.CreatedAt.Format("15:04:05"), r.Request.Method, status,
+		r.Response.Duration.Round(time.Millisecond), r.TargetURL)
+}
+
+// variantURL returns the full url a variant was sent to, including the query
+// string of the request
+func variantURL(r sdump.Replay) string {
+	return requestURL(r.Request, r.TargetURL)
+}
+
+// requestURL is target with the query string of the request merged in the
+// same way as when the request is replayed
+func requestURL(def sdump.RequestDefinition, target string) string {
+	req, err := def.HTTPRequest(context.Background(), target)
+	if err != nil {
+		return target
+	}
+
+	return req.URL.String()
+}
+
+func renderReplayResponse(st styles, r sdump.Replay, colorscheme string) string {
+	if r.Error != "" {
+		return st.errorStyle.Render(r.Error)
+	}
+
+	var b strings.Builder
+
+	b.WriteString(st.boldenString(fmt.Sprintf("%d %s", r.Response.StatusCode,
+		http.StatusText(r.Response.StatusCode)), false))
+	b.WriteString(st.makeString(fmt.Sprintf("  %s  %s",
+		r.Response.Duration.Round(time.Millisecond),
+		responseSize(r.Response)), true))
+	b.WriteString("\n\n")
+
+	for _, line := range strings.Split(formatHeaders(r.Response.Headers), "\n") {
+		if line == "" {
+			continue
+		}
+
+		b.WriteString(st.makeString(line, true))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+
+	body, err := prettyPrintJSON(r.Response.Body)
+	if err != nil {
+		body = r.Response.Body
+	}
+
+	buf := new(bytes.Buffer)
+	if err := highlightCode(buf, body, colorscheme); err != nil {
+		buf.Reset()
+		buf.WriteString(body)
+	}
+
+	b.WriteString(buf.String())
+
+	return b.String()
+}
+
+// formatHeaders renders headers as "Key: Value" lines sorted by key. Headers
+// with multiple values are repeated once per value
+func formatHeaders(headers http.Header) string {
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	var lines []string
+	for _, key := range keys {
+		for _, value := range headers[key] {
+			lines = append(lines, fmt.Sprintf("%s: %s", key, value))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// parseHeaderLines is the inverse of formatHeaders
+func parseHeaderLines(s string) (http.Header, error) {
+	headers := make(http.Header)
+
+	for _, line := range strings.Split(s, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		key, value, found := strings.Cut(line, ":")
+		if !found || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("invalid header %q. Use the Key: Value format", strings.TrimSpace(line))
+		}
+
+		headers.Add(strings.TrimSpace(key), strings.TrimSpace(value))
+	}
+
+	return headers, nil
+}
+
+func (m model) send() tea.Cmd {
+	def, target, err := m.composer.request()
+	if err != nil {
+		return func() tea.Msg { return ComposerMsg{err: err} }
+	}
+
+	ingestID := m.composer.ingest
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		replay, err := m.apiClient.Replay(ctx, ingestID, &client.ReplayOptions{
+			TargetURL: target,
+			Request:   def,
+		})
+
+		return ComposerMsg{replay: replay, err: err}
+	}
+}
+
+func (m model) fetchVariants(ingestID string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		replays, err := m.apiClient.Replays(ctx, ingestID)
+		return ComposerHistoryMsg{ingest: ingestID, replays: replays, err: err}
+	}
+}
+
+func (m model) updateComposer(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	keys := m.keys
+
+	switch {
+	case key.Matches(msg, keys.Quit):
+		return m, tea.Quit
+
+	case key.Matches(msg, keys.ComposerClose):
+		m.composer.close()
+		return m, nil
+
+	case key.Matches(msg, keys.ComposerNextField):
+		return m, m.composer.setFocus(m.composer.focus + 1)
+
+	case key.Matches(msg, keys.ComposerPreviousField):
+		return m, m.composer.setFocus(m.composer.focus - 1)
+
+	case key.Matches(msg, keys.ComposerSend):
+		if m.composer.sending {
+			return m, nil
+		}
+
+		m.composer.sending = true
+		m.composer.err = nil
+		return m, m.send()
+
+	case key.Matches(msg, keys.ComposerFormat):
+		m.composer.prettyPrintBody()
+		return m, nil
+
+	case key.Matches(msg, keys.ComposerPreviousVariant):
+		m.composer.showVariant(m.composer.selected - 1)
+		return m, nil
+
+	case key.Matches(msg, keys.ComposerNextVariant):
+		m.composer.showVariant(m.composer.selected + 1)
+		return m, nil
+
+	case key.Matches(msg, keys.ComposerScrollUp):
+		m.composer.response.ViewUp()
+		return m, nil
+
+	case key.Matches(msg, keys.ComposerScrollDown):
+		m.composer.response.ViewDown()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.composer, cmd = m.composer.update(msg)
+	return m, cmd
+}
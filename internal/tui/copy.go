@@ -0,0 +1,131 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ayinke-llc/sdump/internal/snippet"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// copyMenu generates a ready to run snippet of the selected request and
+// copies it to the clipboard
+type copyMenu struct {
+	item     item
+	target   textinput.Model
+	selected int
+	visible  bool
+}
+
+func newCopyMenu() copyMenu {
+	target := textinput.New()
+	target.Prompt = "Target URL: "
+	target.Placeholder = "http://localhost:3000/webhooks"
+
+	return copyMenu{
+		target: target,
+	}
+}
+
+func (c *copyMenu) open(i item, target string) {
+	c.item = i
+	c.visible = true
+
+	if c.target.Value() == "" {
+		c.target.SetValue(target)
+		c.target.CursorEnd()
+	}
+
+	c.target.Blur()
+}
+
+func (c *copyMenu) close() {
+	c.visible = false
+	c.target.Blur()
+}
+
+func (c *copyMenu) generate() (string, error) {
+	format := snippet.Formats[c.selected]
+	return snippet.Generate(format, c.item.Request, strings.TrimSpace(c.target.Value()))
+}
+
+func (c copyMenu) view(st styles) string {
+	options := make([]string, 0, len(snippet.Formats))
+
+	for i, format := range snippet.Formats {
+		label := fmt.Sprintf("%d. %s", i+1, format)
+
+		if i == c.selected {
+			options = append(options, st.boldenString("> "+label, false))
+			continue
+		}
+
+		options = append(options, st.makeString("  "+label, true))
+	}
+
+	return st.overlay.Render(lipgloss.JoinVertical(lipgloss.Left,
+		st.boldenString("Copy request "+c.item.ID+" as", false),
+		lipgloss.JoinVertical(lipgloss.Left, options...),
+		c.target.View(),
+		st.makeString("j/k or 1-4 to pick, tab to edit the target url, enter to copy, esc to cancel", true),
+	))
+}
+
+func (m model) updateCopyMenu(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+
+	case tea.KeyEsc:
+		m.copyMenu.close()
+		return m, nil
+
+	case tea.KeyEnter:
+		return m.copySnippet()
+
+	case tea.KeyTab, tea.KeyShiftTab:
+		if m.copyMenu.target.Focused() {
+			m.copyMenu.target.Blur()
+			return m, nil
+		}
+
+		return m, m.copyMenu.target.Focus()
+	}
+
+	if m.copyMenu.target.Focused() {
+		var cmd tea.Cmd
+		m.copyMenu.target, cmd = m.copyMenu.target.Update(msg)
+		return m, cmd
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		m.copyMenu.selected = (m.copyMenu.selected + len(snippet.Formats) - 1) % len(snippet.Formats)
+
+	case "down", "j":
+		m.copyMenu.selected = (m.copyMenu.selected + 1) % len(snippet.Formats)
+
+	case "1", "2", "3", "4":
+		if i := int(msg.String()[0] - '1'); i < len(snippet.Formats) {
+			m.copyMenu.selected = i
+		}
+	}
+
+	return m, nil
+}
+
+func (m model) copySnippet() (tea.Model, tea.Cmd) {
+	m.copyMenu.close()
+
+	s, err := m.copyMenu.generate()
+	if err != nil {
+		m.status = fmt.Sprintf("Could not generate snippet: %v", err)
+		return m, nil
+	}
+
+	m.copyToClipboard(fmt.Sprintf("request %s as %s", m.copyMenu.item.ID,
+		snippet.Formats[m.copyMenu.selected]), s)
+	return m, nil
+}
@@ -10,66 +10,63 @@ import (
 	"github.com/charmbracelet/lipgloss"
 )
 
-var (
-	color          = lipgloss.AdaptiveColor{Light: "#111222", Dark: "#FAFAFA"}
-	feintColor     = lipgloss.AdaptiveColor{Light: "#333333", Dark: "#888888"}
-	faintBuleColor = lipgloss.Color("#428BCA")
-
-	errorStyle = lipgloss.NewStyle().BorderForeground(lipgloss.Color("9")).
-			Border(lipgloss.RoundedBorder()).
-			Align(lipgloss.Center).
-			Margin(0, 0, 0, 1).
-			Padding(0, 2, 0, 2)
-
-	defaultTextStyle = lipgloss.NewStyle().Foreground(color)
-)
-
-func getTableStyles() table.Styles {
-	s := table.DefaultStyles()
-	s.Header = s.Header.
+func (s styles) getTableStyles() table.Styles {
+	t := table.DefaultStyles()
+	t.Header = t.Header.
 		BorderStyle(lipgloss.NormalBorder()).
-		BorderForeground(lipgloss.Color("240")).
+		BorderForeground(s.theme.border).
 		BorderBottom(true).
 		Bold(false)
-	s.Selected = s.Selected.
-		Foreground(lipgloss.Color("229")).
-		Background(lipgloss.Color("57")).
+	t.Selected = t.Selected.
+		Foreground(s.theme.selectedForeground).
+		Background(s.theme.selectedBackground).
 		Bold(false)
 
-	return s
+	if s.theme.monochrome {
+		t.Header = t.Header.Bold(true)
+		t.Selected = t.Selected.Reverse(true)
+	}
+
+	return t
 }
 
-func showError(err error) string {
-	return errorStyle.Render(lipgloss.Place(200, 3, lipgloss.Center, lipgloss.Center,
-		lipgloss.JoinVertical(lipgloss.Center, err.Error(),
+func (s styles) showError(err error, width int) string {
+	// the border and padding of errorStyle take up some of the width
+	width = max(width-s.errorStyle.GetHorizontalFrameSize(), 20)
+
+	return s.errorStyle.Render(lipgloss.Place(width, 3, lipgloss.Center, lipgloss.Center,
+		lipgloss.JoinVertical(lipgloss.Center, lipgloss.NewStyle().Width(width).Align(lipgloss.Center).Render(err.Error()),
 			"",
 			"Press Ctrl-c to shut down",
 		)))
 }
 
-func makeString(s string, withFeint bool) string {
-	style := defaultTextStyle.Copy()
+func (s styles) makeString(str string, withFeint bool) string {
+	style := s.text
 
 	if withFeint {
-		style = style.Foreground(feintColor)
+		style = style.Foreground(s.theme.muted)
 	}
 
-	return style.Render(s)
+	return style.Render(str)
 }
 
-func boldenString(s string, withFeint bool) string {
-	style := defaultTextStyle.Copy().Bold(true)
+func (s styles) boldenString(str string, withFeint bool) string {
+	style := s.text.Bold(true)
 
 	if withFeint {
-		style = style.Foreground(feintColor)
+		style = style.Foreground(s.theme.muted)
 	}
 
-	return style.Render(s)
+	return style.Render(str)
 }
 
 func highlightCode(w io.Writer, s, colorscheme string) error {
-	err := quick.Highlight(w, s, "json", "terminal256", colorscheme)
-	return err
+	return highlight(w, s, "json", colorscheme)
+}
+
+func highlight(w io.Writer, s, lexer, colorscheme string) error {
+	return quick.Highlight(w, s, lexer, "terminal256", colorscheme)
 }
 
 func prettyPrintJSON(str string) (string, error) {
@@ -0,0 +1,403 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ayinke-llc/sdump/internal/inspect"
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dustin/go-humanize"
+)
+
+// detailTab is a view of the selected request in the detail pane
+type detailTab int
+
+const (
+	bodyTab detailTab = iota
+	headersTab
+	queryTab
+	cookiesTab
+	authTab
+	rawTab
+)
+
+var detailTabs = []detailTab{bodyTab, headersTab, queryTab, cookiesTab, authTab, rawTab}
+
+func (d detailTab) String() string {
+	switch d {
+	case headersTab:
+		return "Headers"
+	case queryTab:
+		return "Query"
+	case cookiesTab:
+		return "Cookies"
+	case authTab:
+		return "Auth"
+	case rawTab:
+		return "Raw"
+	default:
+		return "Body"
+	}
+}
+
+func (d detailTab) next() detailTab {
+	return detailTabs[(int(d)+1)%len(detailTabs)]
+}
+
+func (d detailTab) previous() detailTab {
+	return detailTabs[(int(d)+len(detailTabs)-1)%len(detailTabs)]
+}
+
+func (m model) tabBar() string {
+	tabs := make([]string, 0, len(detailTabs))
+
+	for _, tab := range detailTabs {
+		style := m.styles.inactiveTab
+		if tab == m.detailTab {
+			style = m.styles.activeTab
+		}
+
+		tabs = append(tabs, style.Render(tab.String()))
+	}
+
+	if m.jsonTree.visible {
+		return lipgloss.JoinHorizontal(lipgloss.Top, tabs...) + m.styles.makeString(m.jsonTree.hint(), true)
+	}
+
+	hint := fmt.Sprintf("   %s and %s switch tabs", m.keys.PreviousTab.Help().Key, m.keys.NextTab.Help().Key)
+	if selectedItem, ok := m.requestList.SelectedItem().(item); ok && m.detailTab == bodyTab {
+		view := "detected"
+		if m.bodyFormatOverride != "" {
+			view = "forced"
+		}
+
+		hint += fmt.Sprintf(", %s changes the body view (%s %s)", m.keys.BodyView.Help().Key,
+			view, m.bodyFormat(selectedItem))
+	}
+
+	return lipgloss.JoinHorizontal(lipgloss.Top, tabs...) + m.styles.makeString(hint, true)
+}
+
+// renderDetail returns the content of the current tab for the selected
+// request
+func (m model) renderDetail(selectedItem item) string {
+	switch m.detailTab {
+	case headersTab:
+		return m.pairsTable("Header", inspect.Headers(selectedItem.Request.Headers),
+			"No headers were sent")
+
+	case queryTab:
+		pairs, err := inspect.Query(selectedItem.Request.Query)
+		if err != nil {
+			return m.styles.makeString(err.Error(), false)
+		}
+
+		return m.pairsTable("Parameter", pairs, "No query parameters were sent")
+
+	case cookiesTab:
+		return m.pairsTable("Cookie", inspect.Cookies(selectedItem.Request.Headers),
+			"No cookies were sent")
+
+	case authTab:
+		return m.renderAuth(selectedItem)
+
+	case rawTab:
+		raw := strings.ReplaceAll(inspect.Raw(selectedItem.Request, m.dumpURL), "\r\n", "\n")
+
+		var b bytes.Buffer
+		if err := highlight(&b, raw, "http", m.colorscheme); err != nil {
+			return raw
+		}
+
+		return b.String()
+	}
+
+	if m.jsonTree.visible && m.jsonTree.ingest == selectedItem.ID {
+		return m.jsonTree.view(m.styles, m.detailedRequestView.Height)
+	}
+
+	return m.renderBody(selectedItem)
+}
+
+// bodyFormat is the format the body is shown in. Unless overridden with v,
+// it is detected from the Content-Type of the request
+func (m model) bodyFormat(selectedItem item) inspect.Format {
+	if m.bodyFormatOverride != "" {
+		return m.bodyFormatOverride
+	}
+
+	if _, ok := inspect.ParseGraphQL(selectedItem.Request); ok {
+		return inspect.FormatGraphQL
+	}
+
+	return inspect.DetectFormat(selectedItem.Request.Headers, selectedItem.Request.Body)
+}
+
+// nextBodyFormat cycles through every format and then back to detecting it
+// automatically
+func nextBodyFormat(current inspect.Format) inspect.Format {
+	if current == "" {
+		return inspect.Formats[0]
+	}
+
+	for i, format := range inspect.Formats {
+		if format == current && i+1 < len(inspect.Formats) {
+			return inspect.Formats[i+1]
+		}
+	}
+
+	return ""
+}
+
+func (m model) renderBody(selectedItem item) string {
+	def := selectedItem.Request
+
+	var notices []string
+
+	// bodies that could not be decoded are shown as a hex dump of the bytes
+	// that are available
+	var dump func() ([]byte, error)
+
+	if encoding := def.Encoding; encoding != nil {
+		if encoding.Error != "" {
+			notices = append(notices, fmt.Sprintf("Could not decode the %s body, showing it as it was sent: %s",
+				encoding.ContentEncoding, encoding.Error))
+			dump = def.OriginalBody
+		} else {
+			notices = append(notices, fmt.Sprintf("Decoded from %s: %s sent, %s decoded", encoding.ContentEncoding,
+				humanize.Bytes(uint64(def.Size)), humanize.Bytes(uint64(encoding.DecodedSize))))
+		}
+	}
+
+	if binary := def.Binary; binary != nil {
+		original := func() ([]byte, error) {
+			return base64.StdEncoding.DecodeString(binary.Original)
+		}
+
+		switch {
+		case binary.Format == "":
+			notices = append(notices, "The body is not text, showing it as it was sent")
+			dump = original
+
+		case binary.Error != "":
+			notices = append(notices, fmt.Sprintf("Could not decode the %s body, showing it as it was sent: %s",
+				binary.Format, binary.Error))
+			dump = original
+
+		case binary.MessageType != "":
+			notices = append(notices, fmt.Sprintf("Decoded from %s message %s", binary.Format, binary.MessageType))
+
+		default:
+			notices = append(notices, fmt.Sprintf("Decoded from %s", binary.Format))
+		}
+	}
+
+	if len(notices) == 0 {
+		return m.renderBodyContent(selectedItem)
+	}
+
+	var content string
+
+	if dump != nil {
+		b, err := dump()
+		if err != nil {
+			content = m.styles.makeString(err.Error(), false)
+		} else {
+			content = inspect.HexDump(string(b))
+		}
+	} else {
+		content = m.renderBodyContent(selectedItem)
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		m.styles.makeString(strings.Join(notices, "\n"), true), "", content)
+}
+
+func (m model) renderBodyContent(selectedItem item) string {
+	format := m.bodyFormat(selectedItem)
+
+	if format == inspect.FormatForm {
+		pairs, err := inspect.Form(selectedItem.Request.Body)
+		if err != nil {
+			return m.styles.makeString(err.Error(), false)
+		}
+
+		return m.pairsTable("Field", pairs, "The form is empty")
+	}
+
+	if format == inspect.FormatGraphQL {
+		if operations, ok := inspect.ParseGraphQL(selectedItem.Request); ok {
+			return m.renderGraphQL(operations)
+		}
+	}
+
+	// The body might not match the Content-Type it was sent with so if
+	// pretty printing fails, show the body as it is
+	body, err := inspect.Pretty(format, selectedItem.Request.Body)
+	if err != nil {
+		body = selectedItem.Request.Body
+	}
+
+	if format == inspect.FormatBinary {
+		return body
+	}
+
+	var b bytes.Buffer
+
+	// if we have an error here, just reuse the body as it is without adding
+	// color
+	if err := highlight(&b, body, format.Lexer(), m.colorscheme); err != nil {
+		return body
+	}
+
+	return b.String()
+}
+
+// renderGraphQL shows the query and variables of every operation separately.
+// Batched requests have more than one operation
+func (m model) renderGraphQL(operations []inspect.GraphQL) string {
+	sections := make([]string, 0, len(operations))
+
+	for i, operation := range operations {
+		lines := []string{
+			m.styles.boldenString(operation.Name(), false),
+		}
+
+		if len(operations) > 1 {
+			lines[0] = m.styles.boldenString(fmt.Sprintf("%d. %s", i+1, operation.Name()), false)
+		}
+
+		if operation.PersistedQueryHash != "" {
+			lines = append(lines, fmt.Sprintf("%s %s", m.styles.boldenString("Persisted query:", false),
+				operation.PersistedQueryHash))
+		}
+
+		lines = append(lines, "")
+
+		if operation.Query != "" {
+			query := inspect.PrettyGraphQL(operation.Query)
+
+			var b bytes.Buffer
+			if err := highlight(&b, query, inspect.FormatGraphQL.Lexer(), m.colorscheme); err == nil {
+				query = b.String()
+			}
+
+			lines = append(lines, query)
+		} else {
+			lines = append(lines, m.styles.makeString("Only the hash of the query was sent", true))
+		}
+
+		if len(operation.Variables) > 0 {
+			lines = append(lines, "", m.styles.boldenString("Variables", false),
+				m.highlightJSON(string(operation.Variables)))
+		}
+
+		sections = append(sections, strings.Join(lines, "\n"))
+	}
+
+	return strings.Join(sections, "\n\n")
+}
+
+func (m model) pairsTable(name string, pairs []inspect.Pair, empty string) string {
+	if len(pairs) == 0 {
+		return m.styles.makeString(empty, true)
+	}
+
+	rows := make([]table.Row, 0, len(pairs))
+	for _, pair := range pairs {
+		rows = append(rows, table.Row{pair.Name, pair.Value})
+	}
+
+	// every cell is padded by a column on each side
+	nameWidth := max(m.layout.detailWidth*2/5-2, 10)
+	valueWidth := max(m.layout.detailWidth-nameWidth-4, 10)
+
+	t := m.headersTable
+	t.SetColumns([]table.Column{
+		{Title: name, Width: nameWidth},
+		{Title: "Value", Width: valueWidth},
+	})
+	t.SetRows(rows)
+	t.SetHeight(len(rows) + 1)
+
+	return t.View()
+}
+
+func (m model) renderAuth(selectedItem item) string {
+	auth, err := inspect.Authorization(selectedItem.Request.Headers)
+	if errors.Is(err, inspect.ErrNoAuthorization) {
+		return m.styles.makeString("No Authorization header was sent", true)
+	}
+
+	lines := []string{
+		fmt.Sprintf("%s %s", m.styles.boldenString("Scheme:", false), auth.Scheme),
+	}
+
+	if err != nil {
+		lines = append(lines,
+			fmt.Sprintf("%s %s", m.styles.boldenString("Credentials:", false), auth.Credentials),
+			"",
+			m.styles.makeString(fmt.Sprintf("Could not decode credentials: %v", err), false))
+		return strings.Join(lines, "\n")
+	}
+
+	switch {
+	case auth.JWT != nil:
+		lines = append(lines,
+			"",
+			m.styles.boldenString("JWT header", false),
+			m.highlightJSON(string(auth.JWT.Header)),
+			"",
+			m.styles.boldenString("JWT claims", false),
+			m.highlightJSON(string(auth.JWT.Claims)))
+
+		if len(auth.JWT.Times) > 0 {
+			names := make([]string, 0, len(auth.JWT.Times))
+			for name := range auth.JWT.Times {
+				names = append(names, name)
+			}
+
+			sort.Strings(names)
+
+			lines = append(lines, "")
+			for _, name := range names {
+				t := auth.JWT.Times[name]
+				lines = append(lines, fmt.Sprintf("%s %s (%s)",
+					m.styles.boldenString(name+":", false), t.Format(time.RFC1123), humanize.Time(t)))
+			}
+		}
+
+		lines = append(lines, "", m.styles.makeString("The signature of the token is not verified", true))
+
+	case auth.Username != "" || auth.Password != "":
+		lines = append(lines,
+			fmt.Sprintf("%s %s", m.styles.boldenString("Username:", false), auth.Username),
+			fmt.Sprintf("%s %s", m.styles.boldenString("Password:", false), auth.Password))
+
+	default:
+		lines = append(lines,
+			fmt.Sprintf("%s %s", m.styles.boldenString("Credentials:", false), auth.Credentials))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (m model) highlightJSON(s string) string {
+	pretty, err := prettyPrintJSON(s)
+	if err != nil {
+		return s
+	}
+
+	var b bytes.Buffer
+	if err := highlightCode(&b, pretty, m.colorscheme); err != nil {
+		return pretty
+	}
+
+	return b.String()
+}
@@ -0,0 +1,254 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ayinke-llc/sdump/internal/diff"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// diffView compares the two requests marked with m
+type diffView struct {
+	visible bool
+	first   item
+	second  item
+	view    viewport.Model
+}
+
+func newDiffView() diffView {
+	return diffView{
+		view: viewport.New(0, 0),
+	}
+}
+
+func (d *diffView) setSize(width, height int) {
+	d.view.Width = max(width-6, 20)
+	d.view.Height = max(height-16, 5)
+}
+
+func (d *diffView) open(st styles, first, second item) error {
+	result, err := diff.Requests(first.Request, second.Request)
+	if err != nil {
+		return err
+	}
+
+	d.visible = true
+	d.first = first
+	d.second = second
+	d.view.SetContent(renderDiff(st, result))
+	d.view.GotoTop()
+	return nil
+}
+
+func (d *diffView) close() {
+	d.visible = false
+}
+
+func (d diffView) render(st styles, c clock) string {
+	return st.overlay.Render(lipgloss.JoinVertical(lipgloss.Left,
+		st.boldenString(fmt.Sprintf("Comparing %s with %s", d.first.ID, d.second.ID), false),
+		st.makeString(fmt.Sprintf("- %s    + %s", c.format(d.first.CreatedAt),
+			c.format(d.second.CreatedAt)), true),
+		"",
+		d.view.View(),
+		"",
+		st.makeString("up/down to scroll, s swaps the requests, esc to close", true),
+	))
+}
+
+func renderDiff(st styles, result *diff.Result) string {
+	if result.Equal() {
+		return st.makeString("The requests are identical", true)
+	}
+
+	var sections []string
+
+	if result.Method != nil {
+		sections = append(sections, st.boldenString("Method", false)+"\n"+renderChanges(st, []diff.Change{*result.Method}))
+	}
+
+	sections = append(sections,
+		st.boldenString("Headers", false)+"\n"+renderChanges(st, result.Headers),
+		st.boldenString("Query", false)+"\n"+renderChanges(st, result.Query))
+
+	body := st.boldenString(fmt.Sprintf("Body (%s)", result.Body.Format), false) + "\n"
+
+	switch {
+	case result.Body.Equal:
+		body += st.makeString("No differences", true)
+
+	case result.Body.Format == diff.BodyJSON:
+		body += renderChanges(st, result.Body.Changes)
+
+	default:
+		lines := make([]string, 0, len(result.Body.Lines))
+		for _, line := range result.Body.Lines {
+			text := string(line.Op) + " " + line.Text
+
+			switch line.Op {
+			case diff.LineAdded:
+				text = st.added.Render(text)
+			case diff.LineRemoved:
+				text = st.removed.Render(text)
+			default:
+				text = st.makeString(text, true)
+			}
+
+			lines = append(lines, text)
+		}
+
+		if result.Body.Truncated {
+			lines = append(lines, st.makeString("The bodies are too large to compare line by line. Only the first difference is shown", true))
+		}
+
+		body += strings.Join(lines, "\n")
+	}
+
+	sections = append(sections, body)
+
+	return strings.Join(sections, "\n\n")
+}
+
+func renderChanges(st styles, changes []diff.Change) string {
+	if len(changes) == 0 {
+		return st.makeString("No differences", true)
+	}
+
+	lines := make([]string, 0, len(changes))
+
+	for _, change := range changes {
+		switch change.Kind {
+		case diff.Added:
+			lines = append(lines, st.added.Render(fmt.Sprintf("+ %s: %s", change.Name, change.New)))
+		case diff.Removed:
+			lines = append(lines, st.removed.Render(fmt.Sprintf("- %s: %s", change.Name, change.Old)))
+		default:
+			lines = append(lines, st.changed.Render(fmt.Sprintf("~ %s: %s → %s", change.Name, change.Old, change.New)))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// toggleMark marks the selected request for comparison. Only the two most
+// recently marked requests are kept
+func (m model) toggleMark() (tea.Model, tea.Cmd) {
+	selectedItem, ok := m.requestList.SelectedItem().(item)
+	if !ok {
+		return m, nil
+	}
+
+	marked := make([]string, 0, 2)
+	for _, id := range m.marked {
+		if id != selectedItem.ID {
+			marked = append(marked, id)
+		}
+	}
+
+	if len(marked) == len(m.marked) {
+		marked = append(marked, selectedItem.ID)
+	}
+
+	if len(marked) > 2 {
+		marked = marked[len(marked)-2:]
+	}
+
+	m.marked = marked
+	m.refreshMarks()
+
+	switch len(m.marked) {
+	case 1:
+		m.status = fmt.Sprintf("Marked 1 request. Mark another with %s and press %s to compare them",
+			m.keys.Mark.Help().Key, m.keys.Compare.Help().Key)
+	case 2:
+		m.status = fmt.Sprintf("Marked 2 requests. Press %s to compare them", m.keys.Compare.Help().Key)
+	default:
+		m.status = ""
+	}
+
+	return m, nil
+}
+
+// refreshMarks updates the requests in the list so marks are shown
+func (m *model) refreshMarks() {
+	isMarked := func(id string) bool {
+		for _, marked := range m.marked {
+			if marked == id {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	for i := range m.search.items {
+		m.search.items[i].marked = isMarked(m.search.items[i].ID)
+	}
+
+	for i, listItem := range m.requestList.Items() {
+		if current, ok := listItem.(item); ok && current.marked != isMarked(current.ID) {
+			current.marked = !current.marked
+			_ = m.requestList.SetItem(i, current)
+		}
+	}
+}
+
+// openDiff compares the marked requests. If only one is marked, it is
+// compared with the selected request
+func (m model) openDiff() (tea.Model, tea.Cmd) {
+	ids := m.marked
+	if selectedItem, ok := m.requestList.SelectedItem().(item); ok && len(ids) == 1 && ids[0] != selectedItem.ID {
+		ids = append([]string{ids[0]}, selectedItem.ID)
+	}
+
+	if len(ids) != 2 {
+		m.status = fmt.Sprintf("Mark two requests with %s to compare them", m.keys.Mark.Help().Key)
+		return m, nil
+	}
+
+	items := make([]item, 0, len(ids))
+	for _, id := range ids {
+		for _, known := range m.search.items {
+			if known.ID == id {
+				items = append(items, known)
+				break
+			}
+		}
+	}
+
+	if len(items) != 2 {
+		m.status = "The marked requests are no longer available"
+		return m, nil
+	}
+
+	if err := m.diffView.open(m.styles, items[0], items[1]); err != nil {
+		m.status = fmt.Sprintf("Could not compare the requests: %v", err)
+	}
+
+	return m, nil
+}
+
+func (m model) updateDiffView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "esc", "q":
+		m.diffView.close()
+		return m, nil
+
+	case "s":
+		if err := m.diffView.open(m.styles, m.diffView.second, m.diffView.first); err != nil {
+			m.status = fmt.Sprintf("Could not compare the requests: %v", err)
+		}
+
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.diffView.view, cmd = m.diffView.view.Update(msg)
+	return m, cmd
+}
@@ -0,0 +1,18 @@
+package tui
+
+import (
+	"context"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func (m model) exportHAR(ingestID string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		b, err := m.apiClient.IngestHAR(ctx, ingestID)
+		return HARMsg{name: ingestID, har: b, err: err}
+	}
+}
@@ -0,0 +1,251 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/ayinke-llc/sdump/config"
+	"github.com/ayinke-llc/sdump/internal/filter"
+	"github.com/charmbracelet/bubbles/list"
+)
+
+const (
+	notifyOff     = "off"
+	notifyBell    = "bell"
+	notifyDesktop = "desktop"
+)
+
+var notifyModes = []string{notifyOff, notifyBell, notifyDesktop}
+
+// feed decides what happens when a request arrives. While paused, requests
+// are held back so the list does not move under the request being inspected
+type feed struct {
+	paused bool
+	// follow selects every new request as it arrives
+	follow bool
+	// buffered arrived while paused, oldest first
+	buffered []item
+
+	notify       string
+	notifyFilter *filter.Query
+}
+
+func newFeed(cfg config.NotifyConfig) (feed, error) {
+	f := feed{notify: cfg.Mode}
+	if f.notify == "" {
+		f.notify = notifyOff
+	}
+
+	if !slices.Contains(notifyModes, f.notify) {
+		return feed{}, fmt.Errorf("unknown notify mode %s. Use off, bell or desktop", cfg.Mode)
+	}
+
+	query, err := filter.Parse(cfg.Filter)
+	if err != nil {
+		return feed{}, fmt.Errorf("invalid tui.notify.filter: %w", err)
+	}
+
+	f.notifyFilter = query
+	return f, nil
+}
+
+// ValidateNotify reports if the notification settings in the config are
+// invalid so the ssh server can refuse to start instead of failing every
+// session
+func ValidateNotify(cfg config.NotifyConfig) error {
+	_, err := newFeed(cfg)
+	return err
+}
+
+func (f *feed) cycleNotify() {
+	for i, mode := range notifyModes {
+		if mode == f.notify {
+			f.notify = notifyModes[(i+1)%len(notifyModes)]
+			return
+		}
+	}
+
+	f.notify = notifyOff
+}
+
+func (f feed) isBuffered(id string) bool {
+	for _, i := range f.buffered {
+		if i.ID == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Notifier announces new requests on the user's terminal. Like the
+// clipboard, it writes escape sequences to the session so they reach the
+// client over ssh
+type Notifier struct {
+	out  io.Writer
+	term string
+}
+
+// NewNotifier writes to out. term is the TERM of the client and is used to
+// pass desktop notifications through tmux and screen
+func NewNotifier(out io.Writer, term string) *Notifier {
+	return &Notifier{
+		out:  out,
+		term: term,
+	}
+}
+
+func (n *Notifier) Bell() error {
+	_, err := io.WriteString(n.out, "\a")
+	return err
+}
+
+// Desktop sends an OSC 9 notification. Terminals that do not support it
+// ignore the sequence
+func (n *Notifier) Desktop(message string) error {
+	// the sequence ends at the first control character
+	message = strings.Map(func(r rune) rune {
+		if r < ' ' || r == 0x7f {
+			return ' '
+		}
+
+		return r
+	}, message)
+
+	seq := "\x1b]9;" + message + "\a"
+
+	switch {
+	case strings.HasPrefix(n.term, "tmux"):
+		seq = "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	case strings.HasPrefix(n.term, "screen"):
+		seq = "\x1bP" + seq + "\x1b\\"
+	}
+
+	_, err := io.WriteString(n.out, seq)
+	return err
+}
+
+// announce notifies the user of a new request to dumpURL if it matches the
+// notify filter
+func (m *model) announce(dumpURL *url.URL, i item) {
+	if m.feed.notify == notifyOff ||
+		!m.feed.notifyFilter.Match(filter.Request{ID: i.ID, Request: i.Request}) {
+		return
+	}
+
+	var err error
+
+	switch m.feed.notify {
+	case notifyBell:
+		err = m.notifier.Bell()
+	case notifyDesktop:
+		err = m.notifier.Desktop(fmt.Sprintf("sdump: %s request on %s", i.Request.Method, dumpURL))
+	}
+
+	if err != nil {
+		m.status = fmt.Sprintf("Could not send notification: %v", err)
+	}
+}
+
+// showItem adds a new request to the top of the list. Unless following new
+// requests, the selected request stays selected
+func (m *model) showItem(i item) {
+	insertNewest(&m.requestList, i, m.feed.follow)
+	m.refreshTitle()
+}
+
+func insertNewest(l *list.Model, i item, follow bool) {
+	selected := l.Index()
+	empty := len(l.Items()) == 0
+
+	l.InsertItem(0, i)
+
+	if follow || empty {
+		l.Select(0)
+	} else {
+		l.Select(selected + 1)
+	}
+}
+
+// togglePause holds back new requests or shows the ones that arrived while
+// paused
+func (m *model) togglePause() {
+	m.feed.paused = !m.feed.paused
+
+	if m.feed.paused {
+		m.status = fmt.Sprintf("Paused. New requests are held back until you press %s again",
+			m.keys.Pause.Help().Key)
+		m.refreshTitle()
+		return
+	}
+
+	m.status = fmt.Sprintf("Resumed with %d new requests", len(m.feed.buffered))
+
+	buffered := m.feed.buffered
+	m.feed.buffered = nil
+
+	for _, i := range buffered {
+		m.showItem(i)
+	}
+
+	m.refreshTitle()
+}
+
+func (m *model) toggleFollow() {
+	m.feed.follow = !m.feed.follow
+
+	m.status = "Stopped following new requests"
+	if m.feed.follow {
+		m.status = "Following new requests"
+		if len(m.requestList.Items()) > 0 {
+			m.requestList.Select(0)
+		}
+	}
+
+	m.refreshTitle()
+}
+
+func (m *model) cycleNotify() {
+	m.feed.cycleNotify()
+
+	switch m.feed.notify {
+	case notifyBell:
+		m.status = "Ringing the bell on new requests"
+	case notifyDesktop:
+		m.status = "Sending a desktop notification on new requests"
+	default:
+		m.status = "Notifications are off"
+	}
+
+	if m.feed.notify != notifyOff && !m.feed.notifyFilter.IsEmpty() {
+		m.status += " matching " + m.feed.notifyFilter.Raw
+	}
+
+	m.refreshTitle()
+}
+
+// feedTitle describes the state of the feed in the title of the list
+func (m model) feedTitle() string {
+	var flags []string
+
+	if m.feed.paused {
+		flags = append(flags, fmt.Sprintf("paused, %d new", len(m.feed.buffered)))
+	}
+
+	if m.feed.follow {
+		flags = append(flags, "following")
+	}
+
+	if m.feed.notify != notifyOff {
+		flags = append(flags, m.feed.notify)
+	}
+
+	if len(flags) == 0 {
+		return ""
+	}
+
+	return " [" + strings.Join(flags, ", ") + "]"
+}
@@ -0,0 +1,501 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ayinke-llc/sdump/config"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	presetDefault = "default"
+	presetVim     = "vim"
+	presetEmacs   = "emacs"
+)
+
+// keyMap holds the keys of every action of the request list and detail
+// panes and of the request composer. Other forms and overlays keep their own
+// keys
+type keyMap struct {
+	preset string
+
+	Up           key.Binding
+	Down         key.Binding
+	PageUp       key.Binding
+	PageDown     key.Binding
+	HalfPageUp   key.Binding
+	HalfPageDown key.Binding
+	Top          key.Binding
+	Bottom       key.Binding
+
+	SwitchPane key.Binding
+	OpenDetail key.Binding
+	Back       key.Binding
+	Zoom       key.Binding
+	Filter     key.Binding
+	LoadMore   key.Binding
+
+	Replay    key.Binding
+	Compose   key.Binding
+	CopyAs    key.Binding
+	ExportHAR key.Binding
+	Mark      key.Binding
+	Compare   key.Binding
+	Star      key.Binding
+	Annotate  key.Binding
+
+	NextTab     key.Binding
+	PreviousTab key.Binding
+	BodyView    key.Binding
+	JSONTree    key.Binding
+	CopyBody    key.Binding
+
+	CopyURL     key.Binding
+	ShowCopied  key.Binding
+	NewEndpoint key.Binding
+	Settings    key.Binding
+
+	OpenEndpoint     key.Binding
+	CloseEndpoint    key.Binding
+	NextEndpoint     key.Binding
+	PreviousEndpoint key.Binding
+
+	Pause  key.Binding
+	Follow key.Binding
+	Notify key.Binding
+	Help   key.Binding
+	Quit   key.Binding
+
+	ComposerSend            key.Binding
+	ComposerFormat          key.Binding
+	ComposerNextField       key.Binding
+	ComposerPreviousField   key.Binding
+	ComposerPreviousVariant key.Binding
+	ComposerNextVariant     key.Binding
+	ComposerScrollUp        key.Binding
+	ComposerScrollDown      key.Binding
+	ComposerClose           key.Binding
+}
+
+func newBinding(description string, keys ...string) key.Binding {
+	return key.NewBinding(
+		key.WithKeys(keys...),
+		key.WithHelp(strings.Join(keys, "/"), description))
+}
+
+func defaultKeyMap() keyMap {
+	return keyMap{
+		preset: presetDefault,
+
+		Up:           newBinding("move up", "up", "k"),
+		Down:         newBinding("move down", "down", "j"),
+		PageUp:       newBinding("previous page", "pgup", "b"),
+		PageDown:     newBinding("next page", "pgdown", "f"),
+		HalfPageUp:   newBinding("half a page up", "u", "ctrl+u"),
+		HalfPageDown: newBinding("half a page down", "d", "ctrl+d"),
+		Top:          newBinding("go to the top", "home", "g"),
+		Bottom:       newBinding("go to the bottom", "end", "G"),
+
+		SwitchPane: newBinding("switch between the list and details", "tab"),
+		OpenDetail: newBinding("focus the details", "enter"),
+		Back:       newBinding("focus the list or leave zoom", "esc"),
+		Zoom:       newBinding("zoom into the details", "z"),
+		Filter:     newBinding("filter requests", "/"),
+		LoadMore:   newBinding("load older requests", "ctrl+n"),
+
+		Replay:    newBinding("replay the request", "ctrl+p"),
+		Compose:   newBinding("edit and resend the request", "ctrl+o"),
+		CopyAs:    newBinding("copy as curl, httpie, Go or fetch", "ctrl+k"),
+		ExportHAR: newBinding("copy as HAR", "ctrl+x"),
+		Mark:      newBinding("mark for comparison", "m"),
+		Compare:   newBinding("compare marked requests", "="),
+		Star:      newBinding("star the request", "s"),
+		Annotate:  newBinding("add a note and tags", "a"),
+
+		NextTab:     newBinding("next tab", "]"),
+		PreviousTab: newBinding("previous tab", "["),
+		BodyView:    newBinding("change how the body is shown", "v"),
+		JSONTree:    newBinding("explore a JSON body as a tree", "t"),
+		CopyBody:    newBinding("copy the body in view", "ctrl+b"),
+
+		CopyURL:     newBinding("copy the url", "ctrl+y"),
+		ShowCopied:  newBinding("show the last copied text", "ctrl+v"),
+		NewEndpoint: newBinding("create a new url", "ctrl+r"),
+		Settings:    newBinding("change your settings", ","),
+
+		OpenEndpoint:     newBinding("watch a new url in another tab", "ctrl+t"),
+		CloseEndpoint:    newBinding("stop watching the url in view", "ctrl+w"),
+		NextEndpoint:     newBinding("next url", "}"),
+		PreviousEndpoint: newBinding("previous url", "{"),
+
+		Pause:  newBinding("pause or resume new requests", "p"),
+		Follow: newBinding("follow new requests", "F"),
+		Notify: newBinding("switch between no notification, bell or desktop", "n"),
+		Help:   newBinding("show all keys", "?"),
+		Quit:   newBinding("quit", "ctrl+c"),
+
+		ComposerSend:            newBinding("send", "ctrl+g"),
+		ComposerFormat:          newBinding("format JSON body", "ctrl+l"),
+		ComposerNextField:       newBinding("next field", "tab"),
+		ComposerPreviousField:   newBinding("previous field", "shift+tab"),
+		ComposerPreviousVariant: newBinding("newer sent variant", "alt+up"),
+		ComposerNextVariant:     newBinding("older sent variant", "alt+down"),
+		ComposerScrollUp:        newBinding("scroll response up", "pgup"),
+		ComposerScrollDown:      newBinding("scroll response down", "pgdown"),
+		ComposerClose:           newBinding("close", "esc"),
+	}
+}
+
+// vimKeyMap moves between the panes with h and l and pages with ctrl+f and
+// ctrl+b. Copying the body moves to Y
+func vimKeyMap() keyMap {
+	k := defaultKeyMap()
+	k.preset = presetVim
+
+	k.PageUp = newBinding("previous page", "ctrl+b", "pgup")
+	k.PageDown = newBinding("next page", "ctrl+f", "pgdown")
+	k.HalfPageUp = newBinding("half a page up", "ctrl+u")
+	k.HalfPageDown = newBinding("half a page down", "ctrl+d")
+	k.OpenDetail = newBinding("focus the details", "l", "enter")
+	k.Back = newBinding("focus the list or leave zoom", "h", "esc")
+	k.CopyBody = newBinding("copy the body in view", "Y")
+	return k
+}
+
+// emacsKeyMap moves with ctrl+n and ctrl+p so loading older requests,
+// replaying and showing the copied text move to meta keys. ctrl+g cancels,
+// so the composer sends with alt+enter
+func emacsKeyMap() keyMap {
+	k := defaultKeyMap()
+	k.preset = presetEmacs
+
+	k.Up = newBinding("move up", "ctrl+p", "up")
+	k.Down = newBinding("move down", "ctrl+n", "down")
+	k.PageUp = newBinding("previous page", "alt+v", "pgup")
+	k.PageDown = newBinding("next page", "ctrl+v", "pgdown")
+	k.Top = newBinding("go to the top", "alt+<", "home")
+	k.Bottom = newBinding("go to the bottom", "alt+>", "end")
+	k.Back = newBinding("focus the list or leave zoom", "ctrl+g", "esc")
+	k.Filter = newBinding("filter requests", "ctrl+s", "/")
+	k.LoadMore = newBinding("load older requests", "alt+n")
+	k.Replay = newBinding("replay the request", "alt+p")
+	k.ShowCopied = newBinding("show the last copied text", "alt+y")
+	k.ComposerSend = newBinding("send", "alt+enter")
+	k.ComposerClose = newBinding("close", "ctrl+g", "esc")
+	return k
+}
+
+// keyScope is where the keys of an action are matched. A key can only do
+// one thing in a scope
+type keyScope string
+
+const (
+	scopeBrowser  keyScope = "browser"
+	scopeComposer keyScope = "composer"
+)
+
+type keyAction struct {
+	name    string
+	binding *key.Binding
+	scope   keyScope
+}
+
+// actions names every binding. The names are used in the tui.keys section of
+// the config file
+func (k *keyMap) actions() []keyAction {
+	return []keyAction{
+		{"up", &k.Up, scopeBrowser},
+		{"down", &k.Down, scopeBrowser},
+		{"page_up", &k.PageUp, scopeBrowser},
+		{"page_down", &k.PageDown, scopeBrowser},
+		{"half_page_up", &k.HalfPageUp, scopeBrowser},
+		{"half_page_down", &k.HalfPageDown, scopeBrowser},
+		{"top", &k.Top, scopeBrowser},
+		{"bottom", &k.Bottom, scopeBrowser},
+		{"switch_pane", &k.SwitchPane, scopeBrowser},
+		{"open_detail", &k.OpenDetail, scopeBrowser},
+		{"back", &k.Back, scopeBrowser},
+		{"zoom", &k.Zoom, scopeBrowser},
+		{"filter", &k.Filter, scopeBrowser},
+		{"load_more", &k.LoadMore, scopeBrowser},
+		{"replay", &k.Replay, scopeBrowser},
+		{"compose", &k.Compose, scopeBrowser},
+		{"copy_as", &k.CopyAs, scopeBrowser},
+		{"export_har", &k.ExportHAR, scopeBrowser},
+		{"mark", &k.Mark, scopeBrowser},
+		{"compare", &k.Compare, scopeBrowser},
+		{"star", &k.Star, scopeBrowser},
+		{"annotate", &k.Annotate, scopeBrowser},
+		{"next_tab", &k.NextTab, scopeBrowser},
+		{"previous_tab", &k.PreviousTab, scopeBrowser},
+		{"body_view", &k.BodyView, scopeBrowser},
+		{"json_tree", &k.JSONTree, scopeBrowser},
+		{"copy_body", &k.CopyBody, scopeBrowser},
+		{"copy_url", &k.CopyURL, scopeBrowser},
+		{"show_copied", &k.ShowCopied, scopeBrowser},
+		{"new_endpoint", &k.NewEndpoint, scopeBrowser},
+		{"settings", &k.Settings, scopeBrowser},
+		{"open_endpoint", &k.OpenEndpoint, scopeBrowser},
+		{"close_endpoint", &k.CloseEndpoint, scopeBrowser},
+		{"next_endpoint", &k.NextEndpoint, scopeBrowser},
+		{"previous_endpoint", &k.PreviousEndpoint, scopeBrowser},
+		{"pause", &k.Pause, scopeBrowser},
+		{"follow", &k.Follow, scopeBrowser},
+		{"notify", &k.Notify, scopeBrowser},
+		{"help", &k.Help, scopeBrowser},
+		{"quit", &k.Quit, scopeBrowser},
+		{"composer_send", &k.ComposerSend, scopeComposer},
+		{"composer_format", &k.ComposerFormat, scopeComposer},
+		{"composer_next_field", &k.ComposerNextField, scopeComposer},
+		{"composer_previous_field", &k.ComposerPreviousField, scopeComposer},
+		{"composer_previous_variant", &k.ComposerPreviousVariant, scopeComposer},
+		{"composer_next_variant", &k.ComposerNextVariant, scopeComposer},
+		{"composer_scroll_up", &k.ComposerScrollUp, scopeComposer},
+		{"composer_scroll_down", &k.ComposerScrollDown, scopeComposer},
+		{"composer_close", &k.ComposerClose, scopeComposer},
+	}
+}
+
+// newKeyMap builds the keymap of the preset and applies the bindings from
+// the config on top of it
+func newKeyMap(cfg config.KeysConfig) (keyMap, error) {
+	var k keyMap
+
+	switch cfg.Preset {
+	case "", presetDefault:
+		k = defaultKeyMap()
+	case presetVim:
+		k = vimKeyMap()
+	case presetEmacs:
+		k = emacsKeyMap()
+	default:
+		return keyMap{}, fmt.Errorf("unknown keymap preset %s. Use default, vim or emacs", cfg.Preset)
+	}
+
+	actions := k.actions()
+
+	for name, keys := range cfg.Bindings {
+		found := false
+
+		for _, action := range actions {
+			if action.name != name {
+				continue
+			}
+
+			*action.binding = newBinding(action.binding.Help().Desc, keys...)
+			found = true
+		}
+
+		if !found {
+			return keyMap{}, fmt.Errorf("unknown action %s in tui.keys.bindings", name)
+		}
+	}
+
+	// the actions of a scope are matched in the same place so one key can
+	// only do one thing. quit works in every scope
+	for _, scope := range []keyScope{scopeBrowser, scopeComposer} {
+		owners := make(map[string]string)
+
+		for _, action := range actions {
+			if action.scope != scope && action.binding != &k.Quit {
+				continue
+			}
+
+			for _, bound := range action.binding.Keys() {
+				if owner, ok := owners[bound]; ok {
+					return keyMap{}, fmt.Errorf("%s is bound to both %s and %s", bound, owner, action.name)
+				}
+
+				owners[bound] = action.name
+			}
+		}
+	}
+
+	if len(k.Quit.Keys()) == 0 {
+		return keyMap{}, errors.New("quit must have at least one key")
+	}
+
+	return k, nil
+}
+
+// ValidateKeys reports if the tui.keys section of the config is invalid so
+// the ssh server can refuse to start instead of failing every session
+func ValidateKeys(cfg config.KeysConfig) error {
+	_, err := newKeyMap(cfg)
+	return err
+}
+
+// listKeyMap moves through the request list with the navigation keys.
+// Filtering is handled by search so it stays disabled
+func (k keyMap) listKeyMap() list.KeyMap {
+	return list.KeyMap{
+		CursorUp:      k.Up,
+		CursorDown:    k.Down,
+		PrevPage:      k.PageUp,
+		NextPage:      k.PageDown,
+		GoToStart:     k.Top,
+		GoToEnd:       k.Bottom,
+		ShowFullHelp:  k.Help,
+		CloseFullHelp: k.Help,
+
+		Filter:               key.NewBinding(key.WithDisabled()),
+		ClearFilter:          key.NewBinding(key.WithDisabled()),
+		CancelWhileFiltering: key.NewBinding(key.WithDisabled()),
+		AcceptWhileFiltering: key.NewBinding(key.WithDisabled()),
+		Quit:                 key.NewBinding(key.WithDisabled()),
+		ForceQuit:            key.NewBinding(key.WithDisabled()),
+	}
+}
+
+// viewportKeyMap scrolls the request details with the navigation keys
+func (k keyMap) viewportKeyMap() viewport.KeyMap {
+	return viewport.KeyMap{
+		Up:           k.Up,
+		Down:         k.Down,
+		PageUp:       k.PageUp,
+		PageDown:     k.PageDown,
+		HalfPageUp:   k.HalfPageUp,
+		HalfPageDown: k.HalfPageDown,
+	}
+}
+
+// helpGroups are the bindings shown in the help overlay of the pane
+func (k keyMap) helpGroups(p pane) []keyGroup {
+	global := keyGroup{"Everywhere", []key.Binding{
+		k.Pause, k.Follow, k.Notify,
+		k.CopyURL, k.ShowCopied, k.NewEndpoint,
+		k.OpenEndpoint, k.CloseEndpoint, k.NextEndpoint, k.PreviousEndpoint,
+		k.Settings, k.Help, k.Quit,
+	}}
+
+	if p == detailPane {
+		return []keyGroup{
+			{"Scrolling", []key.Binding{
+				k.Up, k.Down, k.PageUp, k.PageDown,
+				k.HalfPageUp, k.HalfPageDown, k.Top, k.Bottom,
+			}},
+			{"Details", []key.Binding{
+				k.NextTab, k.PreviousTab, k.BodyView, k.JSONTree, k.CopyBody,
+				k.Back, k.SwitchPane, k.Zoom,
+			}},
+			global,
+		}
+	}
+
+	return []keyGroup{
+		{"Moving", []key.Binding{
+			k.Up, k.Down, k.PageUp, k.PageDown, k.Top, k.Bottom,
+			k.OpenDetail, k.SwitchPane, k.Zoom, k.Filter, k.LoadMore,
+		}},
+		{"Selected request", []key.Binding{
+			k.Replay, k.Compose, k.CopyAs, k.ExportHAR,
+			k.Mark, k.Compare, k.Star, k.Annotate,
+		}},
+		global,
+	}
+}
+
+// shortHelp are the bindings shown in the header
+func (k keyMap) shortHelp() []key.Binding {
+	return []key.Binding{k.CopyURL, k.Filter, k.SwitchPane, k.Zoom, k.Help, k.Quit}
+}
+
+// name is the config name of the binding
+func (k keyMap) name(binding key.Binding) string {
+	for _, action := range k.actions() {
+		if action.binding.Help() == binding.Help() {
+			return action.name
+		}
+	}
+
+	return ""
+}
+
+type keyGroup struct {
+	title    string
+	bindings []key.Binding
+}
+
+// keyHelp lists every key of the focused pane
+type keyHelp struct {
+	visible bool
+}
+
+func (h *keyHelp) toggle() { h.visible = !h.visible }
+
+func (m model) renderKeyHelp() string {
+	title := "Keys for the request list"
+	if m.focus == detailPane {
+		title = "Keys for the request details"
+	}
+
+	groups := m.keys.helpGroups(m.focus)
+
+	keyWidth, descWidth := 0, 0
+	for _, group := range groups {
+		for _, binding := range group.bindings {
+			keyWidth = max(keyWidth, lipgloss.Width(binding.Help().Key))
+			descWidth = max(descWidth, lipgloss.Width(binding.Help().Desc))
+		}
+	}
+
+	blocks := make([]string, 0, len(groups))
+	for _, group := range groups {
+		lines := []string{m.styles.boldenString(group.title, false)}
+
+		for _, binding := range group.bindings {
+			keys := binding.Help().Key
+			if !binding.Enabled() {
+				keys = "unbound"
+			}
+
+			lines = append(lines, fmt.Sprintf("%s  %-*s  %s",
+				m.styles.key.Render(fmt.Sprintf("%-*s", keyWidth, keys)),
+				descWidth, binding.Help().Desc,
+				m.styles.makeString(m.keys.name(binding), true)))
+		}
+
+		blocks = append(blocks, lipgloss.NewStyle().Margin(0, 4, 1, 0).Render(strings.Join(lines, "\n")))
+	}
+
+	// the groups sit side by side when they fit so short terminals can see
+	// all of them
+	groupsView := lipgloss.JoinHorizontal(lipgloss.Top, blocks...)
+	if lipgloss.Width(groupsView)+4 > m.width {
+		groupsView = lipgloss.JoinVertical(lipgloss.Left, blocks...)
+	}
+
+	return m.styles.overlay.Render(lipgloss.JoinVertical(lipgloss.Left,
+		m.styles.boldenString(title, false),
+		m.styles.makeString(fmt.Sprintf("%s preset. Actions can be rebound by name from tui.keys.bindings in the config", m.keys.preset), true),
+		"",
+		groupsView,
+		m.styles.makeString(m.keys.Help.Help().Key+" or esc closes", true),
+	))
+}
+
+func (m model) updateKeyHelp(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Quit):
+		return m, tea.Quit
+
+	case key.Matches(msg, m.keys.Help), msg.Type == tea.KeyEsc, msg.String() == "q":
+		m.keyHelp.toggle()
+	}
+
+	return m, nil
+}
+
+func (m *model) setKeyMap(k keyMap) {
+	m.keys = k
+	m.requestList.KeyMap = k.listKeyMap()
+	m.detailedRequestView.KeyMap = k.viewportKeyMap()
+	m.copied.view.KeyMap = k.viewportKeyMap()
+	m.composer.keys = k
+}
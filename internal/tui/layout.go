@@ -0,0 +1,98 @@
+package tui
+
+import (
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// splitWidth is the narrowest terminal the list and the detail pane
+	// are shown side by side in. Narrower terminals show the focused pane
+	// only
+	splitWidth = 100
+
+	minListWidth = 30
+	maxListWidth = 60
+
+	// detailChrome is the tab bar and the note line above the viewport
+	detailChrome = 2
+)
+
+// pane is a part of the screen that receives key presses
+type pane int
+
+const (
+	listPane pane = iota
+	detailPane
+)
+
+// paneFrame is the border around both panes. Every theme uses borders of
+// the same size
+var paneFrame = lipgloss.NewStyle().Border(lipgloss.RoundedBorder())
+
+// layout is the size of every pane. It is computed from the terminal size
+// and the height of the header which changes with status messages and
+// forms
+type layout struct {
+	// split is set when both panes fit side by side
+	split bool
+
+	listWidth   int
+	detailWidth int
+	// paneHeight is the inner height shared by both panes
+	paneHeight int
+}
+
+func computeLayout(width, height, headerHeight int, zoomed bool) layout {
+	frameWidth, frameHeight := paneFrame.GetFrameSize()
+
+	l := layout{
+		split:      width >= splitWidth && !zoomed,
+		paneHeight: max(height-headerHeight-frameHeight, detailChrome+3),
+	}
+
+	if !l.split {
+		l.listWidth = max(width-frameWidth, 10)
+		l.detailWidth = max(width-frameWidth, 10)
+		return l
+	}
+
+	l.listWidth = min(max(width*2/5, minListWidth), maxListWidth)
+	l.detailWidth = width - l.listWidth - 2*frameWidth - 1
+	return l
+}
+
+func (l layout) viewportHeight() int {
+	return max(l.paneHeight-detailChrome, 1)
+}
+
+// resize applies the layout to the list, viewport and tables. It is called
+// before every update and render since the header height is not known in
+// advance
+func (m *model) resize() {
+	var headerHeight int
+	if !m.zoomed {
+		// the header is followed by a blank line
+		headerHeight = lipgloss.Height(m.header()) + 1
+	}
+
+	m.layout = computeLayout(m.width, m.height, headerHeight, m.zoomed)
+
+	m.requestList.SetSize(m.layout.listWidth, m.layout.paneHeight)
+	m.detailedRequestView.Width = m.layout.detailWidth
+	m.detailedRequestView.Height = m.layout.viewportHeight()
+	m.headersTable.SetWidth(m.layout.detailWidth)
+}
+
+func (m model) paneStyle(p pane) lipgloss.Style {
+	if m.focus == p {
+		return m.styles.focusedPane
+	}
+
+	return m.styles.pane
+}
+
+// fit truncates every line of s to width so long hints do not wrap and break
+// the layout
+func fit(s string, width int) string {
+	return lipgloss.NewStyle().MaxWidth(width).Render(s)
+}
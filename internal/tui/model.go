@@ -9,20 +9,22 @@ import (
 	"net/http"
 	"net/url"
 	"os"
-	"sort"
 	"strings"
 	"time"
 
+	"github.com/ayinke-llc/sdump"
 	"github.com/ayinke-llc/sdump/config"
+	"github.com/ayinke-llc/sdump/internal/client"
+	"github.com/ayinke-llc/sdump/internal/inspect"
 	"github.com/ayinke-llc/sdump/internal/util"
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/table"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
-	"github.com/r3labs/sse/v2"
-	"golang.design/x/clipboard"
 	"golang.org/x/term"
 )
 
@@ -38,16 +40,75 @@ type model struct {
 	requestList list.Model
 	httpClient  *http.Client
 	colorscheme string
+	reference   string
 
-	sseClient                 *sse.Client
-	receiveChan               chan item
+	// events are the requests and connection changes of the subscription
+	// to the endpoint
+	events                    chan tea.Msg
+	connection                connection
 	detailedRequestView       viewport.Model
 	detailedRequestViewBuffer *bytes.Buffer
+	// detailItem is the request shown in the details pane
+	detailItem string
+
+	headersTable table.Model
+	detailTab    detailTab
+
+	// bodyFormatOverride replaces the format detected from the Content-Type
+	// of the request when set
+	bodyFormatOverride inspect.Format
 
-	headersTable  table.Model
 	width, height int
+	layout        layout
+
+	// focus is the pane that receives navigation keys
+	focus pane
+	// zoomed shows the detail pane on the entire screen
+	zoomed bool
+
+	keys    keyMap
+	keyHelp keyHelp
+	styles  styles
+	clock   clock
+
+	// preferences are the saved settings of the user
+	preferences  *sdump.Preferences
+	settingsForm settingsForm
 
 	sshFingerPrint string
+	apiClient      *client.Client
+	// environ is the environment of the ssh session
+	environ []string
+
+	replayForm replayForm
+	composer   composer
+	copyMenu   copyMenu
+	search     search
+	jsonTree   jsonTree
+	diffView   diffView
+
+	annotateForm annotateForm
+
+	stats trafficStats
+
+	// tabs are the endpoints being watched. The entry of the tab in view is
+	// only up to date after stashTab
+	tabs      []endpointTab
+	activeTab int
+
+	// marked are the ids of the requests to compare, oldest mark first
+	marked []string
+
+	clipboard *Clipboard
+	copied    clipboardFallback
+
+	feed     feed
+	notifier *Notifier
+
+	// status is a short lived message shown under the header. It is used to
+	// report the result of actions that should not take over the entire
+	// screen like errors do
+	status string
 }
 
 func New(cfg *config.Config,
@@ -58,12 +119,46 @@ func New(cfg *config.Config,
 		return nil, err
 	}
 
+	keys, err := newKeyMap(cfg.TUI.Keys)
+	if err != nil {
+		return nil, err
+	}
+
+	feed, err := newFeed(cfg.TUI.Notify)
+	if err != nil {
+		return nil, err
+	}
+
 	tuiModel := newModel(cfg, width, height)
+	tuiModel.setKeyMap(keys)
+	tuiModel.feed = feed
 
 	for _, opt := range opts {
 		opt(&tuiModel)
 	}
 
+	// the environment of the ssh session decides if colors are used
+	environ := tuiModel.environ
+	if environ == nil {
+		environ = os.Environ()
+	}
+
+	theme, err := newTheme(cfg.TUI, environ)
+	if err != nil {
+		return nil, err
+	}
+
+	tuiModel.setStyles(newStyles(theme))
+
+	if tuiModel.preferences == nil {
+		tuiModel.preferences = &sdump.Preferences{}
+	}
+
+	// saved preferences must never keep the user out of their session
+	if err := tuiModel.applyPreferences(tuiModel.preferences); err != nil {
+		tuiModel.status = fmt.Sprintf("Could not apply your settings: %v", err)
+	}
+
 	if util.IsStringEmpty(tuiModel.sshFingerPrint) {
 		return nil, errors.New("SSH fingerprint must be provided")
 	}
@@ -72,6 +167,17 @@ func New(cfg *config.Config,
 		return nil, errors.New("width or height must be a non zero number")
 	}
 
+	if tuiModel.clipboard == nil {
+		tuiModel.clipboard = NewClipboard(os.Stdout, os.Environ())
+	}
+
+	if tuiModel.notifier == nil {
+		tuiModel.notifier = NewNotifier(os.Stdout, os.Getenv("TERM"))
+	}
+
+	tuiModel.apiClient = client.New(cfg.HTTP.Domain,
+		cfg.HTTP.AdminSecret, tuiModel.sshFingerPrint)
+
 	return tuiModel, nil
 }
 
@@ -92,36 +198,46 @@ func newModel(cfg *config.Config, width, height int) model {
 		width:       width,
 		height:      height,
 		title:       "Sdump",
-		spinner: spinner.New(
-			spinner.WithSpinner(spinner.Line),
-			spinner.WithStyle(lipgloss.NewStyle().Foreground(lipgloss.Color("205"))),
-		),
+		spinner:     spinner.New(spinner.WithSpinner(spinner.Line)),
 
 		cfg: cfg,
 		httpClient: &http.Client{
 			Timeout: time.Minute,
 		},
 
-		requestList:               list.New([]list.Item{}, list.NewDefaultDelegate(), 50, height),
+		requestList:               newRequestList(height),
+		tabs:                      make([]endpointTab, 1),
 		detailedRequestView:       viewport.New(width, height),
 		detailedRequestViewBuffer: bytes.NewBuffer(nil),
-		sseClient:                 sse.NewClient(fmt.Sprintf("%s/events", cfg.HTTP.Domain)),
-		receiveChan:               make(chan item),
+		events:                    make(chan tea.Msg),
+		replayForm:                newReplayForm(),
+		annotateForm:              newAnnotateForm(),
+		composer:                  newComposer(),
+		copyMenu:                  newCopyMenu(),
+		search:                    newSearch(),
+		jsonTree:                  newJSONTree(),
+		diffView:                  newDiffView(),
+		settingsForm:              newSettingsForm(),
+		stats:                     newTrafficStats(),
+		feed:                      feed{notify: notifyOff},
+		clock:                     clock{layout: sdump.TimestampFormats[defaultTimestampFormat]},
+		preferences:               &sdump.Preferences{},
+		copied:                    newClipboardFallback(),
 
 		headersTable: table.New(table.WithColumns(columns),
 			table.WithFocused(true),
 			table.WithHeight(10),
 			table.WithWidth(width),
-			table.WithKeyMap(table.KeyMap{}),
-			table.WithStyles(getTableStyles())),
+			table.WithKeyMap(table.KeyMap{})),
 	}
 
-	m.requestList.Title = "Incoming requests"
-	m.requestList.SetShowTitle(true)
-	m.requestList.SetFilteringEnabled(false)
-	m.requestList.DisableQuitKeybindings()
+	m.setKeyMap(defaultKeyMap())
+	m.setStyles(newStyles(themes[themeDark]))
 
 	m.headersTable.Blur()
+	m.composer.setSize(width, height)
+	m.diffView.setSize(width, height)
+	m.resize()
 
 	return m
 }
@@ -132,36 +248,8 @@ func (m model) Init() tea.Cmd {
 	tea.SetWindowTitle(m.title)
 
 	return tea.Batch(m.spinner.Tick,
-		m.createEndpoint(false))
-}
-
-func (m model) listenForNextItem() tea.Msg {
-	var knownError error
-
-	err := m.sseClient.Subscribe(m.pubChannel, func(msg *sse.Event) {
-		var i item
-
-		if err := json.NewDecoder(bytes.NewBuffer(msg.Data)).Decode(&i); err != nil {
-			knownError = err
-			return
-		}
-
-		m.receiveChan <- i
-	})
-
-	if knownError != nil {
-		return ErrorMsg{err: err}
-	}
-
-	if err != nil {
-		return ErrorMsg{err: err}
-	}
-
-	return nil
-}
-
-func (m model) waitForNextItem() tea.Msg {
-	return ItemMsg{item: <-m.receiveChan}
+		m.createEndpoint(false),
+		m.waitForEvent)
 }
 
 func (m model) createEndpoint(forceURLChange bool) func() tea.Msg {
@@ -192,6 +280,7 @@ func (m model) createEndpoint(forceURLChange bool) func() tea.Msg {
 
 		var response struct {
 			URL struct {
+				Identifier            string `json:"identifier,omitempty"`
 				HumanReadableEndpoint string `json:"human_readable_endpoint,omitempty"`
 			} `json:"url,omitempty"`
 			SSE struct {
@@ -205,12 +294,29 @@ func (m model) createEndpoint(forceURLChange bool) func() tea.Msg {
 
 		return DumpURLMsg{
 			URL:        response.URL.HumanReadableEndpoint,
+			Reference:  response.URL.Identifier,
 			SSEChannel: response.SSE.Channel,
 		}
 	}
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+
+	// the spinner ticks many times a second and never changes the details
+	if _, ok := msg.(spinner.TickMsg); ok {
+		return next, cmd
+	}
+
+	if updated, ok := next.(model); ok {
+		updated.refreshDetail()
+		next = updated
+	}
+
+	return next, cmd
+}
+
+func (m model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 
 	switch msg := msg.(type) {
@@ -233,153 +339,637 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 		m.pubChannel = msg.SSEChannel
-		go m.listenForNextItem()
-		return m, m.waitForNextItem
+		m.reference = msg.Reference
+		m.stats = newTrafficStats()
+		m.stats.requested = time.Now()
+		m.subscribe(msg.SSEChannel)
+		return m, m.fetchStats(msg.Reference)
+
+	case StatsMsg:
+
+		if msg.err != nil {
+			msg.stats = &sdump.IngestStats{}
+		}
+
+		// the tab in view or its endpoint changed while the request was in
+		// flight
+		if msg.reference != m.reference {
+			if t := m.backgroundTab(withReference(msg.reference)); t != nil {
+				t.stats.seed(msg.stats)
+			}
+
+			return m, cmd
+		}
+
+		if msg.err != nil {
+			m.status = fmt.Sprintf("Could not load traffic stats: %v", msg.err)
+		}
+
+		m.stats.seed(msg.stats)
+		return m, cmd
 
 	case ErrorMsg:
 
+		// a url that could not be created for a new tab does not end the
+		// session
+		if !m.isInitialized() && len(m.tabs) > 1 {
+			m.closeTab()
+			m.status = fmt.Sprintf("Could not create a new url: %v", msg.err)
+			return m, cmd
+		}
+
 		m.err = msg.err
 		return m, cmd
 
+	case ConnectionMsg:
+
+		m.applyConnection(msg)
+		return m, m.waitForEvent
+
+	case MalformedEventMsg:
+
+		m.applyMalformedEvent(msg)
+		return m, m.waitForEvent
+
 	case ItemMsg:
 
-		m.requestList.InsertItem(0, msg.item)
+		// requests of other tabs or of the previous endpoint of this tab
+		if msg.channel != m.connection.channel {
+			if t := m.backgroundTab(onChannel(msg.channel)); t != nil {
+				m.addToBackground(t, msg.item)
+			}
+
+			return m, m.waitForEvent
+		}
+
+		// ordering relies on timestamps so requests without one are treated
+		// as just received
+		if msg.item.CreatedAt.IsZero() {
+			msg.item.CreatedAt = time.Now()
+		}
+
+		if !m.search.add(msg.item) {
+			return m, m.waitForEvent
+		}
+
+		m.stats.add(msg.item)
+		m.announce(m.dumpURL, msg.item)
+
+		if !m.search.matches(msg.item) {
+			return m, m.waitForEvent
+		}
+
+		if m.feed.paused {
+			m.feed.buffered = append(m.feed.buffered, msg.item)
+			m.refreshTitle()
+			return m, m.waitForEvent
+		}
+
+		m.showItem(msg.item)
+
+		return m, m.waitForEvent
+
+	case HistoryMsg:
+
+		m.mergeHistory(msg)
+		return m, cmd
+
+	case AnnotationMsg:
+
+		m.applyAnnotation(msg)
+		return m, cmd
+
+	case PreferencesMsg:
+
+		if msg.err != nil {
+			m.status = fmt.Sprintf("Could not save settings: %v", msg.err)
+			return m, cmd
+		}
+
+		m.status = "Saved settings"
+		if err := m.applyPreferences(msg.preferences); err != nil {
+			m.status = fmt.Sprintf("Saved settings but could not apply them: %v", err)
+		}
+
+		return m, cmd
+
+	case ReplayMsg:
+
+		m.status = replayStatus(msg)
+		return m, cmd
+
+	case HARMsg:
+
+		if msg.err != nil {
+			m.status = fmt.Sprintf("Could not export HAR: %v", msg.err)
+			return m, cmd
+		}
+
+		m.copyToClipboard("the HAR of "+msg.name, string(msg.har))
+		if !m.copied.visible {
+			m.status = fmt.Sprintf("Copied the HAR of %s to your clipboard. Run ssh -p %d %s har %s > requests.har to export every request",
+				msg.name, m.cfg.SSH.Port, m.cfg.SSH.Host, m.reference)
+		}
+
+		return m, cmd
+
+	case ComposerMsg:
+
+		m.composer.sending = false
+		m.composer.err = msg.err
+		if msg.err != nil {
+			return m, cmd
+		}
+
+		m.composer.variants = append([]sdump.Replay{*msg.replay}, m.composer.variants...)
+		m.composer.showVariant(0)
+		return m, cmd
 
-		return m, m.waitForNextItem
+	case ComposerHistoryMsg:
+
+		if msg.ingest != m.composer.ingest {
+			return m, cmd
+		}
+
+		if msg.err != nil {
+			m.composer.err = msg.err
+			return m, cmd
+		}
+
+		m.composer.variants = msg.replays
+		return m, cmd
 
 	case tea.WindowSizeMsg:
 
-		m.requestList.SetSize(msg.Width, msg.Height-27)
+		m.width, m.height = msg.Width, msg.Height
+		m.resize()
+		m.composer.setSize(msg.Width, msg.Height)
+		m.diffView.setSize(msg.Width, msg.Height)
 
 		return m, cmd
 
 	case tea.KeyMsg:
-		switch msg.Type {
-		case tea.KeyCtrlR:
+		// the header might have changed since the last render
+		m.resize()
+
+		if m.replayForm.visible {
+			return m.updateReplayForm(msg)
+		}
+
+		if m.annotateForm.visible {
+			return m.updateAnnotateForm(msg)
+		}
+
+		if m.settingsForm.visible {
+			return m.updateSettingsForm(msg)
+		}
+
+		if m.composer.visible {
+			return m.updateComposer(msg)
+		}
+
+		if m.copyMenu.visible {
+			return m.updateCopyMenu(msg)
+		}
+
+		if m.search.editing {
+			return m.updateSearch(msg)
+		}
+
+		if m.copied.visible {
+			return m.updateClipboardFallback(msg)
+		}
+
+		if m.diffView.visible {
+			return m.updateDiffView(msg)
+		}
+
+		if m.jsonTree.visible {
+			return m.updateJSONTree(msg)
+		}
+
+		if m.keyHelp.visible {
+			return m.updateKeyHelp(msg)
+		}
+
+		switch {
+		case key.Matches(msg, m.keys.Quit):
+			return m, tea.Quit
+
+		case key.Matches(msg, m.keys.Help):
+			m.keyHelp.toggle()
+			return m, cmd
+
+		case key.Matches(msg, m.keys.Filter):
+			return m, m.search.open()
+
+		case key.Matches(msg, m.keys.Pause):
+			m.togglePause()
+			return m, cmd
+
+		case key.Matches(msg, m.keys.Follow):
+			m.toggleFollow()
+			return m, cmd
+
+		case key.Matches(msg, m.keys.Notify):
+			m.cycleNotify()
+			return m, cmd
+
+		case key.Matches(msg, m.keys.Settings):
+			m.settingsForm.open(m.preferences)
+			return m, cmd
+
+		case key.Matches(msg, m.keys.SwitchPane):
+			if m.zoomed {
+				return m, cmd
+			}
+
+			m.focus = (m.focus + 1) % 2
+			return m, cmd
+
+		case key.Matches(msg, m.keys.OpenDetail):
+			m.focus = detailPane
+			return m, cmd
+
+		case key.Matches(msg, m.keys.Back):
+			if m.zoomed {
+				m.zoomed = false
+				return m, cmd
+			}
+
+			m.focus = listPane
+			return m, cmd
+
+		case key.Matches(msg, m.keys.Zoom):
+			m.zoomed = !m.zoomed
+			if m.zoomed {
+				m.focus = detailPane
+			}
+
+			return m, cmd
+
+		case key.Matches(msg, m.keys.NextTab):
+			m.detailTab = m.detailTab.next()
+			m.detailedRequestView.GotoTop()
+			return m, cmd
+
+		case key.Matches(msg, m.keys.PreviousTab):
+			m.detailTab = m.detailTab.previous()
+			m.detailedRequestView.GotoTop()
+			return m, cmd
+
+		case key.Matches(msg, m.keys.JSONTree):
+			return m.openJSONTree()
+
+		case key.Matches(msg, m.keys.Mark):
+			return m.toggleMark()
+
+		case key.Matches(msg, m.keys.Star):
+			return m.toggleStar()
+
+		case key.Matches(msg, m.keys.Annotate):
+			selectedItem, ok := m.requestList.SelectedItem().(item)
+			if !ok {
+				return m, cmd
+			}
+
+			return m, m.annotateForm.open(selectedItem)
+
+		case key.Matches(msg, m.keys.Compare):
+			return m.openDiff()
+
+		case key.Matches(msg, m.keys.BodyView):
+			m.bodyFormatOverride = nextBodyFormat(m.bodyFormatOverride)
+			m.detailTab = bodyTab
+			m.detailedRequestView.GotoTop()
+			return m, cmd
+
+		case key.Matches(msg, m.keys.LoadMore):
+
+			if m.search.cursor == "" || m.search.loading {
+				return m, cmd
+			}
+
+			return m, m.fetchHistory(m.search.cursor)
+
+		case key.Matches(msg, m.keys.CopyAs):
+
+			selectedItem, ok := m.requestList.SelectedItem().(item)
+			if !ok {
+				return m, cmd
+			}
+
+			target := m.replayForm.target.Value()
+			if target == "" {
+				target = m.dumpURL.String()
+			}
+
+			m.copyMenu.open(selectedItem, target)
+			return m, cmd
+
+		case key.Matches(msg, m.keys.ExportHAR):
+
+			selectedItem, ok := m.requestList.SelectedItem().(item)
+			if !ok {
+				return m, cmd
+			}
+
+			m.status = "Exporting HAR..."
+			return m, m.exportHAR(selectedItem.ID)
+
+		case key.Matches(msg, m.keys.Compose):
+
+			selectedItem, ok := m.requestList.SelectedItem().(item)
+			if !ok {
+				return m, cmd
+			}
+
+			return m, tea.Batch(
+				m.composer.open(selectedItem, m.replayForm.target.Value(), m.styles, m.colorscheme),
+				m.fetchVariants(selectedItem.ID))
+
+		case key.Matches(msg, m.keys.Replay):
+
+			selectedItem, ok := m.requestList.SelectedItem().(item)
+			if !ok {
+				return m, cmd
+			}
+
+			return m, m.replayForm.open(selectedItem.ID)
+
+		case key.Matches(msg, m.keys.NewEndpoint):
 
 			m.dumpURL = nil
 			m.requestList.SetItems([]list.Item{})
+			m.search.reset()
+			m.marked = nil
+			m.feed.buffered = nil
 
 			return m, m.createEndpoint(true)
 
-		case tea.KeyCtrlY:
+		case key.Matches(msg, m.keys.OpenEndpoint):
+			if !m.isInitialized() {
+				return m, cmd
+			}
+
+			return m, m.openTab()
 
-			_ = clipboard.Write(clipboard.FmtText, []byte(m.dumpURL.String()))
+		case key.Matches(msg, m.keys.CloseEndpoint):
+			if !m.isInitialized() {
+				return m, cmd
+			}
 
+			m.closeTab()
 			return m, cmd
 
-		case tea.KeyCtrlB:
+		case key.Matches(msg, m.keys.NextEndpoint):
+			if !m.isInitialized() {
+				return m, cmd
+			}
 
-			_ = clipboard.Write(clipboard.FmtText, m.detailedRequestViewBuffer.Bytes())
+			m.switchTab(1)
+			return m, cmd
 
+		case key.Matches(msg, m.keys.PreviousEndpoint):
+			if !m.isInitialized() {
+				return m, cmd
+			}
+
+			m.switchTab(-1)
+			return m, cmd
+
+		case key.Matches(msg, m.keys.CopyURL):
+
+			m.copyToClipboard("the url", m.dumpURL.String())
+
+			return m, cmd
+
+		case key.Matches(msg, m.keys.CopyBody):
+
+			m.copyToClipboard("the request body", m.detailedRequestViewBuffer.String())
+
+			return m, cmd
+
+		case key.Matches(msg, m.keys.ShowCopied):
+
+			if m.copied.text == "" {
+				m.status = "Nothing has been copied yet"
+				return m, cmd
+			}
+
+			m.copied.open(m.width-6, m.height/2)
+			return m, cmd
+
+		case m.focus == detailPane && key.Matches(msg, m.keys.Top):
+			m.detailedRequestView.GotoTop()
+			return m, cmd
+
+		case m.focus == detailPane && key.Matches(msg, m.keys.Bottom):
+			m.detailedRequestView.GotoBottom()
 			return m, cmd
-		case tea.KeyCtrlC:
-			return m, tea.Quit
 		}
 	}
 
 	var cmds []tea.Cmd
 
-	m.requestList, cmd = m.requestList.Update(msg)
-	cmds = append(cmds, cmd)
+	// key presses only go to the focused pane
+	_, isKey := msg.(tea.KeyMsg)
 
-	m.detailedRequestView, cmd = m.detailedRequestView.Update(msg)
-	cmds = append(cmds, cmd)
+	if !isKey || m.focus == listPane {
+		m.requestList, cmd = m.requestList.Update(msg)
+		cmds = append(cmds, cmd)
+	}
 
-	m.headersTable, cmd = m.headersTable.Update(msg)
-	cmds = append(cmds, cmd)
+	if !isKey || m.focus == detailPane {
+		m.detailedRequestView, cmd = m.detailedRequestView.Update(msg)
+		cmds = append(cmds, cmd)
+	}
 
 	return m, tea.Batch(cmds...)
 }
 
+func (m model) updateReplayForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+
+	case tea.KeyEsc:
+		m.replayForm.close()
+		return m, nil
+
+	case tea.KeyTab, tea.KeyShiftTab:
+		return m, m.replayForm.toggleFocus()
+
+	case tea.KeyEnter:
+		m.replayForm.close()
+		m.status = fmt.Sprintf("Replaying request to %s...", m.replayForm.target.Value())
+		return m, m.replay()
+	}
+
+	var cmd tea.Cmd
+	m.replayForm, cmd = m.replayForm.update(msg)
+	return m, cmd
+}
+
 func (m model) View() string {
 	if m.err != nil {
-		return showError(m.err)
+		return m.styles.showError(m.err, m.width)
 	}
 
 	if !m.isInitialized() {
 		return lipgloss.Place(
-			200, 3,
+			m.width, 3,
 			lipgloss.Center,
 			lipgloss.Center,
 			lipgloss.JoinVertical(lipgloss.Center,
-				boldenString("Generating your URL... press CTRL+C to quit", true),
+				m.styles.boldenString(fmt.Sprintf("Generating your URL... press %s to quit", m.keys.Quit.Help().Key), true),
 				strings.Repeat(m.spinner.View(), 20),
 			))
 	}
 
-	browserHeader := lipgloss.Place(
-		200, 0,
-		lipgloss.Center, lipgloss.Center,
-		lipgloss.JoinVertical(lipgloss.Center,
-			boldenString("Inspecting incoming HTTP requests", true),
-			boldenString(fmt.Sprintf(`
-Waiting for requests on %s .. Press Ctrl-y to copy the url. Use ctrl-b to copy the json request body in view.
-				You can use j,k or arrow up and down to navigate your requests`, m.dumpURL), true),
-		))
+	// sizes depend on the header so they are recomputed on every render
+	m.resize()
 
-	return m.spinner.View() + browserHeader + strings.Repeat("\n", 2) + m.makeTable()
-}
+	if m.keyHelp.visible {
+		return m.header() + strings.Repeat("\n", 2) + m.renderKeyHelp()
+	}
 
-func (m model) buildView() string {
-	return lipgloss.JoinHorizontal(lipgloss.Top,
-		lipgloss.NewStyle().Margin(1, 4).
-			Render(m.requestList.View()),
-		lipgloss.NewStyle().Padding(0, 0).
-			Render(lipgloss.JoinHorizontal(lipgloss.Center,
-				m.headersTable.View(), ""),
-				lipgloss.NewStyle().Margin(1, 0, 0, 0).
-					Render(m.detailedRequestView.View())))
+	if m.zoomed {
+		return m.buildView()
+	}
+
+	browserHeader := m.header()
+
+	if m.copied.visible {
+		return browserHeader + strings.Repeat("\n", 2) + m.clipboardFallbackView()
+	}
+
+	if m.composer.visible {
+		return browserHeader + strings.Repeat("\n", 2) + m.composer.view()
+	}
+
+	if m.diffView.visible {
+		return browserHeader + strings.Repeat("\n", 2) + m.diffView.render(m.styles, m.clock)
+	}
+
+	return browserHeader + strings.Repeat("\n", 2) + m.buildView()
 }
 
-func (m model) makeTable() string {
-	selectedItem, ok := m.requestList.SelectedItem().(item)
-	if !ok {
-		return m.buildView()
+// header shows the url, help, status and the forms that are open
+func (m model) header() string {
+	if m.zoomed {
+		return ""
 	}
 
-	m.detailedRequestViewBuffer.Reset()
+	// every other key is listed in the help overlay
+	shortHelp := help.New()
+	shortHelp.Width = max(m.width-2, 10)
 
-	// Since the url is meant to take any json content ( valid or not)
-	// we do not want to enforce if a JSON is valid or not. Even on the ingestion side
-	// If we have a valid JSON, pretty print it. Else use the json body as is
-	jsonBody, err := prettyPrintJSON(selectedItem.Request.Body)
-	if err != nil {
-		jsonBody = selectedItem.Request.Body
+	lines := []string{m.styles.boldenString("Inspecting incoming HTTP requests", true)}
+	if tabs := m.renderTabs(); tabs != "" {
+		lines = append(lines, fit(tabs, m.width-2))
 	}
 
-	// if we have an error here, just reuse the json body as it is without adding
-	// color
-	if err := highlightCode(m.detailedRequestViewBuffer, jsonBody, m.cfg.TUI.ColorScheme); err != nil {
-		m.detailedRequestViewBuffer.WriteString(jsonBody)
+	browserHeader := lipgloss.JoinVertical(lipgloss.Center, append(lines,
+		fit(m.styles.boldenString(fmt.Sprintf("Waiting for requests on %s", m.dumpURL), true)+
+			"  "+m.connectionIndicator(), m.width-2),
+		fit(m.renderStats(), m.width-2),
+		shortHelp.ShortHelpView(m.keys.shortHelp()))...)
+
+	browserHeader = lipgloss.PlaceHorizontal(m.width, lipgloss.Center,
+		m.spinner.View()+browserHeader)
+
+	if m.status != "" {
+		browserHeader = lipgloss.JoinVertical(lipgloss.Left, browserHeader,
+			fit(m.styles.makeString(m.status, false), m.width))
 	}
 
-	m.detailedRequestView.SetContent(m.detailedRequestViewBuffer.String())
+	if m.search.editing {
+		browserHeader = lipgloss.JoinVertical(lipgloss.Left, browserHeader,
+			m.search.input.View())
+	}
 
-	m.detailedRequestViewBuffer.Reset()
-	m.detailedRequestViewBuffer.WriteString(jsonBody)
+	if m.replayForm.visible {
+		browserHeader = lipgloss.JoinVertical(lipgloss.Left, browserHeader,
+			m.replayForm.view(m.styles))
+	}
 
-	var keys []string
-	for key := range selectedItem.Request.Headers {
-		keys = append(keys, key)
+	if m.annotateForm.visible {
+		browserHeader = lipgloss.JoinVertical(lipgloss.Left, browserHeader,
+			m.annotateForm.view(m.styles))
 	}
 
-	sort.Strings(keys)
+	if m.settingsForm.visible {
+		browserHeader = lipgloss.JoinVertical(lipgloss.Left, browserHeader,
+			m.settingsForm.view(m.styles))
+	}
 
-	rows := []table.Row{}
+	if m.copyMenu.visible && !m.copied.visible {
+		browserHeader = lipgloss.JoinVertical(lipgloss.Left, browserHeader,
+			m.copyMenu.view(m.styles))
+	}
+
+	return browserHeader
+}
+
+func (m model) buildView() string {
+	// the note of the selected request is shown no matter the tab in view
+	var note string
+	if selectedItem, ok := m.requestList.SelectedItem().(item); ok && selectedItem.Note != "" {
+		note = fmt.Sprintf("%s %s", m.styles.boldenString("Note:", false), selectedItem.Note)
+	}
+
+	detail := m.paneStyle(detailPane).Render(lipgloss.JoinVertical(lipgloss.Left,
+		fit(m.tabBar(), m.layout.detailWidth),
+		fit(note, m.layout.detailWidth),
+		m.detailedRequestView.View()))
+
+	if m.zoomed {
+		return detail
+	}
 
-	for _, v := range keys {
-		value := selectedItem.Request.Headers.Get(v)
-		if value == "" {
-			continue
+	list := m.paneStyle(listPane).Render(m.requestList.View())
+
+	if !m.layout.split {
+		// narrow terminals only show the focused pane
+		if m.focus == detailPane {
+			return detail
 		}
 
-		rows = append(rows, table.Row{
-			v, value,
-		})
+		return list
+	}
+
+	return lipgloss.JoinHorizontal(lipgloss.Top, list, " ", detail)
+}
+
+// refreshDetail renders the selected request into the details pane after
+// every update. View only draws the pane, so scrolling and jumping to the
+// top or bottom act on the content that is shown
+func (m *model) refreshDetail() {
+	m.resize()
+
+	m.detailedRequestViewBuffer.Reset()
+
+	selectedItem, ok := m.requestList.SelectedItem().(item)
+	if !ok {
+		m.detailItem = ""
+		m.detailedRequestView.SetContent("")
+		return
+	}
+
+	// another request starts from the top
+	if selectedItem.ID != m.detailItem {
+		m.detailItem = selectedItem.ID
+		m.detailedRequestView.GotoTop()
 	}
 
-	m.headersTable.SetRows(rows)
+	m.detailedRequestView.SetContent(m.renderDetail(selectedItem))
 
-	return m.buildView()
+	// the copy body key always copies the body no matter the tab in view
+	jsonBody, err := prettyPrintJSON(selectedItem.Request.Body)
+	if err != nil {
+		jsonBody = selectedItem.Request.Body
+	}
+
+	m.detailedRequestViewBuffer.WriteString(jsonBody)
 }
@@ -0,0 +1,124 @@
+package tui
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ayinke-llc/sdump"
+	"github.com/ayinke-llc/sdump/config"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModel_DetailScrolling(t *testing.T) {
+	tt := []struct {
+		name string
+		keys []tea.KeyMsg
+		// bottom is set when the details must be scrolled all the way down
+		bottom bool
+	}{
+		{
+			name: "focus the details and move down",
+			keys: []tea.KeyMsg{
+				{Type: tea.KeyTab},
+				{Type: tea.KeyDown},
+			},
+		},
+		{
+			name: "focus the details and go to the bottom",
+			keys: []tea.KeyMsg{
+				{Type: tea.KeyEnter},
+				{Type: tea.KeyRunes, Runes: []rune("G")},
+			},
+			bottom: true,
+		},
+		{
+			name: "zoom and move down",
+			keys: []tea.KeyMsg{
+				{Type: tea.KeyRunes, Runes: []rune("z")},
+				{Type: tea.KeyDown},
+			},
+		},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			m := testModel(t)
+			require.Zero(t, m.detailedRequestView.YOffset)
+
+			for _, msg := range v.keys {
+				next, _ := m.Update(msg)
+				m = next.(model)
+			}
+
+			require.NotZero(t, m.detailedRequestView.YOffset)
+			require.Equal(t, v.bottom, m.detailedRequestView.AtBottom())
+
+			// rendering must not reset the details
+			_ = m.View()
+			require.NotZero(t, m.detailedRequestView.YOffset)
+		})
+	}
+}
+
+func TestModel_DetailScrolling_NewSelection(t *testing.T) {
+	m := testModel(t)
+
+	for _, msg := range []tea.KeyMsg{
+		{Type: tea.KeyTab},
+		{Type: tea.KeyRunes, Runes: []rune("G")},
+	} {
+		next, _ := m.Update(msg)
+		m = next.(model)
+	}
+
+	require.NotZero(t, m.detailedRequestView.YOffset)
+	require.Equal(t, "older", m.detailItem)
+
+	// back to the list and onto the newer request
+	for _, msg := range []tea.KeyMsg{
+		{Type: tea.KeyTab},
+		{Type: tea.KeyUp},
+	} {
+		next, _ := m.Update(msg)
+		m = next.(model)
+	}
+
+	require.Equal(t, "newer", m.detailItem)
+	require.Zero(t, m.detailedRequestView.YOffset)
+}
+
+// testModel has two requests with bodies longer than the details pane. New
+// requests never move the selection so the older one is selected
+func testModel(t *testing.T) model {
+	t.Helper()
+
+	m := newModel(&config.Config{}, 120, 30)
+
+	var err error
+	m.dumpURL, err = url.Parse("https://sdump.app/cmltfm6g330l5l1vq110")
+	require.NoError(t, err)
+
+	lines := make([]string, 200)
+	for i := range lines {
+		lines[i] = "line"
+	}
+
+	for _, id := range []string{"older", "newer"} {
+		m.showItem(item{
+			ID:        id,
+			CreatedAt: time.Now(),
+			Request: sdump.RequestDefinition{
+				Method:  http.MethodPost,
+				Body:    strings.Join(lines, "\n"),
+				Headers: http.Header{"Content-Type": []string{"text/plain"}},
+			},
+		})
+	}
+
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+	return next.(model)
+}
@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"github.com/ayinke-llc/sdump"
 	"github.com/ayinke-llc/sdump/config"
 )
 
@@ -35,3 +36,35 @@ func WithSSHFingerPrint(fingerPrint string) Option {
 		m.sshFingerPrint = fingerPrint
 	}
 }
+
+// WithEnvironment sets the environment of the ssh session. NO_COLOR, TERM
+// and COLORFGBG are used to pick the theme
+func WithEnvironment(environ []string) Option {
+	return func(m *model) {
+		m.environ = environ
+	}
+}
+
+// WithPreferences applies the saved preferences of the user on top of the
+// config of the server
+func WithPreferences(p *sdump.Preferences) Option {
+	return func(m *model) {
+		m.preferences = p
+	}
+}
+
+// WithNotifier sets where notifications of new requests are sent. Over ssh
+// this must write to the session
+func WithNotifier(n *Notifier) Option {
+	return func(m *model) {
+		m.notifier = n
+	}
+}
+
+// WithClipboard sets where copied text is sent. Over ssh this must write to
+// the session so the text reaches the user's terminal
+func WithClipboard(c *Clipboard) Option {
+	return func(m *model) {
+		m.clipboard = c
+	}
+}
@@ -0,0 +1,156 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ayinke-llc/sdump"
+	"github.com/ayinke-llc/sdump/internal/client"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dustin/go-humanize"
+)
+
+// replayForm asks for the target url and optional header overrides before
+// a captured request is replayed
+type replayForm struct {
+	target  textinput.Model
+	headers textinput.Model
+	ingest  string
+	visible bool
+}
+
+func newReplayForm() replayForm {
+	target := textinput.New()
+	target.Prompt = "Target URL: "
+	target.Placeholder = "http://localhost:3000/webhooks"
+
+	headers := textinput.New()
+	headers.Prompt = "Headers:    "
+	headers.Placeholder = "X-Signature: abc; Authorization: Bearer token"
+
+	return replayForm{
+		target:  target,
+		headers: headers,
+	}
+}
+
+func (f *replayForm) open(ingestID string) tea.Cmd {
+	f.ingest = ingestID
+	f.visible = true
+	f.headers.Blur()
+	return f.target.Focus()
+}
+
+func (f *replayForm) close() {
+	f.visible = false
+	f.target.Blur()
+	f.headers.Blur()
+}
+
+func (f *replayForm) toggleFocus() tea.Cmd {
+	if f.target.Focused() {
+		f.target.Blur()
+		return f.headers.Focus()
+	}
+
+	f.headers.Blur()
+	return f.target.Focus()
+}
+
+func (f replayForm) update(msg tea.Msg) (replayForm, tea.Cmd) {
+	var targetCmd, headersCmd tea.Cmd
+
+	f.target, targetCmd = f.target.Update(msg)
+	f.headers, headersCmd = f.headers.Update(msg)
+
+	return f, tea.Batch(targetCmd, headersCmd)
+}
+
+func (f replayForm) view(st styles) string {
+	return st.overlay.Render(lipgloss.JoinVertical(lipgloss.Left,
+		st.boldenString("Replay request "+f.ingest, false),
+		f.target.View(),
+		f.headers.View(),
+		st.makeString("tab to switch fields, enter to send, esc to cancel", true),
+	))
+}
+
+// parseHeaderOverrides parses headers in the form "Key: Value; Key2: Value2"
+func parseHeaderOverrides(s string) (map[string]string, error) {
+	headers := make(map[string]string)
+
+	for _, pair := range strings.Split(s, ";") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+
+		key, value, found := strings.Cut(pair, ":")
+		if !found || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("invalid header %q. Use the Key: Value format", strings.TrimSpace(pair))
+		}
+
+		headers[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+
+	return headers, nil
+}
+
+func (m model) replay() tea.Cmd {
+	ingestID := m.replayForm.ingest
+	target := strings.TrimSpace(m.replayForm.target.Value())
+
+	headers, err := parseHeaderOverrides(m.replayForm.headers.Value())
+	if err != nil {
+		return func() tea.Msg { return ReplayMsg{err: err} }
+	}
+
+	if target == "" {
+		return func() tea.Msg { return ReplayMsg{err: errors.New("please provide a target url")} }
+	}
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		replay, err := m.apiClient.Replay(ctx, ingestID, &client.ReplayOptions{
+			TargetURL: target,
+			Headers:   headers,
+		})
+
+		return ReplayMsg{replay: replay, err: err}
+	}
+}
+
+func replayStatus(msg ReplayMsg) string {
+	if msg.err != nil {
+		return fmt.Sprintf("Replay failed: %v", msg.err)
+	}
+
+	if msg.replay.Error != "" {
+		return fmt.Sprintf("Replay to %s failed: %s", msg.replay.TargetURL, msg.replay.Error)
+	}
+
+	return fmt.Sprintf("Replayed to %s: %d %s in %s (%s)",
+		msg.replay.TargetURL,
+		msg.replay.Response.StatusCode,
+		http.StatusText(msg.replay.Response.StatusCode),
+		msg.replay.Response.Duration.Round(time.Millisecond),
+		responseSize(msg.replay.Response))
+}
+
+// responseSize shows the size of a replayed response and if only the start
+// of it was kept
+func responseSize(r sdump.ResponseDefinition) string {
+	size := humanize.Bytes(uint64(r.Size))
+	if r.Truncated {
+		size += ", truncated"
+	}
+
+	return size
+}
@@ -0,0 +1,227 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/ayinke-llc/sdump"
+	"github.com/ayinke-llc/sdump/internal/filter"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// historyPageSize is how many older matches are fetched from the server at
+// a time
+const historyPageSize = 50
+
+// search filters the request list with the query language of the filter
+// package. Every received request is kept so the list can be rebuilt when
+// the query changes
+type search struct {
+	input   textinput.Model
+	editing bool
+
+	query *filter.Query
+
+	// items are all requests received or fetched, newest first
+	items []item
+	seen  map[string]struct{}
+
+	loading bool
+	// cursor is the opaque token the next page of history starts from. It
+	// is empty when the server has no older matches
+	cursor string
+}
+
+func newSearch() search {
+	input := textinput.New()
+	input.Prompt = "/"
+	input.Placeholder = "method:POST header:x-github-event=push body.data.id=123 ip:10.0.0.0/8"
+
+	return search{
+		input: input,
+		query: &filter.Query{},
+		seen:  make(map[string]struct{}),
+	}
+}
+
+func (s *search) open() tea.Cmd {
+	s.editing = true
+	s.input.SetValue(s.query.Raw)
+	s.input.CursorEnd()
+	return s.input.Focus()
+}
+
+func (s *search) close() {
+	s.editing = false
+	s.input.Blur()
+}
+
+// add records a request and reports whether it was not known before
+func (s *search) add(i item) bool {
+	if _, ok := s.seen[i.ID]; ok {
+		return false
+	}
+
+	s.seen[i.ID] = struct{}{}
+	s.items = append(s.items, i)
+	return true
+}
+
+func (s *search) reset() {
+	s.items = nil
+	s.seen = make(map[string]struct{})
+	s.cursor = ""
+	s.loading = false
+}
+
+func (s search) matches(i item) bool {
+	return s.query.Match(filter.Request{ID: i.ID, Request: i.Request})
+}
+
+func (s search) filtered() []list.Item {
+	items := []list.Item{}
+
+	for _, i := range s.items {
+		if s.matches(i) {
+			items = append(items, i)
+		}
+	}
+
+	return items
+}
+
+func itemFromIngest(ingest sdump.IngestHTTPRequest) item {
+	return item{
+		ID:        ingest.ID.String(),
+		Request:   ingest.Request,
+		CreatedAt: ingest.CreatedAt,
+		Starred:   ingest.Starred,
+		Note:      ingest.Note,
+		Tags:      ingest.Tags,
+	}
+}
+
+func (m model) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+
+	case tea.KeyEsc:
+		m.search.close()
+		return m, nil
+
+	case tea.KeyEnter:
+		m.search.close()
+
+		query, err := filter.Parse(m.search.input.Value())
+		if err != nil {
+			m.status = fmt.Sprintf("Invalid filter: %v", err)
+			return m, nil
+		}
+
+		m.search.query = query
+		m.search.cursor = ""
+		m.refreshList()
+
+		if query.IsEmpty() {
+			m.status = ""
+			return m, nil
+		}
+
+		return m, m.fetchHistory("")
+	}
+
+	var cmd tea.Cmd
+	m.search.input, cmd = m.search.input.Update(msg)
+	return m, cmd
+}
+
+// refreshList rebuilds the request list from every known request
+func (m *model) refreshList() {
+	sort.SliceStable(m.search.items, func(i, j int) bool {
+		return m.search.items[i].CreatedAt.After(m.search.items[j].CreatedAt)
+	})
+
+	items := m.search.filtered()
+
+	// requests held back while paused stay hidden
+	if len(m.feed.buffered) > 0 {
+		items = slices.DeleteFunc(items, func(listItem list.Item) bool {
+			return m.feed.isBuffered(listItem.(item).ID)
+		})
+	}
+
+	m.requestList.SetItems(items)
+	m.refreshTitle()
+}
+
+func (m *model) refreshTitle() {
+	m.requestList.Title = "Incoming requests"
+
+	if !m.search.query.IsEmpty() {
+		m.requestList.Title = fmt.Sprintf("Incoming requests matching %s (%d)",
+			m.search.query.Raw, len(m.requestList.Items()))
+	}
+
+	m.requestList.Title += m.feedTitle()
+}
+
+// fetchHistory asks the server for older requests matching the active
+// filter. An empty cursor starts from the latest request
+func (m *model) fetchHistory(cursor string) tea.Cmd {
+	m.search.loading = true
+	m.status = "Searching history..."
+
+	query := m.search.query.Raw
+	reference := m.reference
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		result, err := m.apiClient.SearchIngests(ctx, reference, query, cursor, historyPageSize)
+		return HistoryMsg{reference: reference, query: query, result: result, err: err}
+	}
+}
+
+func (m *model) mergeHistory(msg HistoryMsg) {
+	// the filter or the tab in view changed while the request was in flight
+	if msg.reference != m.reference {
+		if t := m.backgroundTab(withReference(msg.reference)); t != nil {
+			t.search.loading = false
+		}
+
+		return
+	}
+
+	if msg.query != m.search.query.Raw {
+		return
+	}
+
+	m.search.loading = false
+
+	if msg.err != nil {
+		m.status = fmt.Sprintf("Could not search history: %v", msg.err)
+		return
+	}
+
+	added := 0
+	for _, ingest := range msg.result.Ingests {
+		if m.search.add(itemFromIngest(ingest)) {
+			added++
+		}
+	}
+
+	m.search.cursor = msg.result.Cursor
+	m.refreshList()
+
+	m.status = fmt.Sprintf("Found %d older requests matching %s", added, msg.query)
+	if m.search.cursor != "" {
+		m.status += fmt.Sprintf(". Press %s to search further back", m.keys.LoadMore.Help().Key)
+	}
+}
@@ -0,0 +1,293 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	chromastyles "github.com/alecthomas/chroma/v2/styles"
+	"github.com/ayinke-llc/sdump"
+	"github.com/ayinke-llc/sdump/internal/inspect"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const defaultTimestampFormat = "default"
+
+// clock shows timestamps in the format and timezone the user picked
+type clock struct {
+	layout string
+	// location is nil when timestamps are shown as the server sent them
+	location *time.Location
+}
+
+func newClock(format, timezone string) (clock, error) {
+	layout, ok := sdump.TimestampFormats[format]
+	if !ok {
+		layout = sdump.TimestampFormats[defaultTimestampFormat]
+	}
+
+	c := clock{layout: layout}
+
+	if timezone == "" {
+		return c, nil
+	}
+
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return c, fmt.Errorf("%s is not a valid timezone", timezone)
+	}
+
+	c.location = location
+	return c, nil
+}
+
+func (c clock) format(t time.Time) string {
+	if c.location != nil {
+		t = t.In(c.location)
+	}
+
+	return t.Format(c.layout)
+}
+
+// setClock shows the timestamps of the list with the clock
+func (m *model) setClock(c clock) {
+	m.clock = c
+	m.requestList.SetDelegate(newItemDelegate(m.styles, c))
+}
+
+const (
+	colorSchemeSetting = iota
+	timestampFormatSetting
+	timezoneSetting
+	bodyViewSetting
+	keymapPresetSetting
+)
+
+// choice is a setting picked from a fixed list of options. The empty option
+// falls back to the config of the server
+type choice struct {
+	label    string
+	options  []string
+	selected int
+}
+
+func (c choice) value() string { return c.options[c.selected] }
+
+func (c *choice) set(value string) {
+	c.selected = 0
+
+	for i, option := range c.options {
+		if option == value {
+			c.selected = i
+		}
+	}
+}
+
+func (c *choice) cycle(step int) {
+	c.selected = (c.selected + step + len(c.options)) % len(c.options)
+}
+
+// settingsForm edits the preferences of the user. They are saved on the
+// server so they follow the user to every session
+type settingsForm struct {
+	visible bool
+	focus   int
+	choices map[int]*choice
+	// timezone is typed since there are too many to cycle through
+	timezone textinput.Model
+}
+
+func newSettingsForm() settingsForm {
+	colorSchemes := append([]string{""}, chromastyles.Names()...)
+
+	timestampFormats := []string{""}
+	for name := range sdump.TimestampFormats {
+		timestampFormats = append(timestampFormats, name)
+	}
+
+	sort.Strings(timestampFormats)
+
+	bodyViews := []string{""}
+	for _, format := range inspect.Formats {
+		bodyViews = append(bodyViews, string(format))
+	}
+
+	timezone := textinput.New()
+	timezone.Prompt = ""
+	timezone.Placeholder = "Africa/Lagos"
+
+	return settingsForm{
+		choices: map[int]*choice{
+			colorSchemeSetting:     {label: "Color scheme", options: colorSchemes},
+			timestampFormatSetting: {label: "Timestamps", options: timestampFormats},
+			bodyViewSetting:        {label: "Body view", options: bodyViews},
+			keymapPresetSetting:    {label: "Keymap", options: append([]string{""}, sdump.KeymapPresets...)},
+		},
+		timezone: timezone,
+	}
+}
+
+func (f *settingsForm) open(p *sdump.Preferences) {
+	f.visible = true
+	f.focus = colorSchemeSetting
+
+	f.choices[colorSchemeSetting].set(p.ColorScheme)
+	f.choices[timestampFormatSetting].set(p.TimestampFormat)
+	f.choices[bodyViewSetting].set(p.BodyView)
+	f.choices[keymapPresetSetting].set(p.KeymapPreset)
+
+	f.timezone.SetValue(p.Timezone)
+	f.timezone.CursorEnd()
+	f.timezone.Blur()
+}
+
+func (f *settingsForm) close() {
+	f.visible = false
+	f.timezone.Blur()
+}
+
+func (f *settingsForm) move(step int) tea.Cmd {
+	f.focus = (f.focus + step + keymapPresetSetting + 1) % (keymapPresetSetting + 1)
+
+	if f.focus == timezoneSetting {
+		return f.timezone.Focus()
+	}
+
+	f.timezone.Blur()
+	return nil
+}
+
+func (f settingsForm) preferences() *sdump.Preferences {
+	return &sdump.Preferences{
+		ColorScheme:     f.choices[colorSchemeSetting].value(),
+		TimestampFormat: f.choices[timestampFormatSetting].value(),
+		Timezone:        strings.TrimSpace(f.timezone.Value()),
+		BodyView:        f.choices[bodyViewSetting].value(),
+		KeymapPreset:    f.choices[keymapPresetSetting].value(),
+	}
+}
+
+func (f settingsForm) view(st styles) string {
+	lines := []string{st.boldenString("Settings", false)}
+
+	for setting := colorSchemeSetting; setting <= keymapPresetSetting; setting++ {
+		var label, value string
+
+		if setting == timezoneSetting {
+			label, value = "Timezone", f.timezone.View()
+		} else {
+			c := f.choices[setting]
+			label, value = c.label, c.value()
+
+			if value == "" {
+				value = "server default"
+				if setting == bodyViewSetting {
+					value = "detect"
+				}
+			}
+
+			value = "‹ " + value + " ›"
+		}
+
+		line := fmt.Sprintf("%-14s %s", label+":", value)
+		if setting == f.focus {
+			lines = append(lines, st.key.Render("> "+line))
+			continue
+		}
+
+		lines = append(lines, "  "+line)
+	}
+
+	lines = append(lines, st.makeString(
+		"up/down to move, left/right to change, enter to save, esc to cancel. Settings are kept for your next session", true))
+
+	return st.overlay.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
+
+func (m model) updateSettingsForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+
+	case tea.KeyEsc:
+		m.settingsForm.close()
+		return m, nil
+
+	case tea.KeyUp, tea.KeyShiftTab:
+		return m, m.settingsForm.move(-1)
+
+	case tea.KeyDown, tea.KeyTab:
+		return m, m.settingsForm.move(1)
+
+	case tea.KeyEnter:
+		m.settingsForm.close()
+		m.status = "Saving settings..."
+		return m, m.savePreferences(m.settingsForm.preferences())
+	}
+
+	if m.settingsForm.focus == timezoneSetting {
+		var cmd tea.Cmd
+		m.settingsForm.timezone, cmd = m.settingsForm.timezone.Update(msg)
+		return m, cmd
+	}
+
+	switch msg.Type {
+	case tea.KeyLeft:
+		m.settingsForm.choices[m.settingsForm.focus].cycle(-1)
+	case tea.KeyRight:
+		m.settingsForm.choices[m.settingsForm.focus].cycle(1)
+	}
+
+	return m, nil
+}
+
+func (m model) savePreferences(preferences *sdump.Preferences) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		saved, err := m.apiClient.SavePreferences(ctx, preferences)
+		return PreferencesMsg{preferences: saved, err: err}
+	}
+}
+
+// applyPreferences sets up the session with the preferences of the user.
+// Empty fields fall back to the config of the server
+func (m *model) applyPreferences(p *sdump.Preferences) error {
+	m.preferences = p
+
+	m.colorscheme = m.cfg.TUI.ColorScheme
+	if p.ColorScheme != "" {
+		m.colorscheme = p.ColorScheme
+	}
+
+	// NO_COLOR wins over the color scheme of the user
+	if m.styles.theme.monochrome {
+		m.colorscheme = monochromeColorScheme
+	}
+
+	m.bodyFormatOverride = inspect.Format(p.BodyView)
+
+	c, err := newClock(p.TimestampFormat, p.Timezone)
+	m.setClock(c)
+	if err != nil {
+		return err
+	}
+
+	keys := m.cfg.TUI.Keys
+	if p.KeymapPreset != "" {
+		keys.Preset = p.KeymapPreset
+	}
+
+	k, err := newKeyMap(keys)
+	if err != nil {
+		return fmt.Errorf("could not use the %s keymap: %w", keys.Preset, err)
+	}
+
+	m.setKeyMap(k)
+	return nil
+}
@@ -0,0 +1,237 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ayinke-llc/sdump"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dustin/go-humanize"
+)
+
+const (
+	// statsMinutes is how far back the sparkline goes
+	statsMinutes = 30
+	// statsTopIPs is how many source ips are shown
+	statsTopIPs = 3
+	// statsTopPaths is how many paths are shown
+	statsTopPaths = 3
+)
+
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// trafficStats summarizes the traffic of the endpoint. It is seeded from the
+// server when the endpoint is set up and updated by every request that
+// arrives after that
+type trafficStats struct {
+	seeded bool
+	// requested is when the seed was asked for. The server already counted
+	// the requests that arrived before it
+	requested time.Time
+	// pending are the requests that arrived before the seed
+	pending []item
+
+	total     int64
+	totalSize int64
+	maxSize   int64
+	methods   map[string]int64
+	paths     map[string]int64
+	ips       map[string]int64
+	// perMinute is keyed by the minutes since the unix epoch
+	perMinute map[int64]int64
+}
+
+func newTrafficStats() trafficStats {
+	return trafficStats{
+		methods:   map[string]int64{},
+		paths:     map[string]int64{},
+		ips:       map[string]int64{},
+		perMinute: map[int64]int64{},
+	}
+}
+
+func unixMinute(t time.Time) int64 { return t.Unix() / 60 }
+
+func (s *trafficStats) add(i item) {
+	if !s.seeded {
+		s.pending = append(s.pending, i)
+		return
+	}
+
+	s.total++
+	s.totalSize += i.Request.Size
+	s.maxSize = max(s.maxSize, i.Request.Size)
+	s.methods[i.Request.Method]++
+	s.paths[statsPath(i.Request)]++
+	s.perMinute[unixMinute(i.CreatedAt)]++
+
+	if i.Request.IPAddress != nil {
+		s.ips[i.Request.IPAddress.String()]++
+	}
+}
+
+// seed replaces the counts with the aggregates of the server and adds the
+// requests it could not have seen
+func (s *trafficStats) seed(stats *sdump.IngestStats) {
+	pending := s.pending
+	requested := s.requested
+
+	*s = newTrafficStats()
+	s.seeded = true
+	s.total = stats.Total
+	s.totalSize = stats.TotalSize
+	s.maxSize = stats.MaxSize
+
+	for method, count := range stats.Methods {
+		s.methods[method] = count
+	}
+
+	for _, path := range stats.TopPaths {
+		s.paths[path.Path] = path.Count
+	}
+
+	for _, ip := range stats.TopIPs {
+		s.ips[ip.IP] = ip.Count
+	}
+
+	for _, minute := range stats.PerMinute {
+		s.perMinute[unixMinute(minute.Minute)] = minute.Count
+	}
+
+	for _, i := range pending {
+		if i.CreatedAt.After(requested) {
+			s.add(i)
+		}
+	}
+}
+
+// statsPath is the path a request is counted under. Requests sent to the
+// endpoint itself are counted as / like the server does
+func statsPath(def sdump.RequestDefinition) string {
+	if def.Path == "" {
+		return "/"
+	}
+
+	return def.Path
+}
+
+// sparkline shows the requests of the last minutes, oldest first
+func (s trafficStats) sparkline(now time.Time, minutes int) (string, int64) {
+	current := unixMinute(now)
+
+	counts := make([]int64, minutes)
+
+	var peak int64
+	for i := range counts {
+		counts[i] = s.perMinute[current-int64(minutes-1-i)]
+		peak = max(peak, counts[i])
+	}
+
+	var b strings.Builder
+	for _, count := range counts {
+		// the lowest bar is kept for minutes without requests
+		if count == 0 {
+			b.WriteRune(sparks[0])
+			continue
+		}
+
+		if peak == 1 {
+			b.WriteRune(sparks[len(sparks)-1])
+			continue
+		}
+
+		b.WriteRune(sparks[1+int((count-1)*int64(len(sparks)-2)/(peak-1))])
+	}
+
+	return b.String(), peak
+}
+
+type counted struct {
+	name  string
+	count int64
+}
+
+// busiest sorts the counts from the highest, then by name
+func busiest(counts map[string]int64) []counted {
+	sorted := make([]counted, 0, len(counts))
+	for name, count := range counts {
+		sorted = append(sorted, counted{name, count})
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].count != sorted[j].count {
+			return sorted[i].count > sorted[j].count
+		}
+
+		return sorted[i].name < sorted[j].name
+	})
+
+	return sorted
+}
+
+// renderStats shows the traffic of the endpoint in two lines
+func (m model) renderStats() string {
+	if !m.stats.seeded {
+		return m.styles.makeString("Loading traffic stats...", true)
+	}
+
+	now := time.Now()
+
+	spark, peak := m.stats.sparkline(now, min(statsMinutes, max(m.width/3, 10)))
+
+	var average int64
+	if m.stats.total > 0 {
+		average = m.stats.totalSize / m.stats.total
+	}
+
+	traffic := fmt.Sprintf("%s  %d/min now, peak %d/min   %d requests   avg %s   max %s",
+		m.styles.key.Render(spark), m.stats.perMinute[unixMinute(now)], peak, m.stats.total,
+		humanize.Bytes(uint64(average)), humanize.Bytes(uint64(m.stats.maxSize)))
+
+	var methods []string
+	for _, method := range busiest(m.stats.methods) {
+		methods = append(methods, fmt.Sprintf("%s %d", m.styles.method(method.name), method.count))
+	}
+
+	var paths []string
+	for i, path := range busiest(m.stats.paths) {
+		if i == statsTopPaths {
+			break
+		}
+
+		paths = append(paths, fmt.Sprintf("%s (%d)", path.name, path.count))
+	}
+
+	var ips []string
+	for i, ip := range busiest(m.stats.ips) {
+		if i == statsTopIPs {
+			break
+		}
+
+		ips = append(ips, fmt.Sprintf("%s (%d)", ip.name, ip.count))
+	}
+
+	breakdown := strings.Join(methods, "  ")
+	if len(paths) > 0 {
+		breakdown += m.styles.makeString("   top paths ", true) + strings.Join(paths, ", ")
+	}
+
+	if len(ips) > 0 {
+		breakdown += m.styles.makeString("   top ips ", true) + strings.Join(ips, ", ")
+	}
+
+	return traffic + "\n" + breakdown
+}
+
+func (m model) fetchStats(reference string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		stats, err := m.apiClient.Stats(ctx, reference, statsMinutes)
+		return StatsMsg{reference: reference, stats: stats, err: err}
+	}
+}
@@ -0,0 +1,243 @@
+package tui
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/r3labs/sse/v2"
+	"gopkg.in/cenkalti/backoff.v1"
+)
+
+const (
+	// maxReconnectInterval is the longest wait between two attempts to
+	// reconnect to the event stream
+	maxReconnectInterval = 30 * time.Second
+	// offlineAfter is how long the stream can be down before the session
+	// is shown as offline. Reconnecting goes on in the background
+	offlineAfter = time.Minute
+)
+
+type connectionState int
+
+const (
+	connecting connectionState = iota
+	connected
+	reconnecting
+	offline
+)
+
+func (s connectionState) String() string {
+	switch s {
+	case connected:
+		return "live"
+	case reconnecting:
+		return "reconnecting"
+	case offline:
+		return "offline"
+	default:
+		return "connecting"
+	}
+}
+
+// connection is the state of the subscription to the events of the
+// endpoint
+type connection struct {
+	// channel is the sse channel of the endpoint. Messages from the
+	// subscription of a previous endpoint are ignored
+	channel string
+	cancel  context.CancelFunc
+
+	state connectionState
+	err   error
+	// retryAt is when the next attempt to reconnect happens
+	retryAt time.Time
+	// malformed counts the events that could not be decoded
+	malformed int
+}
+
+// subscribe listens to the events of the endpoint until the endpoint
+// changes. Events are delivered through m.events
+func (m *model) subscribe(channel string) {
+	if m.connection.cancel != nil {
+		m.connection.cancel()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	m.connection = connection{
+		channel: channel,
+		cancel:  cancel,
+	}
+
+	go m.listen(ctx, channel)
+}
+
+// listen reconnects with an exponential backoff whenever the stream drops.
+// The client sends the id of the last event it received so the server
+// replays what was missed. Replayed requests are deduplicated by their id
+func (m model) listen(ctx context.Context, channel string) {
+	send := func(msg tea.Msg) {
+		select {
+		case m.events <- msg:
+		case <-ctx.Done():
+		}
+	}
+
+	strategy := backoff.NewExponentialBackOff()
+	strategy.MaxInterval = maxReconnectInterval
+	strategy.MaxElapsedTime = 0
+
+	var disconnectedAt time.Time
+
+	notify := func(err error, next time.Duration) {
+		if disconnectedAt.IsZero() {
+			disconnectedAt = time.Now()
+		}
+
+		state := reconnecting
+		if time.Since(disconnectedAt) > offlineAfter {
+			state = offline
+		}
+
+		send(ConnectionMsg{channel: channel, state: state, err: err, retryIn: next})
+	}
+
+	// every endpoint gets its own client so the id of the last event is
+	// never sent to the stream of another endpoint
+	client := sse.NewClient(fmt.Sprintf("%s/events", m.cfg.HTTP.Domain))
+	client.ReconnectStrategy = backoff.WithContext(strategy, ctx)
+	client.ReconnectNotify = notify
+	client.ResponseValidator = func(_ *sse.Client, resp *http.Response) error {
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return fmt.Errorf("could not connect to the event stream: %s", resp.Status)
+		}
+
+		strategy.Reset()
+		disconnectedAt = time.Time{}
+
+		send(ConnectionMsg{channel: channel, state: connected})
+		return nil
+	}
+
+	for {
+		err := client.SubscribeWithContext(ctx, channel, func(msg *sse.Event) {
+			var i item
+
+			if err := json.Unmarshal(msg.Data, &i); err != nil {
+				send(MalformedEventMsg{channel: channel, id: string(msg.ID), err: err})
+				return
+			}
+
+			if i.ID == "" {
+				send(MalformedEventMsg{channel: channel, id: string(msg.ID), err: errors.New("the event has no request id")})
+				return
+			}
+
+			send(ItemMsg{channel: channel, item: i})
+		})
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		// the server closed the stream without an error
+		if err == nil {
+			err = errors.New("the server closed the event stream")
+		}
+
+		next := strategy.NextBackOff()
+		notify(err, next)
+
+		select {
+		case <-time.After(next):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (m model) waitForEvent() tea.Msg {
+	return <-m.events
+}
+
+func (c *connection) apply(msg ConnectionMsg) {
+	c.state = msg.state
+	c.err = msg.err
+	c.retryAt = time.Now().Add(msg.retryIn)
+}
+
+func (m *model) applyConnection(msg ConnectionMsg) {
+	if msg.channel != m.connection.channel {
+		if t := m.backgroundTab(onChannel(msg.channel)); t != nil {
+			t.connection.apply(msg)
+		}
+
+		return
+	}
+
+	m.connection.apply(msg)
+}
+
+// applyMalformedEvent counts the events that could not be decoded. Only
+// those of the tab in view are reported in the status
+func (m *model) applyMalformedEvent(msg MalformedEventMsg) {
+	if msg.channel != m.connection.channel {
+		if t := m.backgroundTab(onChannel(msg.channel)); t != nil {
+			t.connection.malformed++
+		}
+
+		return
+	}
+
+	m.connection.malformed++
+
+	id := msg.id
+	if id == "" {
+		id = "without an id"
+	}
+
+	m.status = fmt.Sprintf("Skipped malformed event %s: %v", id, msg.err)
+}
+
+// connectionIndicator shows if new requests are arriving
+func (m model) connectionIndicator() string {
+	c := m.connection
+
+	var indicator string
+
+	switch c.state {
+	case connected:
+		indicator = m.styles.success.Render("● " + c.state.String())
+
+	case reconnecting, offline:
+		style := m.styles.warning
+		if c.state == offline {
+			style = m.styles.danger
+		}
+
+		indicator = style.Render("○ " + c.state.String())
+
+		if wait := time.Until(c.retryAt).Round(time.Second); wait > 0 {
+			indicator += m.styles.makeString(fmt.Sprintf(", retrying in %s", wait), true)
+		}
+
+		if c.err != nil {
+			indicator += m.styles.makeString(fmt.Sprintf(" (%v)", c.err), true)
+		}
+
+	default:
+		indicator = m.styles.makeString("◌ "+c.state.String(), true)
+	}
+
+	if c.malformed > 0 {
+		indicator += m.styles.warning.Render(fmt.Sprintf("  %d malformed events skipped", c.malformed))
+	}
+
+	return indicator
+}
@@ -0,0 +1,228 @@
+package tui
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// endpointTab is an endpoint watched in its own tab. The model holds the
+// state of the tab in view. The other tabs keep theirs here and are updated
+// as their requests arrive
+type endpointTab struct {
+	dumpURL    *url.URL
+	pubChannel string
+	reference  string
+
+	requestList list.Model
+	search      search
+	stats       trafficStats
+	connection  connection
+	marked      []string
+	// buffered arrived while paused
+	buffered []item
+	// detailOffset is how far the details of the selected request were
+	// scrolled
+	detailOffset int
+
+	// unread counts the requests that arrived while the tab was not in view
+	unread int
+}
+
+func newRequestList(height int) list.Model {
+	l := list.New([]list.Item{}, list.NewDefaultDelegate(), 50, height)
+	l.Title = "Incoming requests"
+	l.SetShowTitle(true)
+	l.SetFilteringEnabled(false)
+	l.DisableQuitKeybindings()
+	return l
+}
+
+func newEndpointTab(height int) endpointTab {
+	return endpointTab{
+		requestList: newRequestList(height),
+		search:      newSearch(),
+		stats:       newTrafficStats(),
+	}
+}
+
+// stashTab saves the state of the tab in view
+func (m *model) stashTab() {
+	m.tabs[m.activeTab] = endpointTab{
+		dumpURL:      m.dumpURL,
+		pubChannel:   m.pubChannel,
+		reference:    m.reference,
+		requestList:  m.requestList,
+		search:       m.search,
+		stats:        m.stats,
+		connection:   m.connection,
+		marked:       m.marked,
+		buffered:     m.feed.buffered,
+		detailOffset: m.detailedRequestView.YOffset,
+	}
+}
+
+// loadTab brings the tab into view. The list is styled again as the
+// settings might have changed while it was hidden
+func (m *model) loadTab(i int) {
+	t := m.tabs[i]
+
+	m.activeTab = i
+	m.tabs[i].unread = 0
+
+	m.dumpURL = t.dumpURL
+	m.pubChannel = t.pubChannel
+	m.reference = t.reference
+	m.requestList = t.requestList
+	m.search = t.search
+	m.stats = t.stats
+	m.connection = t.connection
+	m.marked = t.marked
+	m.feed.buffered = t.buffered
+	m.detailedRequestView.YOffset = t.detailOffset
+
+	// the restored offset belongs to the request selected in the tab
+	m.detailItem = ""
+	if selectedItem, ok := m.requestList.SelectedItem().(item); ok {
+		m.detailItem = selectedItem.ID
+	}
+
+	m.requestList.SetDelegate(newItemDelegate(m.styles, m.clock))
+	m.requestList.Styles = m.styles.listStyles()
+	m.requestList.KeyMap = m.keys.listKeyMap()
+	m.refreshTitle()
+	m.resize()
+}
+
+// openTab watches a new url in a tab of its own
+func (m *model) openTab() tea.Cmd {
+	m.stashTab()
+	m.tabs = append(m.tabs, newEndpointTab(m.height))
+	m.loadTab(len(m.tabs) - 1)
+	m.status = ""
+
+	return m.createEndpoint(true)
+}
+
+// closeTab stops watching the endpoint in view. The last tab cannot be
+// closed
+func (m *model) closeTab() {
+	if len(m.tabs) == 1 {
+		m.status = fmt.Sprintf("This is the only tab. Press %s to quit", m.keys.Quit.Help().Key)
+		return
+	}
+
+	if m.connection.cancel != nil {
+		m.connection.cancel()
+	}
+
+	status := "Stopped watching the new url"
+	if m.dumpURL != nil {
+		status = fmt.Sprintf("Stopped watching %s", m.dumpURL)
+	}
+
+	closed := m.activeTab
+	m.tabs = append(m.tabs[:closed], m.tabs[closed+1:]...)
+	m.loadTab(min(closed, len(m.tabs)-1))
+	m.status = status
+}
+
+// switchTab moves by offset through the tabs, wrapping around at both ends
+func (m *model) switchTab(offset int) {
+	if len(m.tabs) == 1 {
+		return
+	}
+
+	m.stashTab()
+	m.loadTab((m.activeTab + offset + len(m.tabs)) % len(m.tabs))
+	m.status = ""
+}
+
+// backgroundTab finds the tab that is not in view and matches
+func (m *model) backgroundTab(match func(t endpointTab) bool) *endpointTab {
+	for i := range m.tabs {
+		if i != m.activeTab && match(m.tabs[i]) {
+			return &m.tabs[i]
+		}
+	}
+
+	return nil
+}
+
+func onChannel(channel string) func(t endpointTab) bool {
+	return func(t endpointTab) bool { return t.connection.channel == channel }
+}
+
+func withReference(reference string) func(t endpointTab) bool {
+	return func(t endpointTab) bool { return t.reference == reference }
+}
+
+// addToBackground records a request of a tab that is not in view
+func (m *model) addToBackground(t *endpointTab, i item) {
+	if !t.search.add(i) {
+		return
+	}
+
+	t.stats.add(i)
+	m.announce(t.dumpURL, i)
+
+	if !t.search.matches(i) {
+		return
+	}
+
+	t.unread++
+
+	if m.feed.paused {
+		t.buffered = append(t.buffered, i)
+		return
+	}
+
+	insertNewest(&t.requestList, i, m.feed.follow)
+}
+
+// renderTabs lists the tabs with their unread requests. Nothing is shown
+// while only one endpoint is watched
+func (m model) renderTabs() string {
+	if len(m.tabs) < 2 {
+		return ""
+	}
+
+	tabs := make([]string, 0, len(m.tabs))
+
+	for i, t := range m.tabs {
+		if i == m.activeTab {
+			t.dumpURL = m.dumpURL
+			t.connection = m.connection
+		}
+
+		name := "new url..."
+		if t.dumpURL != nil {
+			name = t.dumpURL.Host
+		}
+
+		label := fmt.Sprintf("%d %s", i+1, name)
+		if t.connection.state == reconnecting || t.connection.state == offline {
+			label += " " + t.connection.state.String()
+		}
+
+		if t.unread > 0 {
+			label += fmt.Sprintf(" (%d)", t.unread)
+		}
+
+		style := m.styles.inactiveTab
+		if i == m.activeTab {
+			style = m.styles.activeTab
+		}
+
+		tabs = append(tabs, style.Render(label))
+	}
+
+	hint := fmt.Sprintf("   %s and %s switch urls", m.keys.PreviousEndpoint.Help().Key,
+		m.keys.NextEndpoint.Help().Key)
+
+	return lipgloss.JoinHorizontal(lipgloss.Top, tabs...) +
+		m.styles.makeString(hint, true)
+}
@@ -0,0 +1,423 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ayinke-llc/sdump/config"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	themeAuto       = "auto"
+	themeDark       = "dark"
+	themeLight      = "light"
+	themeMonochrome = "monochrome"
+
+	// monochromeColorScheme is the chroma style used to highlight bodies
+	// when colors are disabled. It only uses bold and italics
+	monochromeColorScheme = "bw"
+)
+
+// theme is the palette of the TUI. Request bodies are highlighted with the
+// chroma color scheme instead
+type theme struct {
+	name string
+	// monochrome themes rely on bold, underline and reverse video instead
+	// of colors
+	monochrome bool
+
+	text               lipgloss.TerminalColor
+	muted              lipgloss.TerminalColor
+	accent             lipgloss.TerminalColor
+	border             lipgloss.TerminalColor
+	errorColor         lipgloss.TerminalColor
+	success            lipgloss.TerminalColor
+	warning            lipgloss.TerminalColor
+	special            lipgloss.TerminalColor
+	selectedForeground lipgloss.TerminalColor
+	selectedBackground lipgloss.TerminalColor
+	spinner            lipgloss.TerminalColor
+}
+
+var themes = map[string]theme{
+	themeDark: {
+		name:               themeDark,
+		text:               lipgloss.Color("#FAFAFA"),
+		muted:              lipgloss.Color("#888888"),
+		accent:             lipgloss.Color("#428BCA"),
+		border:             lipgloss.Color("240"),
+		errorColor:         lipgloss.Color("9"),
+		success:            lipgloss.Color("114"),
+		warning:            lipgloss.Color("215"),
+		special:            lipgloss.Color("176"),
+		selectedForeground: lipgloss.Color("229"),
+		selectedBackground: lipgloss.Color("57"),
+		spinner:            lipgloss.Color("205"),
+	},
+	themeLight: {
+		name:               themeLight,
+		text:               lipgloss.Color("#111222"),
+		muted:              lipgloss.Color("#555555"),
+		accent:             lipgloss.Color("#1F6FB2"),
+		border:             lipgloss.Color("250"),
+		errorColor:         lipgloss.Color("160"),
+		success:            lipgloss.Color("28"),
+		warning:            lipgloss.Color("166"),
+		special:            lipgloss.Color("127"),
+		selectedForeground: lipgloss.Color("231"),
+		selectedBackground: lipgloss.Color("63"),
+		spinner:            lipgloss.Color("199"),
+	},
+	"dracula": {
+		name:               "dracula",
+		text:               lipgloss.Color("#F8F8F2"),
+		muted:              lipgloss.Color("#6272A4"),
+		accent:             lipgloss.Color("#BD93F9"),
+		border:             lipgloss.Color("#44475A"),
+		errorColor:         lipgloss.Color("#FF5555"),
+		success:            lipgloss.Color("#50FA7B"),
+		warning:            lipgloss.Color("#FFB86C"),
+		special:            lipgloss.Color("#FF79C6"),
+		selectedForeground: lipgloss.Color("#282A36"),
+		selectedBackground: lipgloss.Color("#BD93F9"),
+		spinner:            lipgloss.Color("#FF79C6"),
+	},
+	"nord": {
+		name:               "nord",
+		text:               lipgloss.Color("#ECEFF4"),
+		muted:              lipgloss.Color("#7B88A1"),
+		accent:             lipgloss.Color("#88C0D0"),
+		border:             lipgloss.Color("#4C566A"),
+		errorColor:         lipgloss.Color("#BF616A"),
+		success:            lipgloss.Color("#A3BE8C"),
+		warning:            lipgloss.Color("#D08770"),
+		special:            lipgloss.Color("#B48EAD"),
+		selectedForeground: lipgloss.Color("#2E3440"),
+		selectedBackground: lipgloss.Color("#88C0D0"),
+		spinner:            lipgloss.Color("#B48EAD"),
+	},
+	themeMonochrome: {
+		name:               themeMonochrome,
+		monochrome:         true,
+		text:               lipgloss.NoColor{},
+		muted:              lipgloss.NoColor{},
+		accent:             lipgloss.NoColor{},
+		border:             lipgloss.NoColor{},
+		errorColor:         lipgloss.NoColor{},
+		success:            lipgloss.NoColor{},
+		warning:            lipgloss.NoColor{},
+		special:            lipgloss.NoColor{},
+		selectedForeground: lipgloss.NoColor{},
+		selectedBackground: lipgloss.NoColor{},
+		spinner:            lipgloss.NoColor{},
+	},
+}
+
+// themeNames lists the themes for error messages
+func themeNames() string {
+	names := []string{themeAuto}
+	for name := range themes {
+		names = append(names, name)
+	}
+
+	sort.Strings(names[1:])
+	return strings.Join(names, ", ")
+}
+
+// colors names every color of the theme. The names are used in the
+// tui.colors section of the config file
+func (t *theme) colors() map[string]*lipgloss.TerminalColor {
+	return map[string]*lipgloss.TerminalColor{
+		"text":                &t.text,
+		"muted":               &t.muted,
+		"accent":              &t.accent,
+		"border":              &t.border,
+		"error":               &t.errorColor,
+		"success":             &t.success,
+		"warning":             &t.warning,
+		"special":             &t.special,
+		"selected_foreground": &t.selectedForeground,
+		"selected_background": &t.selectedBackground,
+		"spinner":             &t.spinner,
+	}
+}
+
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// parseColor accepts hex colors and ANSI 256 color numbers
+func parseColor(value string) (lipgloss.TerminalColor, error) {
+	if hexColor.MatchString(value) {
+		return lipgloss.Color(value), nil
+	}
+
+	if n, err := strconv.Atoi(value); err == nil && n >= 0 && n <= 255 {
+		return lipgloss.Color(value), nil
+	}
+
+	return nil, fmt.Errorf("%s is not a hex color or an ANSI color number between 0 and 255", value)
+}
+
+// detectTheme resolves the theme of a session from its environment.
+// NO_COLOR and dumb terminals always get the monochrome theme. auto picks
+// the light or dark theme from COLORFGBG which most terminals set to the
+// indexes of their foreground and background colors
+func detectTheme(name string, environ []string) string {
+	env := make(map[string]string, len(environ))
+	for _, v := range environ {
+		if key, value, ok := strings.Cut(v, "="); ok {
+			env[key] = value
+		}
+	}
+
+	if env["NO_COLOR"] != "" || env["TERM"] == "dumb" {
+		return themeMonochrome
+	}
+
+	if name != "" && name != themeAuto {
+		return name
+	}
+
+	parts := strings.Split(env["COLORFGBG"], ";")
+
+	background, err := strconv.Atoi(parts[len(parts)-1])
+	if err != nil {
+		return themeDark
+	}
+
+	// 7 is light gray and 9 to 15 are the bright colors
+	if background == 7 || (background >= 9 && background <= 15) {
+		return themeLight
+	}
+
+	return themeDark
+}
+
+// newTheme resolves the theme of a session and applies the color
+// overrides from the config on top of it
+func newTheme(cfg config.TUIConfig, environ []string) (theme, error) {
+	name := detectTheme(cfg.Theme, environ)
+
+	t, ok := themes[name]
+	if !ok {
+		return theme{}, fmt.Errorf("unknown theme %s. Use one of %s", name, themeNames())
+	}
+
+	// monochrome is requested by the user so it is never overridden
+	if t.monochrome {
+		return t, nil
+	}
+
+	colors := t.colors()
+
+	for colorName, value := range cfg.Colors {
+		target, ok := colors[colorName]
+		if !ok {
+			return theme{}, fmt.Errorf("unknown color %s in tui.colors", colorName)
+		}
+
+		c, err := parseColor(value)
+		if err != nil {
+			return theme{}, fmt.Errorf("tui.colors.%s: %w", colorName, err)
+		}
+
+		*target = c
+	}
+
+	return t, nil
+}
+
+// ValidateTheme reports if the theme or colors in the config are invalid so
+// the ssh server can refuse to start instead of failing every session
+func ValidateTheme(cfg config.TUIConfig) error {
+	_, err := newTheme(cfg, nil)
+	return err
+}
+
+// styles are built from a theme once per session since every session can
+// have a different theme
+type styles struct {
+	theme theme
+
+	text       lipgloss.Style
+	errorStyle lipgloss.Style
+	overlay    lipgloss.Style
+
+	pane        lipgloss.Style
+	focusedPane lipgloss.Style
+
+	activeTab   lipgloss.Style
+	inactiveTab lipgloss.Style
+
+	selected lipgloss.Style
+	key      lipgloss.Style
+	str      lipgloss.Style
+	number   lipgloss.Style
+	other    lipgloss.Style
+
+	added   lipgloss.Style
+	removed lipgloss.Style
+	changed lipgloss.Style
+
+	// success, warning and danger color short inline states
+	success lipgloss.Style
+	warning lipgloss.Style
+	danger  lipgloss.Style
+
+	spinner lipgloss.Style
+}
+
+func newStyles(t theme) styles {
+	s := styles{
+		theme: t,
+
+		text: lipgloss.NewStyle().Foreground(t.text),
+
+		errorStyle: lipgloss.NewStyle().BorderForeground(t.errorColor).
+			Border(lipgloss.RoundedBorder()).
+			Align(lipgloss.Center).
+			Margin(0, 0, 0, 1).
+			Padding(0, 2, 0, 2),
+
+		overlay: lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(t.accent).
+			Padding(0, 1),
+
+		pane: lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(t.muted),
+
+		activeTab: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(t.selectedForeground).
+			Background(t.selectedBackground).
+			Padding(0, 1),
+
+		inactiveTab: lipgloss.NewStyle().
+			Foreground(t.muted).
+			Padding(0, 1),
+
+		selected: lipgloss.NewStyle().
+			Foreground(t.selectedForeground).
+			Background(t.selectedBackground),
+
+		key:    lipgloss.NewStyle().Foreground(t.accent),
+		str:    lipgloss.NewStyle().Foreground(t.success),
+		number: lipgloss.NewStyle().Foreground(t.warning),
+		other:  lipgloss.NewStyle().Foreground(t.special),
+
+		added:   lipgloss.NewStyle().Foreground(t.success),
+		removed: lipgloss.NewStyle().Foreground(t.errorColor),
+		changed: lipgloss.NewStyle().Foreground(t.warning),
+
+		success: lipgloss.NewStyle().Foreground(t.success),
+		warning: lipgloss.NewStyle().Foreground(t.warning),
+		danger:  lipgloss.NewStyle().Foreground(t.errorColor).Bold(true),
+
+		spinner: lipgloss.NewStyle().Foreground(t.spinner),
+	}
+
+	s.focusedPane = s.pane.BorderForeground(t.accent)
+
+	if t.monochrome {
+		// the focused pane and the selection have to stand out without
+		// colors. Both borders are as wide so the layout does not shift
+		s.focusedPane = s.pane.Border(lipgloss.ThickBorder())
+		s.activeTab = s.activeTab.Reverse(true)
+		s.selected = s.selected.Reverse(true)
+		s.key = s.key.Bold(true)
+		s.removed = s.removed.Strikethrough(true)
+		s.changed = s.changed.Underline(true)
+	}
+
+	return s
+}
+
+// method colors the method of a request by what it does to the resource
+func (s styles) method(method string) string {
+	style := lipgloss.NewStyle().Foreground(s.theme.accent)
+
+	switch strings.ToUpper(method) {
+	case "GET", "HEAD", "OPTIONS":
+		style = style.Foreground(s.theme.success)
+	case "PUT", "PATCH":
+		style = style.Foreground(s.theme.warning)
+	case "DELETE":
+		style = style.Foreground(s.theme.errorColor)
+	}
+
+	if s.theme.monochrome {
+		style = style.Bold(true)
+	}
+
+	return style.Render(method)
+}
+
+func (s styles) listStyles() list.Styles {
+	l := list.DefaultStyles()
+	l.Title = l.Title.
+		Foreground(s.theme.selectedForeground).
+		Background(s.theme.selectedBackground)
+
+	if s.theme.monochrome {
+		l.Title = l.Title.Reverse(true)
+	}
+
+	return l
+}
+
+// itemDelegate renders requests with the colors of the theme
+type itemDelegate struct {
+	list.DefaultDelegate
+	styles *styles
+	clock  *clock
+}
+
+func newItemDelegate(s styles, c clock) itemDelegate {
+	d := list.NewDefaultDelegate()
+
+	d.Styles.NormalTitle = d.Styles.NormalTitle.Foreground(s.theme.text)
+	d.Styles.NormalDesc = d.Styles.NormalDesc.Foreground(s.theme.muted)
+	d.Styles.SelectedTitle = d.Styles.SelectedTitle.
+		BorderForeground(s.theme.accent).
+		Foreground(s.theme.accent)
+	d.Styles.SelectedDesc = d.Styles.SelectedDesc.
+		BorderForeground(s.theme.accent).
+		Foreground(s.theme.muted)
+
+	if s.theme.monochrome {
+		d.Styles.SelectedTitle = d.Styles.SelectedTitle.Bold(true)
+	}
+
+	return itemDelegate{DefaultDelegate: d, styles: &s, clock: &c}
+}
+
+func (d itemDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
+	if i, ok := listItem.(item); ok {
+		i.styles = d.styles
+		i.clock = d.clock
+		listItem = i
+	}
+
+	d.DefaultDelegate.Render(w, m, index, listItem)
+}
+
+// setStyles applies the styles to the session and the bubbles it uses
+func (m *model) setStyles(s styles) {
+	m.styles = s
+
+	m.spinner.Style = s.spinner
+	m.requestList.SetDelegate(newItemDelegate(s, m.clock))
+	m.requestList.Styles = s.listStyles()
+	m.headersTable.SetStyles(s.getTableStyles())
+
+	if s.theme.monochrome {
+		m.colorscheme = monochromeColorScheme
+	}
+}
@@ -0,0 +1,404 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ayinke-llc/sdump/internal/inspect"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// treeExpandDepth is how deep the tree is expanded when opened. Deeper
+// objects and arrays start collapsed so large payloads stay readable
+const treeExpandDepth = 2
+
+// jsonTree explores a JSON body with objects and arrays that can be
+// expanded and collapsed
+type jsonTree struct {
+	visible bool
+	// ingest is the request the tree was built from
+	ingest string
+
+	root      *inspect.Node
+	collapsed map[string]bool
+	cursor    int
+	// offset is the first row in view
+	offset int
+
+	input     textinput.Model
+	searching bool
+	query     string
+}
+
+func newJSONTree() jsonTree {
+	input := textinput.New()
+	input.Prompt = "key: "
+	input.Placeholder = "customer"
+
+	return jsonTree{
+		input: input,
+	}
+}
+
+func (t *jsonTree) open(i item) error {
+	root, err := inspect.ParseTree(i.Request.Body)
+	if err != nil {
+		return err
+	}
+
+	t.visible = true
+	t.ingest = i.ID
+	t.root = root
+	t.cursor = 0
+	t.offset = 0
+	t.query = ""
+	t.collapsed = make(map[string]bool)
+
+	root.Walk(func(n *inspect.Node) bool {
+		if n.IsContainer() && n.Depth >= treeExpandDepth {
+			t.collapsed[n.Path] = true
+		}
+
+		return true
+	})
+
+	return nil
+}
+
+func (t *jsonTree) close() {
+	t.visible = false
+	t.searching = false
+	t.input.Blur()
+}
+
+// rows are the nodes that are not hidden by a collapsed parent
+func (t jsonTree) rows() []*inspect.Node {
+	var rows []*inspect.Node
+
+	t.root.Walk(func(n *inspect.Node) bool {
+		rows = append(rows, n)
+		return !t.collapsed[n.Path]
+	})
+
+	return rows
+}
+
+func (t jsonTree) focused() *inspect.Node {
+	rows := t.rows()
+	if t.cursor >= len(rows) {
+		return t.root
+	}
+
+	return rows[t.cursor]
+}
+
+func (t *jsonTree) moveTo(cursor int) {
+	t.cursor = max(0, min(cursor, len(t.rows())-1))
+}
+
+// focus moves the cursor to the node, expanding its parents if needed
+func (t *jsonTree) focus(node *inspect.Node) {
+	for parent := node.Parent; parent != nil; parent = parent.Parent {
+		delete(t.collapsed, parent.Path)
+	}
+
+	for i, n := range t.rows() {
+		if n == node {
+			t.cursor = i
+			return
+		}
+	}
+}
+
+func (t *jsonTree) setCollapsed(node *inspect.Node, collapsed bool) {
+	if !node.IsContainer() || len(node.Children) == 0 {
+		return
+	}
+
+	if collapsed {
+		t.collapsed[node.Path] = true
+		return
+	}
+
+	delete(t.collapsed, node.Path)
+}
+
+func (t *jsonTree) setAllCollapsed(collapsed bool) {
+	focused := t.focused()
+
+	t.root.Walk(func(n *inspect.Node) bool {
+		// the root stays open so there is always something to move through
+		if n != t.root {
+			t.setCollapsed(n, collapsed)
+		}
+
+		return true
+	})
+
+	// the focused node would be hidden so focus its top level parent
+	for collapsed && focused.Parent != nil && focused.Parent != t.root {
+		focused = focused.Parent
+	}
+
+	t.focus(focused)
+}
+
+// find moves to the next node after the cursor whose key contains the query.
+// Collapsed nodes are searched too
+func (t *jsonTree) find(forward bool) bool {
+	if t.query == "" {
+		return false
+	}
+
+	var all []*inspect.Node
+	t.root.Walk(func(n *inspect.Node) bool {
+		all = append(all, n)
+		return true
+	})
+
+	current := 0
+	focused := t.focused()
+	for i, n := range all {
+		if n == focused {
+			current = i
+			break
+		}
+	}
+
+	query := strings.ToLower(t.query)
+
+	for step := 1; step <= len(all); step++ {
+		i := (current + step) % len(all)
+		if !forward {
+			i = (current - step + len(all)) % len(all)
+		}
+
+		if strings.Contains(strings.ToLower(all[i].Key), query) {
+			t.focus(all[i])
+			return true
+		}
+	}
+
+	return false
+}
+
+func (t jsonTree) hint() string {
+	if t.searching {
+		return "   enter jumps to the first matching key, esc cancels"
+	}
+
+	return "   j/k move, enter/space folds, h/l collapse/expand, e/c all, / finds keys, n/N next/previous, y copies the node, p its path, t or esc closes"
+}
+
+// treeRows is how many rows fit in height. The path and search input take
+// up the first two lines
+func treeRows(height int) int { return max(height-2, 1) }
+
+// scroll keeps the cursor in view
+func (t *jsonTree) scroll(height int) {
+	height = treeRows(height)
+
+	if t.cursor < t.offset {
+		t.offset = t.cursor
+	}
+
+	if t.cursor >= t.offset+height {
+		t.offset = t.cursor - height + 1
+	}
+
+	t.offset = max(0, min(t.offset, len(t.rows())-height))
+}
+
+// view renders the rows that fit in height
+func (t jsonTree) view(st styles, height int) string {
+	rows := t.rows()
+	height = treeRows(height)
+
+	lines := []string{
+		fmt.Sprintf("%s %s", st.boldenString("Path:", false), t.focused().Path),
+	}
+
+	if t.searching {
+		lines = append(lines, t.input.View())
+	} else {
+		lines = append(lines, "")
+	}
+
+	for i := t.offset; i < len(rows) && i < t.offset+height; i++ {
+		line := t.renderNode(st, rows[i])
+		if i == t.cursor {
+			line = st.selected.Render(line)
+		}
+
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (t jsonTree) renderNode(st styles, n *inspect.Node) string {
+	var b strings.Builder
+
+	b.WriteString(strings.Repeat("  ", n.Depth))
+
+	switch {
+	case !n.IsContainer():
+		b.WriteString("  ")
+	case t.collapsed[n.Path]:
+		b.WriteString("▸ ")
+	default:
+		b.WriteString("▾ ")
+	}
+
+	if n.Parent != nil {
+		key := n.Key
+		if n.Parent.Kind == inspect.NodeArray {
+			key = "[" + key + "]"
+		}
+
+		b.WriteString(st.key.Render(key))
+		b.WriteString(": ")
+	}
+
+	switch n.Kind {
+	case inspect.NodeObject:
+		b.WriteString(st.makeString(fmt.Sprintf("{} %d keys", len(n.Children)), true))
+	case inspect.NodeArray:
+		b.WriteString(st.makeString(fmt.Sprintf("[] %d items", len(n.Children)), true))
+	case inspect.NodeString:
+		b.WriteString(st.str.Render(n.Value))
+	case inspect.NodeNumber:
+		b.WriteString(st.number.Render(n.Value))
+	default:
+		b.WriteString(st.other.Render(n.Value))
+	}
+
+	return b.String()
+}
+
+func (m model) openJSONTree() (tea.Model, tea.Cmd) {
+	selectedItem, ok := m.requestList.SelectedItem().(item)
+	if !ok {
+		return m, nil
+	}
+
+	if err := m.jsonTree.open(selectedItem); err != nil {
+		m.status = fmt.Sprintf("Only JSON bodies can be explored as a tree: %v", err)
+		return m, nil
+	}
+
+	m.detailTab = bodyTab
+	m.detailedRequestView.GotoTop()
+	return m, nil
+}
+
+func (m model) updateJSONTree(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.Type == tea.KeyCtrlC {
+		return m, tea.Quit
+	}
+
+	// new requests shift the selection of the list
+	if selectedItem, ok := m.requestList.SelectedItem().(item); !ok || selectedItem.ID != m.jsonTree.ingest {
+		m.jsonTree.close()
+		return m, nil
+	}
+
+	if m.jsonTree.searching {
+		switch msg.Type {
+		case tea.KeyEsc:
+			m.jsonTree.searching = false
+			m.jsonTree.input.Blur()
+			return m, nil
+
+		case tea.KeyEnter:
+			m.jsonTree.searching = false
+			m.jsonTree.input.Blur()
+			m.jsonTree.query = strings.TrimSpace(m.jsonTree.input.Value())
+
+			if !m.jsonTree.find(true) {
+				m.status = fmt.Sprintf("No key matches %s", m.jsonTree.query)
+			}
+
+			m.jsonTree.scroll(m.detailedRequestView.Height)
+			return m, nil
+		}
+
+		var cmd tea.Cmd
+		m.jsonTree.input, cmd = m.jsonTree.input.Update(msg)
+		return m, cmd
+	}
+
+	tree := &m.jsonTree
+	focused := tree.focused()
+
+	switch msg.String() {
+	case "esc", "t":
+		tree.close()
+
+	case "down", "j":
+		tree.moveTo(tree.cursor + 1)
+
+	case "up", "k":
+		tree.moveTo(tree.cursor - 1)
+
+	case "pgdown", "f":
+		tree.moveTo(tree.cursor + m.detailedRequestView.Height/2)
+
+	case "pgup", "b":
+		tree.moveTo(tree.cursor - m.detailedRequestView.Height/2)
+
+	case "home", "g":
+		tree.moveTo(0)
+
+	case "end", "G":
+		tree.moveTo(len(tree.rows()) - 1)
+
+	case "enter", " ":
+		tree.setCollapsed(focused, !tree.collapsed[focused.Path])
+
+	case "right", "l":
+		if focused.IsContainer() && !tree.collapsed[focused.Path] && len(focused.Children) > 0 {
+			tree.focus(focused.Children[0])
+			break
+		}
+
+		tree.setCollapsed(focused, false)
+
+	case "left", "h":
+		if focused.IsContainer() && !tree.collapsed[focused.Path] && len(focused.Children) > 0 {
+			tree.setCollapsed(focused, true)
+			break
+		}
+
+		if focused.Parent != nil {
+			tree.focus(focused.Parent)
+		}
+
+	case "e":
+		tree.setAllCollapsed(false)
+
+	case "c":
+		tree.setAllCollapsed(true)
+
+	case "/":
+		tree.searching = true
+		tree.input.SetValue(tree.query)
+		tree.input.CursorEnd()
+		return m, tree.input.Focus()
+
+	case "n", "N":
+		if !tree.find(msg.String() == "n") && tree.query != "" {
+			m.status = fmt.Sprintf("No key matches %s", tree.query)
+		}
+
+	case "y":
+		m.copyToClipboard(fmt.Sprintf("the JSON at %s", focused.Path), focused.Pretty())
+
+	case "p":
+		m.copyToClipboard(fmt.Sprintf("the path %s", focused.Path), focused.Path)
+	}
+
+	tree.scroll(m.detailedRequestView.Height)
+	return m, nil
+}
@@ -5,6 +5,8 @@ import (
 	"time"
 
 	"github.com/ayinke-llc/sdump"
+	"github.com/ayinke-llc/sdump/internal/client"
+	"github.com/ayinke-llc/sdump/internal/inspect"
 	"github.com/dustin/go-humanize"
 )
 
@@ -14,24 +16,145 @@ type ErrorMsg struct {
 
 type DumpURLMsg struct {
 	URL        string `json:"url,omitempty"`
+	Reference  string `json:"reference,omitempty"`
 	SSEChannel string `json:"sse_channel,omitempty"`
 }
 
 type ItemMsg struct {
-	item item
+	channel string
+	item    item
+}
+
+type ConnectionMsg struct {
+	channel string
+	state   connectionState
+	err     error
+	retryIn time.Duration
+}
+
+type MalformedEventMsg struct {
+	channel string
+	id      string
+	err     error
+}
+
+type ReplayMsg struct {
+	replay *sdump.Replay
+	err    error
+}
+
+type HARMsg struct {
+	name string
+	har  []byte
+	err  error
+}
+
+type HistoryMsg struct {
+	reference string
+	query     string
+	result    *client.SearchResult
+	err       error
+}
+
+type ComposerMsg struct {
+	replay *sdump.Replay
+	err    error
+}
+
+type ComposerHistoryMsg struct {
+	ingest  string
+	replays []sdump.Replay
+	err     error
+}
+
+type AnnotationMsg struct {
+	ingest *sdump.IngestHTTPRequest
+	err    error
+}
+
+type StatsMsg struct {
+	reference string
+	stats     *sdump.IngestStats
+	err       error
+}
+
+type PreferencesMsg struct {
+	preferences *sdump.Preferences
+	err         error
 }
 
 type item struct {
 	Request   sdump.RequestDefinition `json:"request,omitempty"`
 	ID        string                  `json:"id,omitempty"`
 	CreatedAt time.Time               `json:"created_at,omitempty"`
+
+	Starred bool     `json:"starred,omitempty"`
+	Note    string   `json:"note,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+
+	// marked is set when the request was marked for comparison
+	marked bool
+	// styles is set by the list delegate when the item is rendered
+	styles *styles
+	// clock is set by the list delegate when the item is rendered
+	clock *clock
+}
+
+func (i item) Title() string {
+	title := fmt.Sprintf("%s    %s", i.ID, i.Request.IPAddress)
+	if i.Starred {
+		title = "★ " + title
+	}
+
+	if i.marked {
+		title = "◆ " + title
+	}
+
+	return title
 }
 
-func (i item) Title() string { return fmt.Sprintf("%s    %s", i.ID, i.Request.IPAddress) }
 func (i item) Description() string {
-	return fmt.Sprintf("%s   %s    %s",
-		defaultTextStyle.Copy().Foreground(faintBuleColor).
-			Render(i.Request.Method), humanize.Bytes(uint64(i.Request.Size)), i.CreatedAt.Format("02/01/2006 15:04:05"))
+	size := humanize.Bytes(uint64(i.Request.Size))
+	if i.Request.Encoding != nil {
+		size += " " + i.Request.Encoding.ContentEncoding
+	}
+
+	if i.Request.Binary != nil && i.Request.Binary.Format != "" {
+		size += " " + i.Request.Binary.Format
+	}
+
+	method := i.Request.Method
+	if i.styles != nil {
+		method = i.styles.method(method)
+	}
+
+	if i.Request.Path != "" {
+		method += " " + i.Request.Path
+	}
+
+	if operations, ok := inspect.ParseGraphQL(i.Request); ok {
+		method += " " + operations[0].Name()
+		if len(operations) > 1 {
+			method += fmt.Sprintf(" +%d", len(operations)-1)
+		}
+	}
+
+	createdAt := i.CreatedAt.Format(sdump.TimestampFormats[defaultTimestampFormat])
+	if i.clock != nil {
+		createdAt = i.clock.format(i.CreatedAt)
+	}
+
+	description := fmt.Sprintf("%s   %s    %s", method, size, createdAt)
+
+	if i.Note != "" {
+		description += "  ✎"
+	}
+
+	for _, tag := range i.Tags {
+		description += " #" + tag
+	}
+
+	return description
 }
 func (i item) FilterValue() string { return i.ID }
 
@@ -41,6 +41,20 @@ func (m *MockIngestRepository) EXPECT() *MockIngestRepositoryMockRecorder {
 	return m.recorder
 }
 
+// Annotate mocks base method.
+func (m *MockIngestRepository) Annotate(arg0 context.Context, arg1 *sdump.IngestHTTPRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Annotate", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Annotate indicates an expected call of Annotate.
+func (mr *MockIngestRepositoryMockRecorder) Annotate(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Annotate", reflect.TypeOf((*MockIngestRepository)(nil).Annotate), arg0, arg1)
+}
+
 // Create mocks base method.
 func (m *MockIngestRepository) Create(arg0 context.Context, arg1 *sdump.IngestHTTPRequest) error {
 	m.ctrl.T.Helper()
@@ -55,6 +69,20 @@ func (mr *MockIngestRepositoryMockRecorder) Create(arg0, arg1 any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockIngestRepository)(nil).Create), arg0, arg1)
 }
 
+// CreateMany mocks base method.
+func (m *MockIngestRepository) CreateMany(arg0 context.Context, arg1 []sdump.IngestHTTPRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMany", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateMany indicates an expected call of CreateMany.
+func (mr *MockIngestRepositoryMockRecorder) CreateMany(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMany", reflect.TypeOf((*MockIngestRepository)(nil).CreateMany), arg0, arg1)
+}
+
 // Delete mocks base method.
 func (m *MockIngestRepository) Delete(arg0 context.Context, arg1 *sdump.DeleteIngestedRequestOptions) error {
 	m.ctrl.T.Helper()
@@ -68,3 +96,48 @@ func (mr *MockIngestRepositoryMockRecorder) Delete(arg0, arg1 any) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockIngestRepository)(nil).Delete), arg0, arg1)
 }
+
+// Get mocks base method.
+func (m *MockIngestRepository) Get(arg0 context.Context, arg1 *sdump.FindIngestOptions) (*sdump.IngestHTTPRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", arg0, arg1)
+	ret0, _ := ret[0].(*sdump.IngestHTTPRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockIngestRepositoryMockRecorder) Get(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockIngestRepository)(nil).Get), arg0, arg1)
+}
+
+// List mocks base method.
+func (m *MockIngestRepository) List(arg0 context.Context, arg1 *sdump.ListIngestOptions) ([]sdump.IngestHTTPRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1)
+	ret0, _ := ret[0].([]sdump.IngestHTTPRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockIngestRepositoryMockRecorder) List(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockIngestRepository)(nil).List), arg0, arg1)
+}
+
+// Stats mocks base method.
+func (m *MockIngestRepository) Stats(arg0 context.Context, arg1 *sdump.IngestStatsOptions) (*sdump.IngestStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats", arg0, arg1)
+	ret0, _ := ret[0].(*sdump.IngestStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockIngestRepositoryMockRecorder) Stats(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockIngestRepository)(nil).Stats), arg0, arg1)
+}
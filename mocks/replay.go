@@ -0,0 +1,71 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: replay.go
+//
+// Generated by this command:
+//
+//	mockgen --source replay.go -destination mocks/replay.go -package mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	sdump "github.com/ayinke-llc/sdump"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockReplayRepository is a mock of ReplayRepository interface.
+type MockReplayRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockReplayRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockReplayRepositoryMockRecorder is the mock recorder for MockReplayRepository.
+type MockReplayRepositoryMockRecorder struct {
+	mock *MockReplayRepository
+}
+
+// NewMockReplayRepository creates a new mock instance.
+func NewMockReplayRepository(ctrl *gomock.Controller) *MockReplayRepository {
+	mock := &MockReplayRepository{ctrl: ctrl}
+	mock.recorder = &MockReplayRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReplayRepository) EXPECT() *MockReplayRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockReplayRepository) Create(arg0 context.Context, arg1 *sdump.Replay) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockReplayRepositoryMockRecorder) Create(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockReplayRepository)(nil).Create), arg0, arg1)
+}
+
+// List mocks base method.
+func (m *MockReplayRepository) List(arg0 context.Context, arg1 *sdump.FindReplayOptions) ([]sdump.Replay, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1)
+	ret0, _ := ret[0].([]sdump.Replay)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockReplayRepositoryMockRecorder) List(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockReplayRepository)(nil).List), arg0, arg1)
+}
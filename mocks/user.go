@@ -14,6 +14,7 @@ import (
 	reflect "reflect"
 
 	sdump "github.com/ayinke-llc/sdump"
+	uuid "github.com/google/uuid"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -69,3 +70,32 @@ func (mr *MockUserRepositoryMockRecorder) Find(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockUserRepository)(nil).Find), arg0, arg1)
 }
+
+// FindPreferences mocks base method.
+func (m *MockUserRepository) FindPreferences(arg0 context.Context, arg1 uuid.UUID) (*sdump.Preferences, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPreferences", arg0, arg1)
+	ret0, _ := ret[0].(*sdump.Preferences)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPreferences indicates an expected call of FindPreferences.
+func (mr *MockUserRepositoryMockRecorder) FindPreferences(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPreferences", reflect.TypeOf((*MockUserRepository)(nil).FindPreferences), arg0, arg1)
+}
+
+// SavePreferences mocks base method.
+func (m *MockUserRepository) SavePreferences(arg0 context.Context, arg1 *sdump.Preferences) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SavePreferences", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SavePreferences indicates an expected call of SavePreferences.
+func (mr *MockUserRepositoryMockRecorder) SavePreferences(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePreferences", reflect.TypeOf((*MockUserRepository)(nil).SavePreferences), arg0, arg1)
+}
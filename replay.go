@@ -0,0 +1,54 @@
+package sdump
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// ResponseDefinition is what the target returned when a captured request
+// was replayed against it
+type ResponseDefinition struct {
+	StatusCode int           `json:"status_code,omitempty"`
+	Headers    http.Header   `json:"headers,omitempty"`
+	Body       string        `json:"body,omitempty"`
+	Size       int64         `json:"size,omitempty"`
+	Duration   time.Duration `json:"duration,omitempty"`
+
+	// Truncated is set when the target sent a larger body than is kept.
+	// Body and Size only cover the start of it
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+type Replay struct {
+	ID        uuid.UUID          `bun:"type:uuid,default:uuid_generate_v4()" json:"id,omitempty"`
+	IngestID  uuid.UUID          `json:"ingest_id,omitempty"`
+	TargetURL string             `json:"target_url,omitempty"`
+	Request   RequestDefinition  `json:"request,omitempty"`
+	Response  ResponseDefinition `json:"response,omitempty"`
+
+	// Error is set when the target could not be reached at all. In that case
+	// Response will be empty
+	Error string `json:"error,omitempty"`
+
+	CreatedAt time.Time  `bun:",nullzero,notnull,default:current_timestamp" json:"created_at,omitempty" bson:"created_at"`
+	UpdatedAt time.Time  `bun:",nullzero,notnull,default:current_timestamp" json:"updated_at,omitempty" bson:"updated_at"`
+	DeletedAt *time.Time `bun:",soft_delete,nullzero" json:"-,omitempty" bson:"deleted_at"`
+
+	bun.BaseModel `bun:"table:replays"`
+}
+
+type FindReplayOptions struct {
+	IngestID uuid.UUID
+	// IngestIDs fetches the replays of multiple captured requests at once.
+	// It is used in place of IngestID when not empty
+	IngestIDs []uuid.UUID
+}
+
+type ReplayRepository interface {
+	Create(context.Context, *Replay) error
+	List(context.Context, *FindReplayOptions) ([]Replay, error)
+}
@@ -0,0 +1,136 @@
+package httpd
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/ayinke-llc/sdump"
+	"github.com/ayinke-llc/sdump/config"
+	"github.com/ayinke-llc/sdump/internal/util"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type contextKey string
+
+const userCtx contextKey = "user"
+
+// sshFingerprintHeader identifies the user an API call is made on behalf of.
+// It is only trusted when the call is also authenticated with the admin secret
+const sshFingerprintHeader = "X-Sdump-Fingerprint"
+
+// requireAPIUser protects routes that are only meant to be called by the ssh
+// server or an admin. Callers must send the admin secret as a bearer token
+// and the ssh fingerprint of the user they are acting for
+func requireAPIUser(cfg config.Config,
+	userRepo sdump.UserRepository,
+	logger *logrus.Entry,
+) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+			if subtle.ConstantTimeCompare([]byte(token), []byte(cfg.HTTP.AdminSecret)) != 1 {
+				_ = render.Render(w, r, newAPIError(http.StatusUnauthorized,
+					"invalid admin secret"))
+				return
+			}
+
+			fingerprint := r.Header.Get(sshFingerprintHeader)
+			if util.IsStringEmpty(fingerprint) {
+				_ = render.Render(w, r, newAPIError(http.StatusUnauthorized,
+					"please provide your ssh fingerprint"))
+				return
+			}
+
+			user, err := userRepo.Find(r.Context(), &sdump.FindUserOptions{
+				SSHKeyFingerprint: fingerprint,
+			})
+			if errors.Is(err, sdump.ErrUserNotFound) {
+				_ = render.Render(w, r, newAPIError(http.StatusUnauthorized,
+					"user not found"))
+				return
+			}
+
+			if err != nil {
+				logger.WithError(err).
+					WithField("request_id", retrieveRequestID(r)).
+					Error("could not find user from database")
+				_ = render.Render(w, r, newAPIError(http.StatusInternalServerError,
+					"could not find user from database"))
+				return
+			}
+
+			if user.IsBanned {
+				_ = render.Render(w, r, newAPIError(http.StatusForbidden,
+					"user is banned"))
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), userCtx, user)))
+		})
+	}
+}
+
+func getUserFromContext(ctx context.Context) *sdump.User {
+	return ctx.Value(userCtx).(*sdump.User)
+}
+
+// findIngestForUser fetches an ingested request but only if it was captured
+// by an endpoint owned by the user. Requests owned by others are reported as
+// not found
+func findIngestForUser(ctx context.Context,
+	ingestRepo sdump.IngestRepository,
+	urlRepo sdump.URLRepository,
+	id uuid.UUID,
+	user *sdump.User,
+) (*sdump.IngestHTTPRequest, error) {
+	ingest, err := ingestRepo.Get(ctx, &sdump.FindIngestOptions{
+		ID: id,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint, err := urlRepo.Get(ctx, &sdump.FindURLOptions{
+		ID: ingest.UrlID,
+	})
+	if errors.Is(err, sdump.ErrURLEndpointNotFound) {
+		return nil, sdump.ErrIngestNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if endpoint.UserID != user.ID {
+		return nil, sdump.ErrIngestNotFound
+	}
+
+	return ingest, nil
+}
+
+// findEndpointForUser fetches an endpoint by its reference but only if it is
+// owned by the user
+func findEndpointForUser(ctx context.Context,
+	urlRepo sdump.URLRepository,
+	reference string,
+	user *sdump.User,
+) (*sdump.URLEndpoint, error) {
+	endpoint, err := urlRepo.Get(ctx, &sdump.FindURLOptions{
+		Reference: reference,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if endpoint.UserID != user.ID {
+		return nil, sdump.ErrURLEndpointNotFound
+	}
+
+	return endpoint, nil
+}
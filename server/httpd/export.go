@@ -0,0 +1,167 @@
+package httpd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ayinke-llc/sdump"
+	"github.com/ayinke-llc/sdump/config"
+	"github.com/ayinke-llc/sdump/internal/har"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+type exportHandler struct {
+	logger     *logrus.Entry
+	urlRepo    sdump.URLRepository
+	ingestRepo sdump.IngestRepository
+	replayRepo sdump.ReplayRepository
+	cfg        config.Config
+}
+
+func (e *exportHandler) endpointURL(endpoint *sdump.URLEndpoint) string {
+	return fmt.Sprintf("%s/%s", e.cfg.HTTP.Domain, endpoint.Reference)
+}
+
+func (e *exportHandler) ingest(w http.ResponseWriter, r *http.Request) {
+	ctx, span, requestID := getTracer(r.Context(), r, "export.ingest")
+	defer span.End()
+
+	logger := e.logger.WithField("method", "export.ingest").
+		WithField("request_id", requestID)
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		span.SetStatus(codes.Error, "invalid ingest id")
+		_ = render.Render(w, r, newAPIError(http.StatusBadRequest, "please provide a valid ingest id"))
+		return
+	}
+
+	span.SetAttributes(attribute.String("ingest_id", id.String()))
+
+	ingest, err := findIngestForUser(ctx, e.ingestRepo, e.urlRepo, id, getUserFromContext(ctx))
+	if errors.Is(err, sdump.ErrIngestNotFound) {
+		span.SetStatus(codes.Error, "ingest not found")
+		_ = render.Render(w, r, newAPIError(http.StatusNotFound, "ingested request does not exist"))
+		return
+	}
+
+	if err != nil {
+		logger.WithError(err).Error("could not fetch ingested request")
+		span.SetStatus(codes.Error, "could not fetch ingested request")
+		_ = render.Render(w, r, newAPIError(http.StatusInternalServerError,
+			"an error occurred while fetching ingested request"))
+		return
+	}
+
+	endpoint, err := e.urlRepo.Get(ctx, &sdump.FindURLOptions{
+		ID: ingest.UrlID,
+	})
+	if err != nil {
+		logger.WithError(err).Error("could not fetch endpoint")
+		span.SetStatus(codes.Error, "could not fetch endpoint")
+		_ = render.Render(w, r, newAPIError(http.StatusInternalServerError,
+			"an error occurred while fetching endpoint"))
+		return
+	}
+
+	e.write(w, r, endpoint, []sdump.IngestHTTPRequest{*ingest}, ingest.ID.String())
+}
+
+func (e *exportHandler) endpoint(w http.ResponseWriter, r *http.Request) {
+	ctx, span, requestID := getTracer(r.Context(), r, "export.endpoint")
+	defer span.End()
+
+	reference := chi.URLParam(r, "reference")
+
+	span.SetAttributes(attribute.String("reference", reference))
+
+	logger := e.logger.WithField("method", "export.endpoint").
+		WithField("request_id", requestID).
+		WithField("reference", reference)
+
+	var ids []uuid.UUID
+
+	if s := r.URL.Query().Get("ids"); s != "" {
+		for _, v := range strings.Split(s, ",") {
+			id, err := uuid.Parse(strings.TrimSpace(v))
+			if err != nil {
+				span.SetStatus(codes.Error, "invalid ingest id")
+				_ = render.Render(w, r, newAPIError(http.StatusBadRequest,
+					fmt.Sprintf("%s is not a valid ingest id", v)))
+				return
+			}
+
+			ids = append(ids, id)
+		}
+	}
+
+	endpoint, err := findEndpointForUser(ctx, e.urlRepo, reference, getUserFromContext(ctx))
+	if errors.Is(err, sdump.ErrURLEndpointNotFound) {
+		span.SetStatus(codes.Error, "endpoint not found")
+		_ = render.Render(w, r, newAPIError(http.StatusNotFound, "Dump url does not exist"))
+		return
+	}
+
+	if err != nil {
+		logger.WithError(err).Error("could not fetch endpoint")
+		span.SetStatus(codes.Error, "could not fetch endpoint")
+		_ = render.Render(w, r, newAPIError(http.StatusInternalServerError,
+			"an error occurred while fetching endpoint"))
+		return
+	}
+
+	ingests, err := e.ingestRepo.List(ctx, &sdump.ListIngestOptions{
+		UrlID: endpoint.ID,
+		IDs:   ids,
+	})
+	if err != nil {
+		logger.WithError(err).Error("could not list ingested requests")
+		span.SetStatus(codes.Error, "could not list ingested requests")
+		_ = render.Render(w, r, newAPIError(http.StatusInternalServerError,
+			"an error occurred while fetching ingested requests"))
+		return
+	}
+
+	e.write(w, r, endpoint, ingests, endpoint.Reference)
+}
+
+func (e *exportHandler) write(w http.ResponseWriter, r *http.Request,
+	endpoint *sdump.URLEndpoint,
+	ingests []sdump.IngestHTTPRequest,
+	name string,
+) {
+	var replays []sdump.Replay
+
+	if len(ingests) > 0 {
+		ids := make([]uuid.UUID, 0, len(ingests))
+		for _, ingest := range ingests {
+			ids = append(ids, ingest.ID)
+		}
+
+		var err error
+
+		replays, err = e.replayRepo.List(r.Context(), &sdump.FindReplayOptions{
+			IngestIDs: ids,
+		})
+		if err != nil {
+			e.logger.WithError(err).
+				WithField("request_id", retrieveRequestID(r)).
+				Error("could not list replays")
+			_ = render.Render(w, r, newAPIError(http.StatusInternalServerError,
+				"an error occurred while fetching replays"))
+			return
+		}
+	}
+
+	w.Header().Set("Content-Disposition",
+		fmt.Sprintf("attachment; filename=%q", har.Filename(name)))
+
+	render.JSON(w, r, har.New(e.endpointURL(endpoint), ingests, replays))
+}
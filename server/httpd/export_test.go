@@ -0,0 +1,227 @@
+package httpd
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ayinke-llc/sdump"
+	"github.com/ayinke-llc/sdump/config"
+	"github.com/ayinke-llc/sdump/mocks"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+var testCreatedAt = time.Date(2024, time.January, 20, 14, 30, 0, 0, time.UTC)
+
+func TestExportHandler_Ingest(t *testing.T) {
+	tt := []struct {
+		name   string
+		mockFn func(ingestRepo *mocks.MockIngestRepository,
+			urlRepo *mocks.MockURLRepository,
+			replayRepo *mocks.MockReplayRepository)
+		expectedStatusCode int
+	}{
+		{
+			name: "ingest not found",
+			mockFn: func(ingestRepo *mocks.MockIngestRepository, _ *mocks.MockURLRepository, _ *mocks.MockReplayRepository) {
+				ingestRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sdump.ErrIngestNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name: "could not fetch replays",
+			mockFn: func(ingestRepo *mocks.MockIngestRepository, urlRepo *mocks.MockURLRepository, replayRepo *mocks.MockReplayRepository) {
+				ingestRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&sdump.IngestHTTPRequest{ID: testIngestID}, nil)
+
+				urlRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(2).
+					Return(&sdump.URLEndpoint{UserID: testUser.ID, Reference: "cmltfm6g330l5l1vq110"}, nil)
+
+				replayRepo.EXPECT().List(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, errors.New("could not list replays"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+		{
+			name: "exported ingest",
+			mockFn: func(ingestRepo *mocks.MockIngestRepository, urlRepo *mocks.MockURLRepository, replayRepo *mocks.MockReplayRepository) {
+				ingestRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&sdump.IngestHTTPRequest{
+						ID: testIngestID,
+						Request: sdump.RequestDefinition{
+							Method: http.MethodPost,
+							Body:   `{"name": "sdump"}`,
+							Headers: http.Header{
+								"Content-Type": []string{"application/json"},
+							},
+						},
+						CreatedAt: testCreatedAt,
+					}, nil)
+
+				urlRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(2).
+					Return(&sdump.URLEndpoint{UserID: testUser.ID, Reference: "cmltfm6g330l5l1vq110"}, nil)
+
+				replayRepo.EXPECT().List(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+
+			req := withUserAndParams(httptest.NewRequest(http.MethodGet, "/", nil),
+				map[string]string{"id": testIngestID.String()})
+
+			logrus.SetOutput(io.Discard)
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ingestRepo := mocks.NewMockIngestRepository(ctrl)
+			urlRepo := mocks.NewMockURLRepository(ctrl)
+			replayRepo := mocks.NewMockReplayRepository(ctrl)
+
+			v.mockFn(ingestRepo, urlRepo, replayRepo)
+
+			h := &exportHandler{
+				logger: logrus.WithField("module", "test"),
+				cfg: config.Config{
+					HTTP: config.HTTPConfig{
+						Domain: "https://sdump.app",
+					},
+				},
+				urlRepo:    urlRepo,
+				ingestRepo: ingestRepo,
+				replayRepo: replayRepo,
+			}
+
+			h.ingest(recorder, req)
+
+			require.Equal(t, v.expectedStatusCode, recorder.Result().StatusCode)
+			verifyMatch(t, recorder)
+		})
+	}
+}
+
+func TestExportHandler_Endpoint(t *testing.T) {
+	tt := []struct {
+		name   string
+		ids    string
+		mockFn func(ingestRepo *mocks.MockIngestRepository,
+			urlRepo *mocks.MockURLRepository,
+			replayRepo *mocks.MockReplayRepository)
+		expectedStatusCode int
+	}{
+		{
+			name:               "invalid ingest id in selection",
+			ids:                "oops",
+			mockFn:             func(_ *mocks.MockIngestRepository, _ *mocks.MockURLRepository, _ *mocks.MockReplayRepository) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "endpoint belongs to another user",
+			mockFn: func(_ *mocks.MockIngestRepository, urlRepo *mocks.MockURLRepository, _ *mocks.MockReplayRepository) {
+				urlRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&sdump.URLEndpoint{UserID: uuid.New()}, nil)
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name: "exported selection",
+			ids:  testIngestID.String(),
+			mockFn: func(ingestRepo *mocks.MockIngestRepository, urlRepo *mocks.MockURLRepository, replayRepo *mocks.MockReplayRepository) {
+				urlRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&sdump.URLEndpoint{UserID: testUser.ID, Reference: "cmltfm6g330l5l1vq110"}, nil)
+
+				ingestRepo.EXPECT().List(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, opts *sdump.ListIngestOptions) ([]sdump.IngestHTTPRequest, error) {
+						require.Equal(t, []uuid.UUID{testIngestID}, opts.IDs)
+
+						return []sdump.IngestHTTPRequest{
+							{
+								ID: testIngestID,
+								Request: sdump.RequestDefinition{
+									Method: http.MethodGet,
+									Query:  "page=1",
+								},
+								CreatedAt: testCreatedAt,
+							},
+						}, nil
+					})
+
+				replayRepo.EXPECT().List(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]sdump.Replay{
+						{
+							IngestID:  testIngestID,
+							TargetURL: "http://localhost:3000",
+							Response: sdump.ResponseDefinition{
+								StatusCode: http.StatusOK,
+								Body:       "ok",
+								Size:       2,
+							},
+							CreatedAt: testCreatedAt.Add(time.Minute),
+						},
+					}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+
+			req := withUserAndParams(httptest.NewRequest(http.MethodGet, "/?ids="+v.ids, nil),
+				map[string]string{"reference": "cmltfm6g330l5l1vq110"})
+
+			logrus.SetOutput(io.Discard)
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ingestRepo := mocks.NewMockIngestRepository(ctrl)
+			urlRepo := mocks.NewMockURLRepository(ctrl)
+			replayRepo := mocks.NewMockReplayRepository(ctrl)
+
+			v.mockFn(ingestRepo, urlRepo, replayRepo)
+
+			h := &exportHandler{
+				logger: logrus.WithField("module", "test"),
+				cfg: config.Config{
+					HTTP: config.HTTPConfig{
+						Domain: "https://sdump.app",
+					},
+				},
+				urlRepo:    urlRepo,
+				ingestRepo: ingestRepo,
+				replayRepo: replayRepo,
+			}
+
+			h.endpoint(recorder, req)
+
+			require.Equal(t, v.expectedStatusCode, recorder.Result().StatusCode)
+			verifyMatch(t, recorder)
+		})
+	}
+}
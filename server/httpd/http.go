@@ -7,6 +7,8 @@ import (
 
 	"github.com/ayinke-llc/sdump"
 	"github.com/ayinke-llc/sdump/config"
+	"github.com/ayinke-llc/sdump/internal/payload"
+	"github.com/ayinke-llc/sdump/internal/replay"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/telemetry"
@@ -40,13 +42,14 @@ func New(cfg config.Config,
 	urlRepo sdump.URLRepository,
 	ingestRepo sdump.IngestRepository,
 	userRepo sdump.UserRepository,
+	replayRepo sdump.ReplayRepository,
 	logger *logrus.Entry,
 	sseServer *sse.Server,
 	ratelimitStore limiter.Store,
 ) *http.Server {
 	return &http.Server{
 		Handler: buildRoutes(cfg, logger, urlRepo, ingestRepo,
-			userRepo, sseServer, ratelimitStore),
+			userRepo, replayRepo, sseServer, ratelimitStore),
 		Addr: fmt.Sprintf(":%d", cfg.HTTP.Port),
 	}
 }
@@ -56,17 +59,19 @@ func buildRoutes(cfg config.Config,
 	urlRepo sdump.URLRepository,
 	ingestRepo sdump.IngestRepository,
 	userRepo sdump.UserRepository,
+	replayRepo sdump.ReplayRepository,
 	sseServer *sse.Server,
 	ratelimitStore limiter.Store,
 ) http.Handler {
 
 	router := chi.NewRouter()
 
-	router.Use(middleware.AllowContentType("application/json"))
 	router.Use(middleware.RequestID)
 	router.Use(writeRequestIDHeader)
 	router.Use(jsonResponse)
 
+	decoder := payload.NewDecoder(logger, cfg.HTTP.MaxDecodedBodySize)
+
 	urlHandler := &urlHandler{
 		cfg:        cfg,
 		urlRepo:    urlRepo,
@@ -74,6 +79,52 @@ func buildRoutes(cfg config.Config,
 		ingestRepo: ingestRepo,
 		userRepo:   userRepo,
 		sseServer:  sseServer,
+		decoder:    decoder,
+	}
+
+	replayHandler := &replayHandler{
+		cfg:        cfg,
+		logger:     logger,
+		urlRepo:    urlRepo,
+		ingestRepo: ingestRepo,
+		replayRepo: replayRepo,
+		replayer:   replay.New(cfg.HTTP.Replay.Timeout, cfg.HTTP.Replay.AllowPrivateNetworks),
+	}
+
+	exportHandler := &exportHandler{
+		cfg:        cfg,
+		logger:     logger,
+		urlRepo:    urlRepo,
+		ingestRepo: ingestRepo,
+		replayRepo: replayRepo,
+	}
+
+	ingestHandler := &ingestHandler{
+		cfg:        cfg,
+		logger:     logger,
+		urlRepo:    urlRepo,
+		ingestRepo: ingestRepo,
+	}
+
+	importHandler := &importHandler{
+		cfg:        cfg,
+		logger:     logger,
+		urlRepo:    urlRepo,
+		ingestRepo: ingestRepo,
+		decoder:    decoder,
+	}
+
+	userHandler := &userHandler{
+		cfg:      cfg,
+		logger:   logger,
+		userRepo: userRepo,
+	}
+
+	protobufHandler := &protobufHandler{
+		cfg:     cfg,
+		logger:  logger,
+		urlRepo: urlRepo,
+		decoder: decoder,
 	}
 
 	router.Use(writeRequestIDHeader)
@@ -98,10 +149,36 @@ func buildRoutes(cfg config.Config,
 		logger.WithError(err).Fatal("could not set up HTTP middleware")
 	}
 
-	router.Post("/", urlHandler.create)
+	// endpoints capture requests of any content type. Only the routes used
+	// by the ssh server and cli are restricted
+	router.With(middleware.AllowContentType("application/json")).Post("/", urlHandler.create)
 	router.Handle("/{reference}", mid.Handle(http.HandlerFunc(urlHandler.ingest)))
+	router.Handle("/{reference}/*", mid.Handle(http.HandlerFunc(urlHandler.ingest)))
 	router.Get("/events", sseServer.ServeHTTP)
 
+	router.Route("/api", func(r chi.Router) {
+		// protobuf descriptor sets are binary so they are uploaded as is
+		r.Use(middleware.AllowContentType("application/json", "application/x-ndjson",
+			"application/octet-stream"))
+		r.Use(requireAPIUser(cfg, userRepo, logger))
+
+		r.Post("/ingests/{id}/replays", replayHandler.replay)
+		r.Get("/ingests/{id}/replays", replayHandler.list)
+		r.Get("/ingests/{id}/har", exportHandler.ingest)
+		r.Get("/ingests/{id}/diff", ingestHandler.diff)
+		r.Put("/ingests/{id}/annotation", ingestHandler.annotate)
+
+		r.Get("/urls/latest", urlHandler.latest)
+		r.Get("/urls/{reference}/ingests", ingestHandler.search)
+		r.Get("/urls/{reference}/stats", ingestHandler.stats)
+		r.Get("/urls/{reference}/har", exportHandler.endpoint)
+		r.Post("/urls/{reference}/imports", importHandler.create)
+		r.Put("/urls/{reference}/protobuf", protobufHandler.upload)
+
+		r.Get("/preferences", userHandler.preferences)
+		r.Put("/preferences", userHandler.savePreferences)
+	})
+
 	return router
 }
 
@@ -0,0 +1,83 @@
+package httpd
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ayinke-llc/sdump"
+	"github.com/ayinke-llc/sdump/config"
+	"github.com/ayinke-llc/sdump/internal/importer"
+	"github.com/ayinke-llc/sdump/internal/payload"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+type importHandler struct {
+	logger     *logrus.Entry
+	urlRepo    sdump.URLRepository
+	ingestRepo sdump.IngestRepository
+	cfg        config.Config
+	decoder    *payload.Decoder
+}
+
+func (i *importHandler) create(w http.ResponseWriter, r *http.Request) {
+	ctx, span, requestID := getTracer(r.Context(), r, "import.create")
+	defer span.End()
+
+	reference := chi.URLParam(r, "reference")
+
+	span.SetAttributes(attribute.String("reference", reference))
+
+	logger := i.logger.WithField("method", "import.create").
+		WithField("request_id", requestID).
+		WithField("reference", reference)
+
+	endpoint, err := findEndpointForUser(ctx, i.urlRepo, reference, getUserFromContext(ctx))
+	if errors.Is(err, sdump.ErrURLEndpointNotFound) {
+		span.SetStatus(codes.Error, "endpoint not found")
+		_ = render.Render(w, r, newAPIError(http.StatusNotFound, "Dump url does not exist"))
+		return
+	}
+
+	if err != nil {
+		logger.WithError(err).Error("could not fetch endpoint")
+		span.SetStatus(codes.Error, "could not fetch endpoint")
+		_ = render.Render(w, r, newAPIError(http.StatusInternalServerError,
+			"an error occurred while fetching endpoint"))
+		return
+	}
+
+	ingests, err := importer.Decode(http.MaxBytesReader(w, r.Body, i.cfg.HTTP.MaxImportSize))
+	if err != nil {
+		msg := err.Error()
+
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			msg = maxErr.Error()
+		}
+
+		span.SetStatus(codes.Error, "invalid archive")
+		_ = render.Render(w, r, newAPIError(http.StatusBadRequest, msg))
+		return
+	}
+
+	imported, err := importer.Import(ctx, i.ingestRepo, i.decoder, endpoint, ingests, &importer.Options{
+		KeepAnnotations: r.URL.Query().Get("keep_annotations") == "true",
+	})
+	if err != nil {
+		logger.WithError(err).Error("could not import requests")
+		span.SetStatus(codes.Error, "could not import requests")
+		_ = render.Render(w, r, newAPIError(http.StatusInternalServerError,
+			"an error occurred while importing requests"))
+		return
+	}
+
+	span.SetStatus(codes.Ok, "imported requests")
+	_ = render.Render(w, r, &importResponse{
+		APIStatus: newAPIStatus(http.StatusCreated, "imported requests"),
+		Imported:  imported,
+	})
+}
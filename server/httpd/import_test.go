@@ -0,0 +1,128 @@
+package httpd
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ayinke-llc/sdump"
+	"github.com/ayinke-llc/sdump/config"
+	"github.com/ayinke-llc/sdump/internal/payload"
+	"github.com/ayinke-llc/sdump/mocks"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestImportHandler_Create(t *testing.T) {
+	ndjson := `{"request": {"method": "POST", "body": "{}"}, "created_at": "2024-01-20T14:30:00Z"}
+{"request": {"method": "GET", "query": "page=1"}, "created_at": "2024-01-20T14:31:00Z"}
+`
+
+	tt := []struct {
+		name               string
+		archive            string
+		mockFn             func(ingestRepo *mocks.MockIngestRepository, urlRepo *mocks.MockURLRepository)
+		expectedStatusCode int
+	}{
+		{
+			name:    "endpoint belongs to another user",
+			archive: ndjson,
+			mockFn: func(_ *mocks.MockIngestRepository, urlRepo *mocks.MockURLRepository) {
+				urlRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&sdump.URLEndpoint{UserID: uuid.New()}, nil)
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name:    "invalid archive",
+			archive: "oops",
+			mockFn: func(_ *mocks.MockIngestRepository, urlRepo *mocks.MockURLRepository) {
+				urlRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&sdump.URLEndpoint{UserID: testUser.ID}, nil)
+			},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:    "archive too large",
+			archive: ndjson + strings.Repeat(ndjson, 10),
+			mockFn: func(_ *mocks.MockIngestRepository, urlRepo *mocks.MockURLRepository) {
+				urlRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&sdump.URLEndpoint{UserID: testUser.ID}, nil)
+			},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:    "could not store request",
+			archive: ndjson,
+			mockFn: func(ingestRepo *mocks.MockIngestRepository, urlRepo *mocks.MockURLRepository) {
+				urlRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&sdump.URLEndpoint{UserID: testUser.ID}, nil)
+
+				ingestRepo.EXPECT().CreateMany(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(errors.New("could not create ingest"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+		{
+			name:    "imported requests",
+			archive: ndjson,
+			mockFn: func(ingestRepo *mocks.MockIngestRepository, urlRepo *mocks.MockURLRepository) {
+				urlRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&sdump.URLEndpoint{UserID: testUser.ID}, nil)
+
+				ingestRepo.EXPECT().CreateMany(gomock.Any(), gomock.Len(2)).
+					Times(1).
+					Return(nil)
+			},
+			expectedStatusCode: http.StatusCreated,
+		},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+
+			req := withUserAndParams(httptest.NewRequest(http.MethodPost, "/",
+				strings.NewReader(v.archive)),
+				map[string]string{"reference": "cmltfm6g330l5l1vq110"})
+
+			logrus.SetOutput(io.Discard)
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ingestRepo := mocks.NewMockIngestRepository(ctrl)
+			urlRepo := mocks.NewMockURLRepository(ctrl)
+
+			v.mockFn(ingestRepo, urlRepo)
+
+			h := &importHandler{
+				logger: logrus.WithField("module", "test"),
+				cfg: config.Config{
+					HTTP: config.HTTPConfig{
+						MaxImportSize: int64(len(ndjson) * 2),
+					},
+				},
+				urlRepo:    urlRepo,
+				ingestRepo: ingestRepo,
+				decoder:    payload.NewDecoder(logrus.WithField("module", "test"), 512),
+			}
+
+			h.create(recorder, req)
+
+			require.Equal(t, v.expectedStatusCode, recorder.Result().StatusCode)
+			verifyMatch(t, recorder)
+		})
+	}
+}
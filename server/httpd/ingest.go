@@ -0,0 +1,435 @@
+package httpd
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ayinke-llc/sdump"
+	"github.com/ayinke-llc/sdump/config"
+	"github.com/ayinke-llc/sdump/internal/diff"
+	"github.com/ayinke-llc/sdump/internal/filter"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const (
+	defaultSearchLimit = 50
+	maxSearchLimit     = 200
+
+	// searchPageSize is how many captured requests are read from the store at
+	// once while looking for matches
+	searchPageSize = 500
+
+	// maxSearchScan bounds how much history a single search reads. Clients
+	// can continue from the returned cursor
+	maxSearchScan = 5000
+
+	defaultStatsMinutes = 30
+	maxStatsMinutes     = 24 * 60
+
+	// statsTopIPs is how many source ips the stats of an endpoint list
+	statsTopIPs = 5
+	// statsTopPaths is how many paths the stats of an endpoint list
+	statsTopPaths = 5
+
+	maxNoteLength = 2000
+	maxTags       = 20
+	maxTagLength  = 50
+)
+
+type ingestHandler struct {
+	logger     *logrus.Entry
+	urlRepo    sdump.URLRepository
+	ingestRepo sdump.IngestRepository
+	cfg        config.Config
+}
+
+// search returns the captured requests of an endpoint matching the filter
+// query in q, newest first
+func (i *ingestHandler) search(w http.ResponseWriter, r *http.Request) {
+	ctx, span, requestID := getTracer(r.Context(), r, "ingest.search")
+	defer span.End()
+
+	reference := chi.URLParam(r, "reference")
+
+	span.SetAttributes(attribute.String("reference", reference))
+
+	logger := i.logger.WithField("method", "ingest.search").
+		WithField("request_id", requestID).
+		WithField("reference", reference)
+
+	query, err := filter.Parse(r.URL.Query().Get("q"))
+	if err != nil {
+		span.SetStatus(codes.Error, "invalid query")
+		_ = render.Render(w, r, newAPIError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	limit := defaultSearchLimit
+	if s := r.URL.Query().Get("limit"); s != "" {
+		limit, err = strconv.Atoi(s)
+		if err != nil || limit <= 0 {
+			span.SetStatus(codes.Error, "invalid limit")
+			_ = render.Render(w, r, newAPIError(http.StatusBadRequest, "limit must be a positive number"))
+			return
+		}
+
+		limit = min(limit, maxSearchLimit)
+	}
+
+	var after *sdump.IngestCursor
+	if s := r.URL.Query().Get("cursor"); s != "" {
+		after, err = decodeCursor(s)
+		if err != nil {
+			span.SetStatus(codes.Error, "invalid cursor")
+			_ = render.Render(w, r, newAPIError(http.StatusBadRequest, "please provide a valid cursor"))
+			return
+		}
+	}
+
+	endpoint, err := findEndpointForUser(ctx, i.urlRepo, reference, getUserFromContext(ctx))
+	if errors.Is(err, sdump.ErrURLEndpointNotFound) {
+		span.SetStatus(codes.Error, "endpoint not found")
+		_ = render.Render(w, r, newAPIError(http.StatusNotFound, "Dump url does not exist"))
+		return
+	}
+
+	if err != nil {
+		logger.WithError(err).Error("could not fetch endpoint")
+		span.SetStatus(codes.Error, "could not fetch endpoint")
+		_ = render.Render(w, r, newAPIError(http.StatusInternalServerError,
+			"an error occurred while fetching endpoint"))
+		return
+	}
+
+	matches := []sdump.IngestHTTPRequest{}
+	scanned := 0
+
+	var cursor string
+
+search:
+	for {
+		page, err := i.ingestRepo.List(ctx, &sdump.ListIngestOptions{
+			UrlID:       endpoint.ID,
+			After:       after,
+			Method:      query.Method(),
+			Text:        query.Text(),
+			Body:        query.Body(),
+			Limit:       searchPageSize,
+			NewestFirst: true,
+		})
+		if err != nil {
+			logger.WithError(err).Error("could not list ingested requests")
+			span.SetStatus(codes.Error, "could not list ingested requests")
+			_ = render.Render(w, r, newAPIError(http.StatusInternalServerError,
+				"an error occurred while searching ingested requests"))
+			return
+		}
+
+		for _, ingest := range page {
+			scanned++
+			after = &sdump.IngestCursor{CreatedAt: ingest.CreatedAt, ID: ingest.ID}
+
+			if query.Match(filter.Request{ID: ingest.ID.String(), Request: ingest.Request}) {
+				matches = append(matches, ingest)
+			}
+
+			if len(matches) == limit || scanned == maxSearchScan {
+				cursor = encodeCursor(after)
+				break search
+			}
+		}
+
+		if len(page) < searchPageSize {
+			break
+		}
+	}
+
+	span.SetStatus(codes.Ok, "searched ingested requests")
+	_ = render.Render(w, r, &ingestListResponse{
+		APIStatus: newAPIStatus(http.StatusOK, "fetched ingested requests"),
+		Ingests:   matches,
+		Cursor:    cursor,
+	})
+}
+
+// encodeCursor turns the position of the last request scanned into an
+// opaque token clients pass back to fetch the next page
+func encodeCursor(c *sdump.IngestCursor) string {
+	return base64.RawURLEncoding.EncodeToString(
+		[]byte(c.CreatedAt.UTC().Format(time.RFC3339Nano) + "," + c.ID.String()))
+}
+
+func decodeCursor(s string) (*sdump.IngestCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	createdAt, id, ok := strings.Cut(string(b), ",")
+	if !ok {
+		return nil, errors.New("cursor is malformed")
+	}
+
+	c := new(sdump.IngestCursor)
+
+	c.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return nil, err
+	}
+
+	c.ID, err = uuid.Parse(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// diff compares the ingested request with the one in the with query
+// parameter. Both requests must belong to the user
+func (i *ingestHandler) diff(w http.ResponseWriter, r *http.Request) {
+	ctx, span, requestID := getTracer(r.Context(), r, "ingest.diff")
+	defer span.End()
+
+	logger := i.logger.WithField("method", "ingest.diff").
+		WithField("request_id", requestID)
+
+	ids := make([]uuid.UUID, 0, 2)
+
+	for _, s := range []string{chi.URLParam(r, "id"), r.URL.Query().Get("with")} {
+		id, err := uuid.Parse(s)
+		if err != nil {
+			span.SetStatus(codes.Error, "invalid ingest id")
+			_ = render.Render(w, r, newAPIError(http.StatusBadRequest,
+				"please provide two valid ingest ids to compare"))
+			return
+		}
+
+		ids = append(ids, id)
+	}
+
+	span.SetAttributes(attribute.String("ingest_id", ids[0].String()),
+		attribute.String("other_ingest_id", ids[1].String()))
+
+	ingests := make([]*sdump.IngestHTTPRequest, 0, len(ids))
+
+	for _, id := range ids {
+		ingest, err := findIngestForUser(ctx, i.ingestRepo, i.urlRepo, id, getUserFromContext(ctx))
+		if errors.Is(err, sdump.ErrIngestNotFound) {
+			span.SetStatus(codes.Error, "ingest not found")
+			_ = render.Render(w, r, newAPIError(http.StatusNotFound,
+				fmt.Sprintf("ingested request %s does not exist", id)))
+			return
+		}
+
+		if err != nil {
+			logger.WithError(err).Error("could not fetch ingested request")
+			span.SetStatus(codes.Error, "could not fetch ingested request")
+			_ = render.Render(w, r, newAPIError(http.StatusInternalServerError,
+				"an error occurred while fetching ingested request"))
+			return
+		}
+
+		ingests = append(ingests, ingest)
+	}
+
+	result, err := diff.Requests(ingests[0].Request, ingests[1].Request)
+	if err != nil {
+		span.SetStatus(codes.Error, "could not compare requests")
+		_ = render.Render(w, r, newAPIError(http.StatusUnprocessableEntity,
+			fmt.Sprintf("could not compare requests: %v", err)))
+		return
+	}
+
+	span.SetStatus(codes.Ok, "compared ingested requests")
+	_ = render.Render(w, r, &diffResponse{
+		APIStatus: newAPIStatus(http.StatusOK, "compared ingested requests"),
+		Equal:     result.Equal(),
+		Diff:      result,
+	})
+}
+
+type annotateIngestRequest struct {
+	Starred bool     `json:"starred"`
+	Note    string   `json:"note"`
+	Tags    []string `json:"tags"`
+}
+
+// normalize trims the note and tags. Tags are deduplicated without
+// considering their case and a leading # is dropped
+func (a *annotateIngestRequest) normalize() error {
+	a.Note = strings.TrimSpace(a.Note)
+	if utf8.RuneCountInString(a.Note) > maxNoteLength {
+		return fmt.Errorf("note cannot be longer than %d characters", maxNoteLength)
+	}
+
+	tags := make([]string, 0, len(a.Tags))
+	seen := make(map[string]struct{}, len(a.Tags))
+
+	for _, tag := range a.Tags {
+		tag = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+		if tag == "" {
+			continue
+		}
+
+		if utf8.RuneCountInString(tag) > maxTagLength {
+			return fmt.Errorf("tags cannot be longer than %d characters", maxTagLength)
+		}
+
+		if _, ok := seen[strings.ToLower(tag)]; ok {
+			continue
+		}
+
+		seen[strings.ToLower(tag)] = struct{}{}
+		tags = append(tags, tag)
+	}
+
+	if len(tags) > maxTags {
+		return fmt.Errorf("a request cannot have more than %d tags", maxTags)
+	}
+
+	a.Tags = tags
+	return nil
+}
+
+// annotate replaces the star, note and tags of an ingested request
+func (i *ingestHandler) annotate(w http.ResponseWriter, r *http.Request) {
+	ctx, span, requestID := getTracer(r.Context(), r, "ingest.annotate")
+	defer span.End()
+
+	logger := i.logger.WithField("method", "ingest.annotate").
+		WithField("request_id", requestID)
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		span.SetStatus(codes.Error, "invalid ingest id")
+		_ = render.Render(w, r, newAPIError(http.StatusBadRequest, "please provide a valid ingest id"))
+		return
+	}
+
+	span.SetAttributes(attribute.String("ingest_id", id.String()))
+
+	req := new(annotateIngestRequest)
+
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		span.SetStatus(codes.Error, "invalid request body")
+		_ = render.Render(w, r, newAPIError(http.StatusBadRequest, "please provide a valid request body"))
+		return
+	}
+
+	if err := req.normalize(); err != nil {
+		span.SetStatus(codes.Error, "invalid annotation")
+		_ = render.Render(w, r, newAPIError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	ingest, err := findIngestForUser(ctx, i.ingestRepo, i.urlRepo, id, getUserFromContext(ctx))
+	if errors.Is(err, sdump.ErrIngestNotFound) {
+		span.SetStatus(codes.Error, "ingest not found")
+		_ = render.Render(w, r, newAPIError(http.StatusNotFound, "ingested request does not exist"))
+		return
+	}
+
+	if err != nil {
+		logger.WithError(err).Error("could not fetch ingested request")
+		span.SetStatus(codes.Error, "could not fetch ingested request")
+		_ = render.Render(w, r, newAPIError(http.StatusInternalServerError,
+			"an error occurred while fetching ingested request"))
+		return
+	}
+
+	ingest.Starred = req.Starred
+	ingest.Note = req.Note
+	ingest.Tags = req.Tags
+
+	if err := i.ingestRepo.Annotate(ctx, ingest); err != nil {
+		logger.WithError(err).Error("could not annotate ingested request")
+		span.SetStatus(codes.Error, "could not annotate ingested request")
+		_ = render.Render(w, r, newAPIError(http.StatusInternalServerError,
+			"an error occurred while annotating ingested request"))
+		return
+	}
+
+	span.SetStatus(codes.Ok, "annotated ingested request")
+	_ = render.Render(w, r, &ingestResponse{
+		APIStatus: newAPIStatus(http.StatusOK, "annotated ingested request"),
+		Ingest:    ingest,
+	})
+}
+
+// stats summarizes the traffic of an endpoint. The requests per minute cover
+// the last minutes given in the query, 30 by default
+func (i *ingestHandler) stats(w http.ResponseWriter, r *http.Request) {
+	ctx, span, requestID := getTracer(r.Context(), r, "ingest.stats")
+	defer span.End()
+
+	reference := chi.URLParam(r, "reference")
+
+	span.SetAttributes(attribute.String("reference", reference))
+
+	logger := i.logger.WithField("method", "ingest.stats").
+		WithField("request_id", requestID).
+		WithField("reference", reference)
+
+	minutes := defaultStatsMinutes
+	if s := r.URL.Query().Get("minutes"); s != "" {
+		var err error
+
+		minutes, err = strconv.Atoi(s)
+		if err != nil || minutes <= 0 {
+			span.SetStatus(codes.Error, "invalid minutes")
+			_ = render.Render(w, r, newAPIError(http.StatusBadRequest, "minutes must be a positive number"))
+			return
+		}
+
+		minutes = min(minutes, maxStatsMinutes)
+	}
+
+	endpoint, err := findEndpointForUser(ctx, i.urlRepo, reference, getUserFromContext(ctx))
+	if errors.Is(err, sdump.ErrURLEndpointNotFound) {
+		span.SetStatus(codes.Error, "endpoint not found")
+		_ = render.Render(w, r, newAPIError(http.StatusNotFound, "Dump url does not exist"))
+		return
+	}
+
+	if err != nil {
+		logger.WithError(err).Error("could not fetch endpoint")
+		span.SetStatus(codes.Error, "could not fetch endpoint")
+		_ = render.Render(w, r, newAPIError(http.StatusInternalServerError,
+			"an error occurred while fetching endpoint"))
+		return
+	}
+
+	stats, err := i.ingestRepo.Stats(ctx, &sdump.IngestStatsOptions{
+		UrlID: endpoint.ID,
+		// the current minute is the last one counted
+		Since:    time.Now().Truncate(time.Minute).Add(-time.Duration(minutes-1) * time.Minute),
+		TopIPs:   statsTopIPs,
+		TopPaths: statsTopPaths,
+	})
+	if err != nil {
+		logger.WithError(err).Error("could not aggregate ingested requests")
+		span.SetStatus(codes.Error, "could not aggregate ingested requests")
+		_ = render.Render(w, r, newAPIError(http.StatusInternalServerError,
+			"an error occurred while fetching stats"))
+		return
+	}
+
+	span.SetStatus(codes.Ok, "fetched stats")
+	_ = render.Render(w, r, &statsResponse{
+		APIStatus: newAPIStatus(http.StatusOK, "fetched stats"),
+		Stats:     stats,
+	})
+}
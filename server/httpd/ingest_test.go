@@ -0,0 +1,501 @@
+package httpd
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ayinke-llc/sdump"
+	"github.com/ayinke-llc/sdump/config"
+	"github.com/ayinke-llc/sdump/mocks"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestIngestHandler_Search(t *testing.T) {
+	ingests := []sdump.IngestHTTPRequest{
+		{
+			ID: uuid.MustParse("6f0c3a53-53c5-4c16-9b8a-2d1b7c0a7e01"),
+			Request: sdump.RequestDefinition{
+				Method: http.MethodPost,
+				Body:   `{"data": {"id": 123}}`,
+			},
+			CreatedAt: testCreatedAt.Add(2 * time.Minute),
+		},
+		{
+			ID: uuid.MustParse("6f0c3a53-53c5-4c16-9b8a-2d1b7c0a7e02"),
+			Request: sdump.RequestDefinition{
+				Method: http.MethodPost,
+				Body:   `{"data": {"id": 456}}`,
+			},
+			CreatedAt: testCreatedAt.Add(time.Minute),
+		},
+		{
+			ID: uuid.MustParse("6f0c3a53-53c5-4c16-9b8a-2d1b7c0a7e03"),
+			Request: sdump.RequestDefinition{
+				Method: http.MethodPost,
+				Body:   `{"data": {"id": 123}}`,
+			},
+			CreatedAt: testCreatedAt,
+		},
+	}
+
+	cursor := encodeCursor(&sdump.IngestCursor{
+		CreatedAt: testCreatedAt.Add(time.Hour),
+		ID:        testIngestID,
+	})
+
+	tt := []struct {
+		name               string
+		query              url.Values
+		mockFn             func(ingestRepo *mocks.MockIngestRepository, urlRepo *mocks.MockURLRepository)
+		expectedStatusCode int
+	}{
+		{
+			name:               "invalid query",
+			query:              url.Values{"q": []string{"status:broken"}},
+			mockFn:             func(_ *mocks.MockIngestRepository, _ *mocks.MockURLRepository) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "invalid limit",
+			query:              url.Values{"limit": []string{"-1"}},
+			mockFn:             func(_ *mocks.MockIngestRepository, _ *mocks.MockURLRepository) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "invalid cursor",
+			query:              url.Values{"cursor": []string{"yesterday"}},
+			mockFn:             func(_ *mocks.MockIngestRepository, _ *mocks.MockURLRepository) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:  "endpoint belongs to another user",
+			query: url.Values{},
+			mockFn: func(_ *mocks.MockIngestRepository, urlRepo *mocks.MockURLRepository) {
+				urlRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&sdump.URLEndpoint{UserID: uuid.New()}, nil)
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name:  "could not list ingests",
+			query: url.Values{},
+			mockFn: func(ingestRepo *mocks.MockIngestRepository, urlRepo *mocks.MockURLRepository) {
+				urlRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&sdump.URLEndpoint{UserID: testUser.ID}, nil)
+
+				ingestRepo.EXPECT().List(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, errors.New("could not list ingests"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+		{
+			name:  "matching ingests",
+			query: url.Values{"q": []string{"method:post body.data.id=123"}},
+			mockFn: func(ingestRepo *mocks.MockIngestRepository, urlRepo *mocks.MockURLRepository) {
+				urlRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&sdump.URLEndpoint{UserID: testUser.ID}, nil)
+
+				ingestRepo.EXPECT().List(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, opts *sdump.ListIngestOptions) ([]sdump.IngestHTTPRequest, error) {
+						require.Equal(t, http.MethodPost, opts.Method)
+						require.Equal(t, []sdump.BodyMatch{
+							{Path: []string{"data", "id"}, Value: "123"},
+						}, opts.Body)
+						require.True(t, opts.NewestFirst)
+						return ingests, nil
+					})
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:  "limit reached",
+			query: url.Values{"limit": []string{"1"}, "cursor": []string{cursor}},
+			mockFn: func(ingestRepo *mocks.MockIngestRepository, urlRepo *mocks.MockURLRepository) {
+				urlRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&sdump.URLEndpoint{UserID: testUser.ID}, nil)
+
+				ingestRepo.EXPECT().List(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, opts *sdump.ListIngestOptions) ([]sdump.IngestHTTPRequest, error) {
+						require.Equal(t, &sdump.IngestCursor{
+							CreatedAt: testCreatedAt.Add(time.Hour),
+							ID:        testIngestID,
+						}, opts.After)
+						return ingests, nil
+					})
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+
+			req := withUserAndParams(httptest.NewRequest(http.MethodGet, "/?"+v.query.Encode(), nil),
+				map[string]string{"reference": "cmltfm6g330l5l1vq110"})
+
+			logrus.SetOutput(io.Discard)
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ingestRepo := mocks.NewMockIngestRepository(ctrl)
+			urlRepo := mocks.NewMockURLRepository(ctrl)
+
+			v.mockFn(ingestRepo, urlRepo)
+
+			h := &ingestHandler{
+				logger:     logrus.WithField("module", "test"),
+				cfg:        config.Config{},
+				urlRepo:    urlRepo,
+				ingestRepo: ingestRepo,
+			}
+
+			h.search(recorder, req)
+
+			require.Equal(t, v.expectedStatusCode, recorder.Result().StatusCode)
+			verifyMatch(t, recorder)
+		})
+	}
+}
+
+func TestIngestHandler_Diff(t *testing.T) {
+	otherIngestID := uuid.MustParse("6f0c3a53-53c5-4c16-9b8a-2d1b7c0a7e01")
+
+	tt := []struct {
+		name               string
+		with               string
+		mockFn             func(ingestRepo *mocks.MockIngestRepository, urlRepo *mocks.MockURLRepository)
+		expectedStatusCode int
+	}{
+		{
+			name:               "missing second ingest id",
+			mockFn:             func(_ *mocks.MockIngestRepository, _ *mocks.MockURLRepository) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "second ingest not found",
+			with: otherIngestID.String(),
+			mockFn: func(ingestRepo *mocks.MockIngestRepository, urlRepo *mocks.MockURLRepository) {
+				ingestRepo.EXPECT().Get(gomock.Any(), &sdump.FindIngestOptions{ID: testIngestID}).
+					Times(1).
+					Return(&sdump.IngestHTTPRequest{ID: testIngestID}, nil)
+
+				ingestRepo.EXPECT().Get(gomock.Any(), &sdump.FindIngestOptions{ID: otherIngestID}).
+					Times(1).
+					Return(nil, sdump.ErrIngestNotFound)
+
+				urlRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&sdump.URLEndpoint{UserID: testUser.ID}, nil)
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name: "compared ingests",
+			with: otherIngestID.String(),
+			mockFn: func(ingestRepo *mocks.MockIngestRepository, urlRepo *mocks.MockURLRepository) {
+				ingestRepo.EXPECT().Get(gomock.Any(), &sdump.FindIngestOptions{ID: testIngestID}).
+					Times(1).
+					Return(&sdump.IngestHTTPRequest{
+						ID: testIngestID,
+						Request: sdump.RequestDefinition{
+							Method: http.MethodPost,
+							Query:  "attempt=1",
+							Headers: http.Header{
+								"Content-Type":      []string{"application/json"},
+								"Stripe-Signature":  []string{"t=1,v1=abc"},
+								"X-Delivery-Source": []string{"dashboard"},
+							},
+							Body: `{"type": "invoice.paid", "data": {"amount": 100}}`,
+						},
+					}, nil)
+
+				ingestRepo.EXPECT().Get(gomock.Any(), &sdump.FindIngestOptions{ID: otherIngestID}).
+					Times(1).
+					Return(&sdump.IngestHTTPRequest{
+						ID: otherIngestID,
+						Request: sdump.RequestDefinition{
+							Method: http.MethodPost,
+							Query:  "attempt=2",
+							Headers: http.Header{
+								"Content-Type":     []string{"application/json"},
+								"Stripe-Signature": []string{"t=2,v1=def"},
+								"User-Agent":       []string{"Stripe/1.0"},
+							},
+							Body: `{"data": {"amount": 100, "currency": "usd"}, "type": "invoice.paid"}`,
+						},
+					}, nil)
+
+				urlRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(2).
+					Return(&sdump.URLEndpoint{UserID: testUser.ID}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+
+			req := withUserAndParams(httptest.NewRequest(http.MethodGet, "/?with="+v.with, nil),
+				map[string]string{"id": testIngestID.String()})
+
+			logrus.SetOutput(io.Discard)
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ingestRepo := mocks.NewMockIngestRepository(ctrl)
+			urlRepo := mocks.NewMockURLRepository(ctrl)
+
+			v.mockFn(ingestRepo, urlRepo)
+
+			h := &ingestHandler{
+				logger:     logrus.WithField("module", "test"),
+				cfg:        config.Config{},
+				urlRepo:    urlRepo,
+				ingestRepo: ingestRepo,
+			}
+
+			h.diff(recorder, req)
+
+			require.Equal(t, v.expectedStatusCode, recorder.Result().StatusCode)
+			verifyMatch(t, recorder)
+		})
+	}
+}
+
+func TestIngestHandler_Annotate(t *testing.T) {
+	tt := []struct {
+		name               string
+		body               string
+		mockFn             func(ingestRepo *mocks.MockIngestRepository, urlRepo *mocks.MockURLRepository)
+		expectedStatusCode int
+	}{
+		{
+			name:               "invalid request body",
+			body:               `{"tags": "stripe"}`,
+			mockFn:             func(_ *mocks.MockIngestRepository, _ *mocks.MockURLRepository) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "tag too long",
+			body:               `{"tags": ["` + strings.Repeat("a", maxTagLength+1) + `"]}`,
+			mockFn:             func(_ *mocks.MockIngestRepository, _ *mocks.MockURLRepository) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "ingest not found",
+			body: `{"starred": true}`,
+			mockFn: func(ingestRepo *mocks.MockIngestRepository, _ *mocks.MockURLRepository) {
+				ingestRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sdump.ErrIngestNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name: "could not annotate ingest",
+			body: `{"starred": true}`,
+			mockFn: func(ingestRepo *mocks.MockIngestRepository, urlRepo *mocks.MockURLRepository) {
+				ingestRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&sdump.IngestHTTPRequest{ID: testIngestID}, nil)
+
+				urlRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&sdump.URLEndpoint{UserID: testUser.ID}, nil)
+
+				ingestRepo.EXPECT().Annotate(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(errors.New("could not annotate ingest"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+		{
+			name: "annotated ingest",
+			body: `{"starred": true, "note": "  reproduces bug #431 ", "tags": ["#stripe", "Bug", "bug", " "]}`,
+			mockFn: func(ingestRepo *mocks.MockIngestRepository, urlRepo *mocks.MockURLRepository) {
+				ingestRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&sdump.IngestHTTPRequest{
+						ID: testIngestID,
+						Request: sdump.RequestDefinition{
+							Method: http.MethodPost,
+							Body:   `{"name": "sdump"}`,
+						},
+						CreatedAt: testCreatedAt,
+					}, nil)
+
+				urlRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&sdump.URLEndpoint{UserID: testUser.ID}, nil)
+
+				ingestRepo.EXPECT().Annotate(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, ingest *sdump.IngestHTTPRequest) error {
+						require.True(t, ingest.Starred)
+						require.Equal(t, "reproduces bug #431", ingest.Note)
+						require.Equal(t, []string{"stripe", "Bug"}, ingest.Tags)
+						return nil
+					})
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+
+			req := withUserAndParams(httptest.NewRequest(http.MethodPut, "/", strings.NewReader(v.body)),
+				map[string]string{"id": testIngestID.String()})
+
+			logrus.SetOutput(io.Discard)
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ingestRepo := mocks.NewMockIngestRepository(ctrl)
+			urlRepo := mocks.NewMockURLRepository(ctrl)
+
+			v.mockFn(ingestRepo, urlRepo)
+
+			h := &ingestHandler{
+				logger:     logrus.WithField("module", "test"),
+				cfg:        config.Config{},
+				urlRepo:    urlRepo,
+				ingestRepo: ingestRepo,
+			}
+
+			h.annotate(recorder, req)
+
+			require.Equal(t, v.expectedStatusCode, recorder.Result().StatusCode)
+			verifyMatch(t, recorder)
+		})
+	}
+}
+
+func TestIngestHandler_Stats(t *testing.T) {
+	tt := []struct {
+		name               string
+		query              url.Values
+		mockFn             func(ingestRepo *mocks.MockIngestRepository, urlRepo *mocks.MockURLRepository)
+		expectedStatusCode int
+	}{
+		{
+			name:               "invalid minutes",
+			query:              url.Values{"minutes": []string{"-1"}},
+			mockFn:             func(_ *mocks.MockIngestRepository, _ *mocks.MockURLRepository) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:  "endpoint belongs to another user",
+			query: url.Values{},
+			mockFn: func(_ *mocks.MockIngestRepository, urlRepo *mocks.MockURLRepository) {
+				urlRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&sdump.URLEndpoint{UserID: uuid.New()}, nil)
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name:  "could not aggregate ingests",
+			query: url.Values{},
+			mockFn: func(ingestRepo *mocks.MockIngestRepository, urlRepo *mocks.MockURLRepository) {
+				urlRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&sdump.URLEndpoint{UserID: testUser.ID}, nil)
+
+				ingestRepo.EXPECT().Stats(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, errors.New("could not aggregate ingests"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+		{
+			name:  "fetched stats",
+			query: url.Values{"minutes": []string{"10"}},
+			mockFn: func(ingestRepo *mocks.MockIngestRepository, urlRepo *mocks.MockURLRepository) {
+				urlRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&sdump.URLEndpoint{UserID: testUser.ID}, nil)
+
+				ingestRepo.EXPECT().Stats(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, opts *sdump.IngestStatsOptions) (*sdump.IngestStats, error) {
+						require.WithinDuration(t, time.Now().Add(-10*time.Minute), opts.Since, 2*time.Minute)
+						require.Equal(t, statsTopIPs, opts.TopIPs)
+						require.Equal(t, statsTopPaths, opts.TopPaths)
+
+						return &sdump.IngestStats{
+							Total:     3,
+							TotalSize: 60,
+							MaxSize:   30,
+							Methods:   map[string]int64{http.MethodPost: 2, http.MethodGet: 1},
+							TopIPs:    []sdump.IPCount{{IP: "10.0.0.1", Count: 3}},
+							TopPaths: []sdump.PathCount{
+								{Path: "/", Count: 2},
+								{Path: "/stripe/events", Count: 1},
+							},
+							PerMinute: []sdump.MinuteCount{
+								{Minute: testCreatedAt.Truncate(time.Minute), Count: 2},
+								{Minute: testCreatedAt.Truncate(time.Minute).Add(time.Minute), Count: 1},
+							},
+						}, nil
+					})
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+
+			req := withUserAndParams(httptest.NewRequest(http.MethodGet, "/?"+v.query.Encode(), nil),
+				map[string]string{"reference": "cmltfm6g330l5l1vq110"})
+
+			logrus.SetOutput(io.Discard)
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ingestRepo := mocks.NewMockIngestRepository(ctrl)
+			urlRepo := mocks.NewMockURLRepository(ctrl)
+
+			v.mockFn(ingestRepo, urlRepo)
+
+			h := &ingestHandler{
+				logger:     logrus.WithField("module", "test"),
+				cfg:        config.Config{},
+				urlRepo:    urlRepo,
+				ingestRepo: ingestRepo,
+			}
+
+			h.stats(recorder, req)
+
+			require.Equal(t, v.expectedStatusCode, recorder.Result().StatusCode)
+			verifyMatch(t, recorder)
+		})
+	}
+}
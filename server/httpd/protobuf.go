@@ -0,0 +1,103 @@
+package httpd
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ayinke-llc/sdump"
+	"github.com/ayinke-llc/sdump/config"
+	"github.com/ayinke-llc/sdump/internal/codec"
+	"github.com/ayinke-llc/sdump/internal/payload"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+type protobufHandler struct {
+	logger  *logrus.Entry
+	urlRepo sdump.URLRepository
+	cfg     config.Config
+	decoder *payload.Decoder
+}
+
+// upload stores the FileDescriptorSet used to decode protobuf payloads sent
+// to an endpoint. The default message can be provided with the message query
+// parameter
+func (p *protobufHandler) upload(w http.ResponseWriter, r *http.Request) {
+	ctx, span, requestID := getTracer(r.Context(), r, "protobuf.upload")
+	defer span.End()
+
+	reference := chi.URLParam(r, "reference")
+
+	span.SetAttributes(attribute.String("reference", reference))
+
+	logger := p.logger.WithField("method", "protobuf.upload").
+		WithField("request_id", requestID).
+		WithField("reference", reference)
+
+	endpoint, err := findEndpointForUser(ctx, p.urlRepo, reference, getUserFromContext(ctx))
+	if errors.Is(err, sdump.ErrURLEndpointNotFound) {
+		span.SetStatus(codes.Error, "endpoint not found")
+		_ = render.Render(w, r, newAPIError(http.StatusNotFound, "Dump url does not exist"))
+		return
+	}
+
+	if err != nil {
+		logger.WithError(err).Error("could not fetch endpoint")
+		span.SetStatus(codes.Error, "could not fetch endpoint")
+		_ = render.Render(w, r, newAPIError(http.StatusInternalServerError,
+			"an error occurred while fetching endpoint"))
+		return
+	}
+
+	descriptorSet, err := io.ReadAll(http.MaxBytesReader(w, r.Body, p.cfg.HTTP.MaxImportSize))
+	if err != nil {
+		msg := "could not read descriptor set"
+
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			msg = maxErr.Error()
+		}
+
+		span.SetStatus(codes.Error, "could not read descriptor set")
+		_ = render.Render(w, r, newAPIError(http.StatusBadRequest, msg))
+		return
+	}
+
+	defaultMessage := strings.TrimSpace(r.URL.Query().Get("message"))
+
+	registry, err := codec.NewRegistry(descriptorSet, defaultMessage)
+	if err != nil {
+		span.SetStatus(codes.Error, "invalid descriptor set")
+		_ = render.Render(w, r, newAPIError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	endpoint.Metadata.Protobuf = &sdump.ProtobufSchema{
+		DescriptorSet:  descriptorSet,
+		DefaultMessage: defaultMessage,
+		UploadedAt:     time.Now(),
+	}
+
+	if err := p.urlRepo.Update(ctx, endpoint); err != nil {
+		logger.WithError(err).Error("could not store descriptor set")
+		span.SetStatus(codes.Error, "could not store descriptor set")
+		_ = render.Render(w, r, newAPIError(http.StatusInternalServerError,
+			"an error occurred while storing descriptor set"))
+		return
+	}
+
+	p.decoder.Forget(endpoint.ID)
+
+	span.SetStatus(codes.Ok, "uploaded descriptor set")
+	_ = render.Render(w, r, &protobufResponse{
+		APIStatus:      newAPIStatus(http.StatusOK, "uploaded descriptor set"),
+		Messages:       registry.Messages(),
+		DefaultMessage: defaultMessage,
+	})
+}
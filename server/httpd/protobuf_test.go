@@ -0,0 +1,153 @@
+package httpd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ayinke-llc/sdump"
+	"github.com/ayinke-llc/sdump/config"
+	"github.com/ayinke-llc/sdump/internal/payload"
+	"github.com/ayinke-llc/sdump/mocks"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// testDescriptorSet only holds google.protobuf.Timestamp
+func testDescriptorSet(t *testing.T) []byte {
+	t.Helper()
+
+	b, err := proto.Marshal(&descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{
+			protodesc.ToFileDescriptorProto(timestamppb.File_google_protobuf_timestamp_proto),
+		},
+	})
+	require.NoError(t, err)
+
+	return b
+}
+
+func TestProtobufHandler_Upload(t *testing.T) {
+	tt := []struct {
+		name               string
+		descriptorSet      []byte
+		message            string
+		mockFn             func(urlRepo *mocks.MockURLRepository)
+		expectedStatusCode int
+	}{
+		{
+			name:          "endpoint belongs to another user",
+			descriptorSet: testDescriptorSet(t),
+			mockFn: func(urlRepo *mocks.MockURLRepository) {
+				urlRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&sdump.URLEndpoint{UserID: uuid.New()}, nil)
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name:          "invalid descriptor set",
+			descriptorSet: []byte("oops"),
+			mockFn: func(urlRepo *mocks.MockURLRepository) {
+				urlRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&sdump.URLEndpoint{UserID: testUser.ID}, nil)
+			},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:          "unknown default message",
+			descriptorSet: testDescriptorSet(t),
+			message:       "google.protobuf.Duration",
+			mockFn: func(urlRepo *mocks.MockURLRepository) {
+				urlRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&sdump.URLEndpoint{UserID: testUser.ID}, nil)
+			},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:          "could not store descriptor set",
+			descriptorSet: testDescriptorSet(t),
+			mockFn: func(urlRepo *mocks.MockURLRepository) {
+				urlRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&sdump.URLEndpoint{UserID: testUser.ID}, nil)
+
+				urlRepo.EXPECT().Update(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(errors.New("could not update endpoint"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+		{
+			name:          "uploaded descriptor set",
+			descriptorSet: testDescriptorSet(t),
+			message:       "google.protobuf.Timestamp",
+			mockFn: func(urlRepo *mocks.MockURLRepository) {
+				urlRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&sdump.URLEndpoint{UserID: testUser.ID}, nil)
+
+				urlRepo.EXPECT().Update(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, endpoint *sdump.URLEndpoint) error {
+						require.Equal(t, testDescriptorSet(t), endpoint.Metadata.Protobuf.DescriptorSet)
+						require.Equal(t, "google.protobuf.Timestamp", endpoint.Metadata.Protobuf.DefaultMessage)
+						return nil
+					})
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+
+			target := "/"
+			if v.message != "" {
+				target += "?message=" + v.message
+			}
+
+			req := withUserAndParams(httptest.NewRequest(http.MethodPut, target,
+				bytes.NewReader(v.descriptorSet)),
+				map[string]string{"reference": "cmltfm6g330l5l1vq110"})
+
+			logrus.SetOutput(io.Discard)
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			urlRepo := mocks.NewMockURLRepository(ctrl)
+
+			v.mockFn(urlRepo)
+
+			h := &protobufHandler{
+				logger: logrus.WithField("module", "test"),
+				cfg: config.Config{
+					HTTP: config.HTTPConfig{
+						MaxImportSize: 1024,
+					},
+				},
+				urlRepo: urlRepo,
+				decoder: payload.NewDecoder(logrus.WithField("module", "test"), 1024),
+			}
+
+			h.upload(recorder, req)
+
+			require.Equal(t, v.expectedStatusCode, recorder.Result().StatusCode)
+			verifyMatch(t, recorder)
+		})
+	}
+}
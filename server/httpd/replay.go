@@ -0,0 +1,177 @@
+package httpd
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/ayinke-llc/sdump"
+	"github.com/ayinke-llc/sdump/config"
+	"github.com/ayinke-llc/sdump/internal/replay"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+type replayHandler struct {
+	logger     *logrus.Entry
+	urlRepo    sdump.URLRepository
+	ingestRepo sdump.IngestRepository
+	replayRepo sdump.ReplayRepository
+	cfg        config.Config
+	replayer   *replay.Client
+}
+
+type replayIngestRequest struct {
+	TargetURL string            `json:"target_url,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+
+	// Request is an edited variant of the captured request. If provided, it
+	// is sent in place of the captured request and stored with the replay so
+	// variants can be compared later on
+	Request *sdump.RequestDefinition `json:"request,omitempty"`
+}
+
+func (rh *replayHandler) replay(w http.ResponseWriter, r *http.Request) {
+	ctx, span, requestID := getTracer(r.Context(), r, "replay.create")
+	defer span.End()
+
+	logger := rh.logger.WithField("method", "replay.create").
+		WithField("request_id", requestID)
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		span.SetStatus(codes.Error, "invalid ingest id")
+		_ = render.Render(w, r, newAPIError(http.StatusBadRequest, "please provide a valid ingest id"))
+		return
+	}
+
+	span.SetAttributes(attribute.String("ingest_id", id.String()))
+
+	req := new(replayIngestRequest)
+
+	// an edited request can carry a body as large as an imported one
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, rh.cfg.HTTP.MaxImportSize)).Decode(req); err != nil {
+		span.SetStatus(codes.Error, "invalid request body")
+		_ = render.Render(w, r, newAPIError(http.StatusBadRequest, "please provide a valid request body"))
+		return
+	}
+
+	if err := replay.ValidateTarget(req.TargetURL); err != nil {
+		span.SetStatus(codes.Error, "invalid target url")
+		_ = render.Render(w, r, newAPIError(http.StatusBadRequest, "please provide a valid target url"))
+		return
+	}
+
+	ingest, err := findIngestForUser(ctx, rh.ingestRepo, rh.urlRepo, id, getUserFromContext(ctx))
+	if errors.Is(err, sdump.ErrIngestNotFound) {
+		span.SetStatus(codes.Error, "ingest not found")
+		_ = render.Render(w, r, newAPIError(http.StatusNotFound, "ingested request does not exist"))
+		return
+	}
+
+	if err != nil {
+		logger.WithError(err).Error("could not fetch ingested request")
+		span.SetStatus(codes.Error, "could not fetch ingested request")
+		_ = render.Render(w, r, newAPIError(http.StatusInternalServerError,
+			"an error occurred while fetching ingested request"))
+		return
+	}
+
+	def := ingest.Request
+	if req.Request != nil {
+		def = *req.Request
+		def.IPAddress = ingest.Request.IPAddress
+
+		// binary bodies are sent as the bytes they were captured as
+		body, err := def.OriginalBody()
+		if err != nil {
+			span.SetStatus(codes.Error, "invalid request body")
+			_ = render.Render(w, r, newAPIError(http.StatusBadRequest, "please provide a valid request body"))
+			return
+		}
+
+		def.Size = int64(len(body))
+	}
+
+	result, err := rh.replayer.Do(ctx, def, &replay.Options{
+		Target:  req.TargetURL,
+		Headers: req.Headers,
+	})
+	if err != nil {
+		span.SetStatus(codes.Error, "could not build replay request")
+		_ = render.Render(w, r, newAPIError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	result.IngestID = ingest.ID
+
+	if err := rh.replayRepo.Create(ctx, result); err != nil {
+		logger.WithError(err).Error("could not store replay")
+		span.SetStatus(codes.Error, "could not store replay")
+		_ = render.Render(w, r, newAPIError(http.StatusInternalServerError,
+			"an error occurred while storing replay"))
+		return
+	}
+
+	msg := "replayed request"
+	if result.Error != "" {
+		msg = "could not reach target"
+	}
+
+	span.SetStatus(codes.Ok, msg)
+	_ = render.Render(w, r, &replayResponse{
+		APIStatus: newAPIStatus(http.StatusOK, msg),
+		Replay:    result,
+	})
+}
+
+func (rh *replayHandler) list(w http.ResponseWriter, r *http.Request) {
+	ctx, span, requestID := getTracer(r.Context(), r, "replay.list")
+	defer span.End()
+
+	logger := rh.logger.WithField("method", "replay.list").
+		WithField("request_id", requestID)
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		span.SetStatus(codes.Error, "invalid ingest id")
+		_ = render.Render(w, r, newAPIError(http.StatusBadRequest, "please provide a valid ingest id"))
+		return
+	}
+
+	ingest, err := findIngestForUser(ctx, rh.ingestRepo, rh.urlRepo, id, getUserFromContext(ctx))
+	if errors.Is(err, sdump.ErrIngestNotFound) {
+		span.SetStatus(codes.Error, "ingest not found")
+		_ = render.Render(w, r, newAPIError(http.StatusNotFound, "ingested request does not exist"))
+		return
+	}
+
+	if err != nil {
+		logger.WithError(err).Error("could not fetch ingested request")
+		span.SetStatus(codes.Error, "could not fetch ingested request")
+		_ = render.Render(w, r, newAPIError(http.StatusInternalServerError,
+			"an error occurred while fetching ingested request"))
+		return
+	}
+
+	replays, err := rh.replayRepo.List(ctx, &sdump.FindReplayOptions{
+		IngestID: ingest.ID,
+	})
+	if err != nil {
+		logger.WithError(err).Error("could not list replays")
+		span.SetStatus(codes.Error, "could not list replays")
+		_ = render.Render(w, r, newAPIError(http.StatusInternalServerError,
+			"an error occurred while fetching replays"))
+		return
+	}
+
+	span.SetStatus(codes.Ok, "listed replays")
+	_ = render.Render(w, r, &replayListResponse{
+		APIStatus: newAPIStatus(http.StatusOK, "fetched replays"),
+		Replays:   replays,
+	})
+}
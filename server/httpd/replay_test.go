@@ -0,0 +1,385 @@
+package httpd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ayinke-llc/sdump"
+	"github.com/ayinke-llc/sdump/config"
+	"github.com/ayinke-llc/sdump/internal/replay"
+	"github.com/ayinke-llc/sdump/mocks"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+var (
+	testUser = &sdump.User{
+		ID: uuid.MustParse("8511ac86-5079-42ae-a030-cb46e6dbfbda"),
+	}
+
+	testIngestID = uuid.MustParse("0c7b3b0a-6f4d-4f0e-9a6e-0c7f6fd2c6a1")
+)
+
+func withUserAndParams(r *http.Request, params map[string]string) *http.Request {
+	rctx := chi.NewRouteContext()
+	for k, v := range params {
+		rctx.URLParams.Add(k, v)
+	}
+
+	ctx := context.WithValue(r.Context(), chi.RouteCtxKey, rctx)
+	ctx = context.WithValue(ctx, userCtx, testUser)
+
+	return r.WithContext(ctx)
+}
+
+func TestReplayHandler_Replay(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+		_, _ = w.Write([]byte(`{"status": "received"}`))
+	}))
+	defer target.Close()
+
+	tt := []struct {
+		name   string
+		mockFn func(ingestRepo *mocks.MockIngestRepository,
+			urlRepo *mocks.MockURLRepository,
+			replayRepo *mocks.MockReplayRepository)
+		id                 string
+		requestBody        replayIngestRequest
+		expectedStatusCode int
+		hasDynamicData     bool
+	}{
+		{
+			name:               "invalid ingest id",
+			id:                 "oops",
+			mockFn:             func(_ *mocks.MockIngestRepository, _ *mocks.MockURLRepository, _ *mocks.MockReplayRepository) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "invalid target url",
+			id:                 testIngestID.String(),
+			mockFn:             func(_ *mocks.MockIngestRepository, _ *mocks.MockURLRepository, _ *mocks.MockReplayRepository) {},
+			expectedStatusCode: http.StatusBadRequest,
+			requestBody: replayIngestRequest{
+				TargetURL: "ftp://localhost",
+			},
+		},
+		{
+			name:               "request body too large",
+			id:                 testIngestID.String(),
+			mockFn:             func(_ *mocks.MockIngestRepository, _ *mocks.MockURLRepository, _ *mocks.MockReplayRepository) {},
+			expectedStatusCode: http.StatusBadRequest,
+			requestBody: replayIngestRequest{
+				TargetURL: target.URL,
+				Headers: map[string]string{
+					"X-Sdump": strings.Repeat("a", 2048),
+				},
+			},
+		},
+		{
+			name: "ingest not found",
+			id:   testIngestID.String(),
+			mockFn: func(ingestRepo *mocks.MockIngestRepository, _ *mocks.MockURLRepository, _ *mocks.MockReplayRepository) {
+				ingestRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sdump.ErrIngestNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+			requestBody: replayIngestRequest{
+				TargetURL: target.URL,
+			},
+		},
+		{
+			name: "ingest belongs to another user",
+			id:   testIngestID.String(),
+			mockFn: func(ingestRepo *mocks.MockIngestRepository, urlRepo *mocks.MockURLRepository, _ *mocks.MockReplayRepository) {
+				ingestRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&sdump.IngestHTTPRequest{ID: testIngestID}, nil)
+
+				urlRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&sdump.URLEndpoint{UserID: uuid.New()}, nil)
+			},
+			expectedStatusCode: http.StatusNotFound,
+			requestBody: replayIngestRequest{
+				TargetURL: target.URL,
+			},
+		},
+		{
+			name: "could not store replay",
+			id:   testIngestID.String(),
+			mockFn: func(ingestRepo *mocks.MockIngestRepository, urlRepo *mocks.MockURLRepository, replayRepo *mocks.MockReplayRepository) {
+				ingestRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&sdump.IngestHTTPRequest{ID: testIngestID}, nil)
+
+				urlRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&sdump.URLEndpoint{UserID: testUser.ID}, nil)
+
+				replayRepo.EXPECT().Create(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(errors.New("could not store replay"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			requestBody: replayIngestRequest{
+				TargetURL: target.URL,
+			},
+		},
+		{
+			name:           "replayed request",
+			id:             testIngestID.String(),
+			hasDynamicData: true,
+			mockFn: func(ingestRepo *mocks.MockIngestRepository, urlRepo *mocks.MockURLRepository, replayRepo *mocks.MockReplayRepository) {
+				ingestRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&sdump.IngestHTTPRequest{
+						ID: testIngestID,
+						Request: sdump.RequestDefinition{
+							Method: http.MethodPost,
+							Body:   `{"name": "sdump"}`,
+						},
+					}, nil)
+
+				urlRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&sdump.URLEndpoint{UserID: testUser.ID}, nil)
+
+				replayRepo.EXPECT().Create(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, r *sdump.Replay) error {
+						require.Equal(t, testIngestID, r.IngestID)
+						require.Equal(t, http.StatusTeapot, r.Response.StatusCode)
+						require.Equal(t, "override", r.Request.Headers.Get("X-Sdump"))
+						return nil
+					})
+			},
+			expectedStatusCode: http.StatusOK,
+			requestBody: replayIngestRequest{
+				TargetURL: target.URL,
+				Headers: map[string]string{
+					"X-Sdump": "override",
+				},
+			},
+		},
+		{
+			name:           "replayed edited request",
+			id:             testIngestID.String(),
+			hasDynamicData: true,
+			mockFn: func(ingestRepo *mocks.MockIngestRepository, urlRepo *mocks.MockURLRepository, replayRepo *mocks.MockReplayRepository) {
+				ingestRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&sdump.IngestHTTPRequest{
+						ID: testIngestID,
+						Request: sdump.RequestDefinition{
+							Method: http.MethodPost,
+							Body:   `{"name": "sdump"}`,
+						},
+					}, nil)
+
+				urlRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&sdump.URLEndpoint{UserID: testUser.ID}, nil)
+
+				replayRepo.EXPECT().Create(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, r *sdump.Replay) error {
+						require.Equal(t, http.MethodPatch, r.Request.Method)
+						require.Equal(t, `{"name": "edited"}`, r.Request.Body)
+						require.Equal(t, int64(18), r.Request.Size)
+						return nil
+					})
+			},
+			expectedStatusCode: http.StatusOK,
+			requestBody: replayIngestRequest{
+				TargetURL: target.URL,
+				Request: &sdump.RequestDefinition{
+					Method: http.MethodPatch,
+					Body:   `{"name": "edited"}`,
+				},
+			},
+		},
+		{
+			name:           "replayed binary request",
+			id:             testIngestID.String(),
+			hasDynamicData: true,
+			mockFn: func(ingestRepo *mocks.MockIngestRepository, urlRepo *mocks.MockURLRepository, replayRepo *mocks.MockReplayRepository) {
+				ingestRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&sdump.IngestHTTPRequest{ID: testIngestID}, nil)
+
+				urlRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&sdump.URLEndpoint{UserID: testUser.ID}, nil)
+
+				replayRepo.EXPECT().Create(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, r *sdump.Replay) error {
+						// the size of the msgpack bytes, not of the JSON view
+						require.Equal(t, int64(12), r.Request.Size)
+						return nil
+					})
+			},
+			expectedStatusCode: http.StatusOK,
+			requestBody: replayIngestRequest{
+				TargetURL: target.URL,
+				Request: &sdump.RequestDefinition{
+					Method: http.MethodPost,
+					Body:   `{"name":"sdump"}`,
+					Binary: &sdump.BinaryBody{
+						Format:   "msgpack",
+						Original: "gaRuYW1lpXNkdW1w",
+					},
+				},
+			},
+		},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+
+			b := new(bytes.Buffer)
+
+			err := json.NewEncoder(b).Encode(v.requestBody)
+			require.NoError(t, err)
+
+			req := withUserAndParams(httptest.NewRequest(http.MethodPost, "/", b),
+				map[string]string{"id": v.id})
+
+			logrus.SetOutput(io.Discard)
+
+			logger := logrus.WithField("module", "test")
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ingestRepo := mocks.NewMockIngestRepository(ctrl)
+			urlRepo := mocks.NewMockURLRepository(ctrl)
+			replayRepo := mocks.NewMockReplayRepository(ctrl)
+
+			v.mockFn(ingestRepo, urlRepo, replayRepo)
+
+			h := &replayHandler{
+				logger: logger,
+				cfg: config.Config{
+					HTTP: config.HTTPConfig{
+						MaxImportSize: 1024,
+					},
+				},
+				urlRepo:    urlRepo,
+				ingestRepo: ingestRepo,
+				replayRepo: replayRepo,
+				replayer:   replay.New(time.Second*5, true),
+			}
+
+			h.replay(recorder, req)
+
+			require.Equal(t, v.expectedStatusCode, recorder.Result().StatusCode)
+
+			if !v.hasDynamicData {
+				verifyMatch(t, recorder)
+			}
+		})
+	}
+}
+
+func TestReplayHandler_List(t *testing.T) {
+	tt := []struct {
+		name   string
+		mockFn func(ingestRepo *mocks.MockIngestRepository,
+			urlRepo *mocks.MockURLRepository,
+			replayRepo *mocks.MockReplayRepository)
+		expectedStatusCode int
+	}{
+		{
+			name: "could not list replays",
+			mockFn: func(ingestRepo *mocks.MockIngestRepository, urlRepo *mocks.MockURLRepository, replayRepo *mocks.MockReplayRepository) {
+				ingestRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&sdump.IngestHTTPRequest{ID: testIngestID}, nil)
+
+				urlRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&sdump.URLEndpoint{UserID: testUser.ID}, nil)
+
+				replayRepo.EXPECT().List(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, errors.New("could not list replays"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+		{
+			name: "listed replays",
+			mockFn: func(ingestRepo *mocks.MockIngestRepository, urlRepo *mocks.MockURLRepository, replayRepo *mocks.MockReplayRepository) {
+				ingestRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&sdump.IngestHTTPRequest{ID: testIngestID}, nil)
+
+				urlRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&sdump.URLEndpoint{UserID: testUser.ID}, nil)
+
+				replayRepo.EXPECT().List(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]sdump.Replay{
+						{
+							IngestID:  testIngestID,
+							TargetURL: "http://localhost:3000",
+							Response: sdump.ResponseDefinition{
+								StatusCode: http.StatusOK,
+							},
+						},
+					}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+
+			req := withUserAndParams(httptest.NewRequest(http.MethodGet, "/", nil),
+				map[string]string{"id": testIngestID.String()})
+
+			logrus.SetOutput(io.Discard)
+
+			logger := logrus.WithField("module", "test")
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ingestRepo := mocks.NewMockIngestRepository(ctrl)
+			urlRepo := mocks.NewMockURLRepository(ctrl)
+			replayRepo := mocks.NewMockReplayRepository(ctrl)
+
+			v.mockFn(ingestRepo, urlRepo, replayRepo)
+
+			h := &replayHandler{
+				logger:     logger,
+				cfg:        config.Config{},
+				urlRepo:    urlRepo,
+				ingestRepo: ingestRepo,
+				replayRepo: replayRepo,
+			}
+
+			h.list(recorder, req)
+
+			require.Equal(t, v.expectedStatusCode, recorder.Result().StatusCode)
+			verifyMatch(t, recorder)
+		})
+	}
+}
@@ -3,6 +3,8 @@ package httpd
 import (
 	"net/http"
 
+	"github.com/ayinke-llc/sdump"
+	"github.com/ayinke-llc/sdump/internal/diff"
 	"github.com/go-chi/render"
 )
 
@@ -47,3 +49,53 @@ type createdURLEndpointResponse struct {
 	} `json:"sse,omitempty"`
 	APIStatus
 }
+
+type replayResponse struct {
+	Replay *sdump.Replay `json:"replay,omitempty"`
+	APIStatus
+}
+
+type ingestResponse struct {
+	Ingest *sdump.IngestHTTPRequest `json:"ingest"`
+	APIStatus
+}
+
+type ingestListResponse struct {
+	Ingests []sdump.IngestHTTPRequest `json:"ingests"`
+	// Cursor is set when there might be older matches. It can be passed
+	// back as the cursor parameter
+	Cursor string `json:"cursor,omitempty"`
+	APIStatus
+}
+
+type statsResponse struct {
+	Stats *sdump.IngestStats `json:"stats"`
+	APIStatus
+}
+
+type preferencesResponse struct {
+	Preferences *sdump.Preferences `json:"preferences"`
+	APIStatus
+}
+
+type importResponse struct {
+	Imported int `json:"imported"`
+	APIStatus
+}
+
+type protobufResponse struct {
+	Messages       []string `json:"messages"`
+	DefaultMessage string   `json:"default_message,omitempty"`
+	APIStatus
+}
+
+type replayListResponse struct {
+	Replays []sdump.Replay `json:"replays"`
+	APIStatus
+}
+
+type diffResponse struct {
+	Equal bool         `json:"equal"`
+	Diff  *diff.Result `json:"diff"`
+	APIStatus
+}
@@ -8,11 +8,11 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/ayinke-llc/sdump"
 	"github.com/ayinke-llc/sdump/config"
+	"github.com/ayinke-llc/sdump/internal/payload"
 	"github.com/ayinke-llc/sdump/internal/util"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
@@ -30,6 +30,7 @@ type urlHandler struct {
 	userRepo   sdump.UserRepository
 	cfg        config.Config
 	sseServer  *sse.Server
+	decoder    *payload.Decoder
 }
 
 type createURLRequest struct {
@@ -138,6 +139,44 @@ func (u *urlHandler) create(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// latest returns the endpoint the user last created. The ssh commands use it
+// when no reference is provided
+func (u *urlHandler) latest(w http.ResponseWriter, r *http.Request) {
+	ctx, span, requestID := getTracer(r.Context(), r, "url.latest")
+	defer span.End()
+
+	logger := u.logger.WithField("method", "url.latest").
+		WithField("request_id", requestID)
+
+	endpoint, err := u.urlRepo.Latest(ctx, getUserFromContext(ctx).ID)
+	if errors.Is(err, sdump.ErrURLEndpointNotFound) {
+		span.SetStatus(codes.Error, "endpoint not found")
+		_ = render.Render(w, r, newAPIError(http.StatusNotFound,
+			"you have no endpoint yet. Connect without a command to create one"))
+		return
+	}
+
+	if err != nil {
+		logger.WithError(err).Error("could not fetch latest url endpoint")
+		span.SetStatus(codes.Error, "could not fetch latest url endpoint")
+		_ = render.Render(w, r, newAPIError(http.StatusInternalServerError,
+			"an error occurred while fetching your endpoint"))
+		return
+	}
+
+	resp := &createdURLEndpointResponse{
+		APIStatus: newAPIStatus(http.StatusOK, "fetched latest url endpoint"),
+	}
+
+	resp.SSE.Channel = endpoint.PubChannel()
+	resp.URL.FQDN = u.cfg.HTTP.Domain
+	resp.URL.Identifier = endpoint.Reference
+	resp.URL.HumanReadableEndpoint = fmt.Sprintf("%s/%s", u.cfg.HTTP.Domain, endpoint.Reference)
+
+	span.SetStatus(codes.Ok, "fetched latest url endpoint")
+	_ = render.Render(w, r, resp)
+}
+
 func (u *urlHandler) createOrFetchEndpoint(
 	ctx context.Context,
 	endpoint *sdump.URLEndpoint,
@@ -204,9 +243,9 @@ func (u *urlHandler) ingest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	s := &strings.Builder{}
+	b := new(bytes.Buffer)
 
-	size, err := io.Copy(s, r.Body)
+	size, err := io.Copy(b, r.Body)
 	if err != nil {
 		failedIngestedHTTPRequestsCounter.Inc()
 		msg := "could not copy request body"
@@ -226,15 +265,17 @@ func (u *urlHandler) ingest(w http.ResponseWriter, r *http.Request) {
 	ingestedRequest := &sdump.IngestHTTPRequest{
 		UrlID: endpoint.ID,
 		Request: sdump.RequestDefinition{
-			Body:      s.String(),
 			Query:     r.URL.Query().Encode(),
 			Headers:   r.Header,
 			IPAddress: util.GetIP(r),
 			Size:      size,
 			Method:    r.Method,
+			Path:      ingestPath(r),
 		},
 	}
 
+	u.decoder.Decode(endpoint, &ingestedRequest.Request, b.Bytes())
+
 	if err := u.ingestRepo.Create(ctx, ingestedRequest); err != nil {
 		failedIngestedHTTPRequestsCounter.Inc()
 		logger.WithError(err).Error("could not ingest request")
@@ -278,3 +319,13 @@ func (u *urlHandler) ingest(w http.ResponseWriter, r *http.Request) {
 	_ = render.Render(w, r, newAPIStatus(http.StatusAccepted,
 		"Request ingested"))
 }
+
+// ingestPath is what the request was sent to after the endpoint reference
+func ingestPath(r *http.Request) string {
+	path := chi.URLParam(r, "*")
+	if path == "" {
+		return ""
+	}
+
+	return "/" + path
+}
@@ -2,6 +2,8 @@ package httpd
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
@@ -12,12 +14,16 @@ import (
 
 	"github.com/ayinke-llc/sdump"
 	"github.com/ayinke-llc/sdump/config"
+	"github.com/ayinke-llc/sdump/internal/payload"
 	"github.com/ayinke-llc/sdump/mocks"
+	"github.com/google/uuid"
 	"github.com/r3labs/sse/v2"
 	"github.com/sebdah/goldie/v2"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func verifyMatch(t *testing.T, v interface{}) {
@@ -245,6 +251,78 @@ func TestURLHandler_Create(t *testing.T) {
 	}
 }
 
+func TestURLHandler_Latest(t *testing.T) {
+	tt := []struct {
+		name               string
+		mockFn             func(urlRepo *mocks.MockURLRepository)
+		expectedStatusCode int
+	}{
+		{
+			name: "user has no endpoint",
+			mockFn: func(urlRepo *mocks.MockURLRepository) {
+				urlRepo.EXPECT().Latest(gomock.Any(), testUser.ID).
+					Times(1).
+					Return(nil, sdump.ErrURLEndpointNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name: "could not fetch endpoint",
+			mockFn: func(urlRepo *mocks.MockURLRepository) {
+				urlRepo.EXPECT().Latest(gomock.Any(), testUser.ID).
+					Times(1).
+					Return(nil, errors.New("could not fetch endpoint"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+		{
+			name: "fetched latest endpoint",
+			mockFn: func(urlRepo *mocks.MockURLRepository) {
+				urlRepo.EXPECT().Latest(gomock.Any(), testUser.ID).
+					Times(1).
+					Return(&sdump.URLEndpoint{
+						ID:        uuid.MustParse("6e8fd3b8-6d0d-4c4b-9a59-4d7a6b0ad1f1"),
+						Reference: "cf8b2a",
+						UserID:    testUser.ID,
+					}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+
+			req := withUserAndParams(httptest.NewRequest(http.MethodGet, "/api/urls/latest", nil), nil)
+
+			logrus.SetOutput(io.Discard)
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			urlRepo := mocks.NewMockURLRepository(ctrl)
+
+			v.mockFn(urlRepo)
+
+			u := &urlHandler{
+				logger: logrus.WithField("module", "test"),
+				cfg: config.Config{
+					HTTP: config.HTTPConfig{
+						Domain: "https://sdump.app",
+					},
+				},
+				urlRepo: urlRepo,
+			}
+
+			u.latest(recorder, req)
+
+			require.Equal(t, v.expectedStatusCode, recorder.Result().StatusCode)
+			verifyMatch(t, recorder)
+		})
+	}
+}
+
 func TestURLHandler_Ingest(t *testing.T) {
 	tt := []struct {
 		name               string
@@ -252,6 +330,9 @@ func TestURLHandler_Ingest(t *testing.T) {
 		expectedStatusCode int
 		requestBody        io.Reader
 		requestBodySize    int64
+		contentEncoding    string
+		contentType        string
+		path               string
 	}{
 		{
 			name: "url reference not found",
@@ -311,6 +392,141 @@ func TestURLHandler_Ingest(t *testing.T) {
 			requestBody:        strings.NewReader(`{"name" : "Lanre", "occupation" :"Software"}`),
 			requestBodySize:    100,
 		},
+		{
+			name: "gzip body is decoded",
+			mockFn: func(urlRepo *mocks.MockURLRepository, requestRepo *mocks.MockIngestRepository) {
+				urlRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).Return(&sdump.URLEndpoint{}, nil)
+
+				requestRepo.EXPECT().Create(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, ingest *sdump.IngestHTTPRequest) error {
+						require.Equal(t, `{"name" : "Lanre", "occupation" :"Software"}`, ingest.Request.Body)
+						require.True(t, ingest.Request.IsDecoded())
+						require.Equal(t, int64(44), ingest.Request.Encoding.DecodedSize)
+
+						original, err := ingest.Request.OriginalBody()
+						require.NoError(t, err)
+						require.Equal(t, gzipBody(t, `{"name" : "Lanre", "occupation" :"Software"}`), original)
+						return nil
+					})
+			},
+			expectedStatusCode: http.StatusAccepted,
+			requestBody:        bytes.NewReader(gzipBody(t, `{"name" : "Lanre", "occupation" :"Software"}`)),
+			requestBodySize:    100,
+			contentEncoding:    "gzip",
+		},
+		{
+			name: "gzip body larger than the decoded limit is not decoded",
+			mockFn: func(urlRepo *mocks.MockURLRepository, requestRepo *mocks.MockIngestRepository) {
+				urlRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).Return(&sdump.URLEndpoint{}, nil)
+
+				requestRepo.EXPECT().Create(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, ingest *sdump.IngestHTTPRequest) error {
+						require.False(t, ingest.Request.IsDecoded())
+						require.Empty(t, ingest.Request.Body)
+
+						original, err := ingest.Request.OriginalBody()
+						require.NoError(t, err)
+						require.Equal(t, gzipBody(t, strings.Repeat("a", 1024)), original)
+						return nil
+					})
+			},
+			expectedStatusCode: http.StatusAccepted,
+			requestBody:        bytes.NewReader(gzipBody(t, strings.Repeat("a", 1024))),
+			requestBodySize:    100,
+			contentEncoding:    "gzip",
+		},
+		{
+			name: "protobuf body is decoded with the descriptor set of the endpoint",
+			mockFn: func(urlRepo *mocks.MockURLRepository, requestRepo *mocks.MockIngestRepository) {
+				urlRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).Return(&sdump.URLEndpoint{
+					Metadata: sdump.URLEndpointMetadata{
+						Protobuf: &sdump.ProtobufSchema{
+							DescriptorSet:  testDescriptorSet(t),
+							DefaultMessage: "google.protobuf.Timestamp",
+						},
+					},
+				}, nil)
+
+				requestRepo.EXPECT().Create(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, ingest *sdump.IngestHTTPRequest) error {
+						require.Equal(t, `"2024-01-20T14:30:00Z"`, ingest.Request.Body)
+						require.Equal(t, "protobuf", ingest.Request.Binary.Format)
+						require.Equal(t, "google.protobuf.Timestamp", ingest.Request.Binary.MessageType)
+						require.Empty(t, ingest.Request.Binary.Error)
+						return nil
+					})
+			},
+			expectedStatusCode: http.StatusAccepted,
+			requestBody:        bytes.NewReader(protobufBody(t)),
+			requestBodySize:    100,
+			contentType:        "application/x-protobuf",
+		},
+		{
+			name: "protobuf body without a descriptor set",
+			mockFn: func(urlRepo *mocks.MockURLRepository, requestRepo *mocks.MockIngestRepository) {
+				urlRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).Return(&sdump.URLEndpoint{}, nil)
+
+				requestRepo.EXPECT().Create(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, ingest *sdump.IngestHTTPRequest) error {
+						require.Empty(t, ingest.Request.Body)
+						require.NotEmpty(t, ingest.Request.Binary.Error)
+
+						original, err := ingest.Request.OriginalBody()
+						require.NoError(t, err)
+						require.Equal(t, protobufBody(t), original)
+						return nil
+					})
+			},
+			expectedStatusCode: http.StatusAccepted,
+			requestBody:        bytes.NewReader(protobufBody(t)),
+			requestBodySize:    100,
+			contentType:        "application/x-protobuf",
+		},
+		{
+			name: "unknown binary body is kept as sent",
+			mockFn: func(urlRepo *mocks.MockURLRepository, requestRepo *mocks.MockIngestRepository) {
+				urlRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).Return(&sdump.URLEndpoint{}, nil)
+
+				requestRepo.EXPECT().Create(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, ingest *sdump.IngestHTTPRequest) error {
+						require.Empty(t, ingest.Request.Body)
+						require.Equal(t, &sdump.BinaryBody{Original: "AAEC"}, ingest.Request.Binary)
+						return nil
+					})
+			},
+			expectedStatusCode: http.StatusAccepted,
+			requestBody:        bytes.NewReader([]byte{0, 1, 2}),
+			requestBodySize:    100,
+			contentType:        "application/octet-stream",
+		},
+		{
+			name: "path after the reference is kept",
+			mockFn: func(urlRepo *mocks.MockURLRepository, requestRepo *mocks.MockIngestRepository) {
+				urlRepo.EXPECT().Get(gomock.Any(), gomock.Any()).
+					Times(1).Return(&sdump.URLEndpoint{}, nil)
+
+				requestRepo.EXPECT().Create(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, ingest *sdump.IngestHTTPRequest) error {
+						require.Equal(t, "/stripe/events", ingest.Request.Path)
+						return nil
+					})
+			},
+			expectedStatusCode: http.StatusAccepted,
+			requestBody:        strings.NewReader(`{"name" : "Lanre", "occupation" :"Software"}`),
+			requestBodySize:    100,
+			path:               "stripe/events",
+		},
 	}
 
 	for _, v := range tt {
@@ -318,6 +534,17 @@ func TestURLHandler_Ingest(t *testing.T) {
 			recorder := httptest.NewRecorder()
 
 			req := httptest.NewRequest(http.MethodPost, "/", v.requestBody)
+			if v.contentEncoding != "" {
+				req.Header.Set("Content-Encoding", v.contentEncoding)
+			}
+
+			if v.contentType != "" {
+				req.Header.Set("Content-Type", v.contentType)
+			}
+
+			if v.path != "" {
+				req = withUserAndParams(req, map[string]string{"*": v.path})
+			}
 
 			logrus.SetOutput(io.Discard)
 
@@ -342,6 +569,7 @@ func TestURLHandler_Ingest(t *testing.T) {
 				urlRepo:    urlRepo,
 				ingestRepo: requestRepo,
 				sseServer:  sse.New(),
+				decoder:    payload.NewDecoder(logger, 512),
 			}
 
 			u.ingest(recorder, req)
@@ -351,3 +579,26 @@ func TestURLHandler_Ingest(t *testing.T) {
 		})
 	}
 }
+
+func gzipBody(t *testing.T, s string) []byte {
+	t.Helper()
+
+	var b bytes.Buffer
+
+	w := gzip.NewWriter(&b)
+
+	_, err := w.Write([]byte(s))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	return b.Bytes()
+}
+
+func protobufBody(t *testing.T) []byte {
+	t.Helper()
+
+	b, err := proto.Marshal(timestamppb.New(testCreatedAt))
+	require.NoError(t, err)
+
+	return b
+}
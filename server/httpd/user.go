@@ -0,0 +1,156 @@
+package httpd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/ayinke-llc/sdump"
+	"github.com/ayinke-llc/sdump/config"
+	"github.com/ayinke-llc/sdump/internal/inspect"
+	"github.com/go-chi/render"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+type userHandler struct {
+	cfg      config.Config
+	logger   *logrus.Entry
+	userRepo sdump.UserRepository
+}
+
+// preferences returns the TUI preferences of the user. Users that never
+// saved them get empty preferences so the server defaults apply
+func (u *userHandler) preferences(w http.ResponseWriter, r *http.Request) {
+	ctx, span, requestID := getTracer(r.Context(), r, "user.preferences")
+	defer span.End()
+
+	logger := u.logger.WithField("method", "user.preferences").
+		WithField("request_id", requestID)
+
+	user := getUserFromContext(ctx)
+
+	span.SetAttributes(attribute.String("user_id", user.ID.String()))
+
+	preferences, err := u.userRepo.FindPreferences(ctx, user.ID)
+	if errors.Is(err, sdump.ErrPreferencesNotFound) {
+		preferences = &sdump.Preferences{UserID: user.ID}
+		err = nil
+	}
+
+	if err != nil {
+		logger.WithError(err).Error("could not fetch preferences")
+		span.SetStatus(codes.Error, "could not fetch preferences")
+		_ = render.Render(w, r, newAPIError(http.StatusInternalServerError,
+			"an error occurred while fetching your preferences"))
+		return
+	}
+
+	span.SetStatus(codes.Ok, "fetched preferences")
+	_ = render.Render(w, r, &preferencesResponse{
+		APIStatus:   newAPIStatus(http.StatusOK, "fetched preferences"),
+		Preferences: preferences,
+	})
+}
+
+type savePreferencesRequest struct {
+	ColorScheme     string `json:"color_scheme"`
+	TimestampFormat string `json:"timestamp_format"`
+	Timezone        string `json:"timezone"`
+	BodyView        string `json:"body_view"`
+	KeymapPreset    string `json:"keymap_preset"`
+}
+
+// normalize trims every field and makes sure the TUI can apply them. Empty
+// fields are allowed and fall back to the server defaults
+func (s *savePreferencesRequest) normalize() error {
+	s.ColorScheme = strings.TrimSpace(s.ColorScheme)
+	s.TimestampFormat = strings.TrimSpace(s.TimestampFormat)
+	s.Timezone = strings.TrimSpace(s.Timezone)
+	s.BodyView = strings.TrimSpace(s.BodyView)
+	s.KeymapPreset = strings.TrimSpace(s.KeymapPreset)
+
+	if s.ColorScheme != "" {
+		if _, ok := styles.Registry[s.ColorScheme]; !ok {
+			return fmt.Errorf("%s is not a supported color scheme", s.ColorScheme)
+		}
+	}
+
+	if s.TimestampFormat != "" {
+		if _, ok := sdump.TimestampFormats[s.TimestampFormat]; !ok {
+			return fmt.Errorf("%s is not a supported timestamp format", s.TimestampFormat)
+		}
+	}
+
+	if s.Timezone != "" {
+		if _, err := time.LoadLocation(s.Timezone); err != nil {
+			return fmt.Errorf("%s is not a valid timezone", s.Timezone)
+		}
+	}
+
+	if s.BodyView != "" && !slices.Contains(inspect.Formats, inspect.Format(s.BodyView)) {
+		return fmt.Errorf("%s is not a supported body view", s.BodyView)
+	}
+
+	if s.KeymapPreset != "" && !slices.Contains(sdump.KeymapPresets, s.KeymapPreset) {
+		return fmt.Errorf("%s is not a supported keymap preset", s.KeymapPreset)
+	}
+
+	return nil
+}
+
+// savePreferences replaces the TUI preferences of the user
+func (u *userHandler) savePreferences(w http.ResponseWriter, r *http.Request) {
+	ctx, span, requestID := getTracer(r.Context(), r, "user.savePreferences")
+	defer span.End()
+
+	logger := u.logger.WithField("method", "user.savePreferences").
+		WithField("request_id", requestID)
+
+	user := getUserFromContext(ctx)
+
+	span.SetAttributes(attribute.String("user_id", user.ID.String()))
+
+	req := new(savePreferencesRequest)
+
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		span.SetStatus(codes.Error, "invalid request body")
+		_ = render.Render(w, r, newAPIError(http.StatusBadRequest, "please provide a valid request body"))
+		return
+	}
+
+	if err := req.normalize(); err != nil {
+		span.SetStatus(codes.Error, "invalid preferences")
+		_ = render.Render(w, r, newAPIError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	preferences := &sdump.Preferences{
+		UserID:          user.ID,
+		ColorScheme:     req.ColorScheme,
+		TimestampFormat: req.TimestampFormat,
+		Timezone:        req.Timezone,
+		BodyView:        req.BodyView,
+		KeymapPreset:    req.KeymapPreset,
+	}
+
+	if err := u.userRepo.SavePreferences(ctx, preferences); err != nil {
+		logger.WithError(err).Error("could not save preferences")
+		span.SetStatus(codes.Error, "could not save preferences")
+		_ = render.Render(w, r, newAPIError(http.StatusInternalServerError,
+			"an error occurred while saving your preferences"))
+		return
+	}
+
+	span.SetStatus(codes.Ok, "saved preferences")
+	_ = render.Render(w, r, &preferencesResponse{
+		APIStatus:   newAPIStatus(http.StatusOK, "saved preferences"),
+		Preferences: preferences,
+	})
+}
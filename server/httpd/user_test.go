@@ -0,0 +1,188 @@
+package httpd
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ayinke-llc/sdump"
+	"github.com/ayinke-llc/sdump/config"
+	"github.com/ayinke-llc/sdump/mocks"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestUserHandler_Preferences(t *testing.T) {
+	tt := []struct {
+		name               string
+		mockFn             func(userRepo *mocks.MockUserRepository)
+		expectedStatusCode int
+	}{
+		{
+			name: "could not fetch preferences",
+			mockFn: func(userRepo *mocks.MockUserRepository) {
+				userRepo.EXPECT().FindPreferences(gomock.Any(), testUser.ID).
+					Times(1).
+					Return(nil, errors.New("could not fetch preferences"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+		{
+			name: "preferences were never saved",
+			mockFn: func(userRepo *mocks.MockUserRepository) {
+				userRepo.EXPECT().FindPreferences(gomock.Any(), testUser.ID).
+					Times(1).
+					Return(nil, sdump.ErrPreferencesNotFound)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name: "fetched preferences",
+			mockFn: func(userRepo *mocks.MockUserRepository) {
+				userRepo.EXPECT().FindPreferences(gomock.Any(), testUser.ID).
+					Times(1).
+					Return(&sdump.Preferences{
+						UserID:          testUser.ID,
+						ColorScheme:     "dracula",
+						TimestampFormat: "iso",
+						Timezone:        "Africa/Lagos",
+						BodyView:        "json",
+						KeymapPreset:    "vim",
+					}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+
+			req := withUserAndParams(httptest.NewRequest(http.MethodGet, "/", nil), nil)
+
+			logrus.SetOutput(io.Discard)
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			userRepo := mocks.NewMockUserRepository(ctrl)
+
+			v.mockFn(userRepo)
+
+			h := &userHandler{
+				logger:   logrus.WithField("module", "test"),
+				cfg:      config.Config{},
+				userRepo: userRepo,
+			}
+
+			h.preferences(recorder, req)
+
+			require.Equal(t, v.expectedStatusCode, recorder.Result().StatusCode)
+			verifyMatch(t, recorder)
+		})
+	}
+}
+
+func TestUserHandler_SavePreferences(t *testing.T) {
+	tt := []struct {
+		name               string
+		body               string
+		mockFn             func(userRepo *mocks.MockUserRepository)
+		expectedStatusCode int
+	}{
+		{
+			name:               "invalid request body",
+			body:               "oops",
+			mockFn:             func(userRepo *mocks.MockUserRepository) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "unknown color scheme",
+			body:               `{"color_scheme": "rainbow"}`,
+			mockFn:             func(userRepo *mocks.MockUserRepository) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "unknown timestamp format",
+			body:               `{"timestamp_format": "unix"}`,
+			mockFn:             func(userRepo *mocks.MockUserRepository) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "invalid timezone",
+			body:               `{"timezone": "Mars/Olympus_Mons"}`,
+			mockFn:             func(userRepo *mocks.MockUserRepository) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "unknown body view",
+			body:               `{"body_view": "csv"}`,
+			mockFn:             func(userRepo *mocks.MockUserRepository) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "unknown keymap preset",
+			body:               `{"keymap_preset": "nano"}`,
+			mockFn:             func(userRepo *mocks.MockUserRepository) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "could not save preferences",
+			body: `{"color_scheme": "dracula"}`,
+			mockFn: func(userRepo *mocks.MockUserRepository) {
+				userRepo.EXPECT().SavePreferences(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(errors.New("could not save preferences"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+		{
+			name: "saved preferences",
+			body: `{"color_scheme": " dracula ", "timestamp_format": "iso", "timezone": "Africa/Lagos", "body_view": "json", "keymap_preset": "vim"}`,
+			mockFn: func(userRepo *mocks.MockUserRepository) {
+				userRepo.EXPECT().SavePreferences(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, preferences *sdump.Preferences) error {
+						require.Equal(t, testUser.ID, preferences.UserID)
+						require.Equal(t, "dracula", preferences.ColorScheme)
+						return nil
+					})
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+	}
+
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+
+			req := withUserAndParams(httptest.NewRequest(http.MethodPut, "/",
+				strings.NewReader(v.body)), nil)
+
+			logrus.SetOutput(io.Discard)
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			userRepo := mocks.NewMockUserRepository(ctrl)
+
+			v.mockFn(userRepo)
+
+			h := &userHandler{
+				logger:   logrus.WithField("module", "test"),
+				cfg:      config.Config{},
+				userRepo: userRepo,
+			}
+
+			h.savePreferences(recorder, req)
+
+			require.Equal(t, v.expectedStatusCode, recorder.Result().StatusCode)
+			verifyMatch(t, recorder)
+		})
+	}
+}
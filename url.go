@@ -18,7 +18,20 @@ const (
 	ErrURLEndpointNotFound = appError("endpoint not found")
 )
 
-type URLEndpointMetadata struct{}
+type URLEndpointMetadata struct {
+	// Protobuf is used to decode protobuf payloads sent to the endpoint
+	Protobuf *ProtobufSchema `json:"protobuf,omitempty"`
+}
+
+// ProtobufSchema is a FileDescriptorSet uploaded for an endpoint
+type ProtobufSchema struct {
+	DescriptorSet []byte `json:"descriptor_set,omitempty"`
+
+	// DefaultMessage is used for payloads that do not specify their message
+	// type
+	DefaultMessage string    `json:"default_message,omitempty"`
+	UploadedAt     time.Time `json:"uploaded_at,omitempty"`
+}
 
 type URLEndpoint struct {
 	ID        uuid.UUID `bun:"type:uuid,default:uuid_generate_v4()" json:"id,omitempty"`
@@ -54,4 +67,5 @@ type URLRepository interface {
 	Create(context.Context, *URLEndpoint) error
 	Get(context.Context, *FindURLOptions) (*URLEndpoint, error)
 	Latest(context.Context, uuid.UUID) (*URLEndpoint, error)
+	Update(context.Context, *URLEndpoint) error
 }
@@ -9,9 +9,10 @@ import (
 )
 
 const (
-	ErrPlanNotFound     = appError("plan does not exists")
-	ErrUserNotFound     = appError("user not found")
-	ErrCounterExhausted = appError("no more units left")
+	ErrPlanNotFound        = appError("plan does not exists")
+	ErrUserNotFound        = appError("user not found")
+	ErrCounterExhausted    = appError("no more units left")
+	ErrPreferencesNotFound = appError("preferences not found")
 )
 
 type Counter int64
@@ -50,6 +51,39 @@ type User struct {
 	bun.BaseModel `bun:"table:users"`
 }
 
+// TimestampFormats are the layouts timestamps can be shown in, by name
+var TimestampFormats = map[string]string{
+	"default": "02/01/2006 15:04:05",
+	"iso":     time.RFC3339,
+	"us":      "01/02/2006 03:04:05 PM",
+	"time":    "15:04:05.000",
+}
+
+// KeymapPresets are the keymaps the TUI can start with
+var KeymapPresets = []string{"default", "vim", "emacs"}
+
+// Preferences customize the TUI of a user. Empty fields fall back to the
+// config of the ssh server
+type Preferences struct {
+	UserID uuid.UUID `bun:"type:uuid,pk" json:"user_id,omitempty"`
+
+	// ColorScheme is the chroma style request bodies are highlighted with
+	ColorScheme string `json:"color_scheme,omitempty"`
+	// TimestampFormat is one of the keys of TimestampFormats
+	TimestampFormat string `json:"timestamp_format,omitempty"`
+	// Timezone is an IANA timezone such as Africa/Lagos
+	Timezone string `json:"timezone,omitempty"`
+	// BodyView forces the format bodies are shown in instead of the one
+	// detected from the Content-Type
+	BodyView     string `json:"body_view,omitempty"`
+	KeymapPreset string `json:"keymap_preset,omitempty"`
+
+	CreatedAt time.Time `bun:",nullzero,notnull,default:current_timestamp" json:"created_at,omitempty" bson:"created_at"`
+	UpdatedAt time.Time `bun:",nullzero,notnull,default:current_timestamp" json:"updated_at,omitempty" bson:"updated_at"`
+
+	bun.BaseModel `bun:"table:preferences"`
+}
+
 type FindUserOptions struct {
 	SSHKeyFingerprint string
 }
@@ -57,4 +91,9 @@ type FindUserOptions struct {
 type UserRepository interface {
 	Create(context.Context, *User) error
 	Find(context.Context, *FindUserOptions) (*User, error)
+	// FindPreferences returns ErrPreferencesNotFound if the user never saved
+	// their preferences
+	FindPreferences(context.Context, uuid.UUID) (*Preferences, error)
+	// SavePreferences creates or replaces the preferences of the user
+	SavePreferences(context.Context, *Preferences) error
 }